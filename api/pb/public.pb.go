@@ -31,6 +31,9 @@ type Response struct {
 	//	*Response_Category
 	//	*Response_Task
 	//	*Response_VerifyEmail
+	//	*Response_Totp
+	//	*Response_ApiToken
+	//	*Response_Tag
 	Data    isResponse_Data `protobuf_oneof:"data"`
 	Status  int32           `protobuf:"varint,5,opt,name=status,proto3" json:"status,omitempty"`
 	Message string          `protobuf:"bytes,6,opt,name=message,proto3" json:"message,omitempty"`
@@ -103,6 +106,27 @@ func (x *Response) GetVerifyEmail() *VerifyEmail {
 	return nil
 }
 
+func (x *Response) GetTotp() *TOTP {
+	if x, ok := x.GetData().(*Response_Totp); ok {
+		return x.Totp
+	}
+	return nil
+}
+
+func (x *Response) GetApiToken() *ApiToken {
+	if x, ok := x.GetData().(*Response_ApiToken); ok {
+		return x.ApiToken
+	}
+	return nil
+}
+
+func (x *Response) GetTag() *Tag {
+	if x, ok := x.GetData().(*Response_Tag); ok {
+		return x.Tag
+	}
+	return nil
+}
+
 func (x *Response) GetStatus() int32 {
 	if x != nil {
 		return x.Status
@@ -137,6 +161,18 @@ type Response_VerifyEmail struct {
 	VerifyEmail *VerifyEmail `protobuf:"bytes,4,opt,name=verifyEmail,proto3,oneof"`
 }
 
+type Response_Totp struct {
+	Totp *TOTP `protobuf:"bytes,7,opt,name=totp,proto3,oneof"`
+}
+
+type Response_ApiToken struct {
+	ApiToken *ApiToken `protobuf:"bytes,8,opt,name=api_token,json=apiToken,proto3,oneof"`
+}
+
+type Response_Tag struct {
+	Tag *Tag `protobuf:"bytes,9,opt,name=tag,proto3,oneof"`
+}
+
 func (*Response_User) isResponse_Data() {}
 
 func (*Response_Category) isResponse_Data() {}
@@ -145,6 +181,12 @@ func (*Response_Task) isResponse_Data() {}
 
 func (*Response_VerifyEmail) isResponse_Data() {}
 
+func (*Response_Totp) isResponse_Data() {}
+
+func (*Response_ApiToken) isResponse_Data() {}
+
+func (*Response_Tag) isResponse_Data() {}
+
 type ListResponse struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -154,6 +196,10 @@ type ListResponse struct {
 	//
 	//	*ListResponse_Categories
 	//	*ListResponse_Tasks
+	//	*ListResponse_ApiTokens
+	//	*ListResponse_Users
+	//	*ListResponse_Tags
+	//	*ListResponse_TaskSearchResults
 	Data       isListResponse_Data `protobuf_oneof:"data"`
 	TotalCount int32               `protobuf:"varint,3,opt,name=total_count,json=totalCount,proto3" json:"total_count,omitempty"`
 	Page       int32               `protobuf:"varint,4,opt,name=page,proto3" json:"page,omitempty"`
@@ -215,6 +261,34 @@ func (x *ListResponse) GetTasks() *Tasks {
 	return nil
 }
 
+func (x *ListResponse) GetApiTokens() *ApiTokens {
+	if x, ok := x.GetData().(*ListResponse_ApiTokens); ok {
+		return x.ApiTokens
+	}
+	return nil
+}
+
+func (x *ListResponse) GetUsers() *Users {
+	if x, ok := x.GetData().(*ListResponse_Users); ok {
+		return x.Users
+	}
+	return nil
+}
+
+func (x *ListResponse) GetTags() *Tags {
+	if x, ok := x.GetData().(*ListResponse_Tags); ok {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *ListResponse) GetTaskSearchResults() *TaskSearchResults {
+	if x, ok := x.GetData().(*ListResponse_TaskSearchResults); ok {
+		return x.TaskSearchResults
+	}
+	return nil
+}
+
 func (x *ListResponse) GetTotalCount() int32 {
 	if x != nil {
 		return x.TotalCount
@@ -262,10 +336,81 @@ type ListResponse_Tasks struct {
 	Tasks *Tasks `protobuf:"bytes,2,opt,name=tasks,proto3,oneof"`
 }
 
+type ListResponse_ApiTokens struct {
+	ApiTokens *ApiTokens `protobuf:"bytes,8,opt,name=api_tokens,json=apiTokens,proto3,oneof"`
+}
+
+type ListResponse_Users struct {
+	Users *Users `protobuf:"bytes,9,opt,name=users,proto3,oneof"`
+}
+
+type ListResponse_Tags struct {
+	Tags *Tags `protobuf:"bytes,10,opt,name=tags,proto3,oneof"`
+}
+
+type ListResponse_TaskSearchResults struct {
+	TaskSearchResults *TaskSearchResults `protobuf:"bytes,11,opt,name=task_search_results,json=taskSearchResults,proto3,oneof"`
+}
+
 func (*ListResponse_Categories) isListResponse_Data() {}
 
 func (*ListResponse_Tasks) isListResponse_Data() {}
 
+func (*ListResponse_ApiTokens) isListResponse_Data() {}
+
+func (*ListResponse_Users) isListResponse_Data() {}
+
+func (*ListResponse_Tags) isListResponse_Data() {}
+
+func (*ListResponse_TaskSearchResults) isListResponse_Data() {}
+
+type Users struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []*User `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *Users) Reset() {
+	*x = Users{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_public_proto_msgTypes[2]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Users) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Users) ProtoMessage() {}
+
+func (x *Users) ProtoReflect() protoreflect.Message {
+	mi := &file_public_proto_msgTypes[2]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Users.ProtoReflect.Descriptor instead.
+func (*Users) Descriptor() ([]byte, []int) {
+	return file_public_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Users) GetData() []*User {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type Categories struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -277,7 +422,7 @@ type Categories struct {
 func (x *Categories) Reset() {
 	*x = Categories{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_public_proto_msgTypes[2]
+		mi := &file_public_proto_msgTypes[3]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -290,7 +435,7 @@ func (x *Categories) String() string {
 func (*Categories) ProtoMessage() {}
 
 func (x *Categories) ProtoReflect() protoreflect.Message {
-	mi := &file_public_proto_msgTypes[2]
+	mi := &file_public_proto_msgTypes[3]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -303,7 +448,7 @@ func (x *Categories) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Categories.ProtoReflect.Descriptor instead.
 func (*Categories) Descriptor() ([]byte, []int) {
-	return file_public_proto_rawDescGZIP(), []int{2}
+	return file_public_proto_rawDescGZIP(), []int{3}
 }
 
 func (x *Categories) GetData() []*Category {
@@ -313,6 +458,53 @@ func (x *Categories) GetData() []*Category {
 	return nil
 }
 
+type Tags struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []*Tag `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *Tags) Reset() {
+	*x = Tags{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_public_proto_msgTypes[4]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Tags) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Tags) ProtoMessage() {}
+
+func (x *Tags) ProtoReflect() protoreflect.Message {
+	mi := &file_public_proto_msgTypes[4]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Tags.ProtoReflect.Descriptor instead.
+func (*Tags) Descriptor() ([]byte, []int) {
+	return file_public_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *Tags) GetData() []*Tag {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type Tasks struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -324,7 +516,7 @@ type Tasks struct {
 func (x *Tasks) Reset() {
 	*x = Tasks{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_public_proto_msgTypes[3]
+		mi := &file_public_proto_msgTypes[5]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -337,7 +529,7 @@ func (x *Tasks) String() string {
 func (*Tasks) ProtoMessage() {}
 
 func (x *Tasks) ProtoReflect() protoreflect.Message {
-	mi := &file_public_proto_msgTypes[3]
+	mi := &file_public_proto_msgTypes[5]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -350,7 +542,7 @@ func (x *Tasks) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use Tasks.ProtoReflect.Descriptor instead.
 func (*Tasks) Descriptor() ([]byte, []int) {
-	return file_public_proto_rawDescGZIP(), []int{3}
+	return file_public_proto_rawDescGZIP(), []int{5}
 }
 
 func (x *Tasks) GetData() []*Task {
@@ -360,6 +552,100 @@ func (x *Tasks) GetData() []*Task {
 	return nil
 }
 
+type TaskSearchResults struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []*TaskSearchResult `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *TaskSearchResults) Reset() {
+	*x = TaskSearchResults{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_public_proto_msgTypes[6]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *TaskSearchResults) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TaskSearchResults) ProtoMessage() {}
+
+func (x *TaskSearchResults) ProtoReflect() protoreflect.Message {
+	mi := &file_public_proto_msgTypes[6]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TaskSearchResults.ProtoReflect.Descriptor instead.
+func (*TaskSearchResults) Descriptor() ([]byte, []int) {
+	return file_public_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TaskSearchResults) GetData() []*TaskSearchResult {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type ApiTokens struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Data []*ApiToken `protobuf:"bytes,1,rep,name=data,proto3" json:"data,omitempty"`
+}
+
+func (x *ApiTokens) Reset() {
+	*x = ApiTokens{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_public_proto_msgTypes[7]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *ApiTokens) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ApiTokens) ProtoMessage() {}
+
+func (x *ApiTokens) ProtoReflect() protoreflect.Message {
+	mi := &file_public_proto_msgTypes[7]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ApiTokens.ProtoReflect.Descriptor instead.
+func (*ApiTokens) Descriptor() ([]byte, []int) {
+	return file_public_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ApiTokens) GetData() []*ApiToken {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
 type VerifyEmails struct {
 	state         protoimpl.MessageState
 	sizeCache     protoimpl.SizeCache
@@ -371,7 +657,7 @@ type VerifyEmails struct {
 func (x *VerifyEmails) Reset() {
 	*x = VerifyEmails{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_public_proto_msgTypes[4]
+		mi := &file_public_proto_msgTypes[8]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -384,7 +670,7 @@ func (x *VerifyEmails) String() string {
 func (*VerifyEmails) ProtoMessage() {}
 
 func (x *VerifyEmails) ProtoReflect() protoreflect.Message {
-	mi := &file_public_proto_msgTypes[4]
+	mi := &file_public_proto_msgTypes[8]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -397,7 +683,7 @@ func (x *VerifyEmails) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyEmails.ProtoReflect.Descriptor instead.
 func (*VerifyEmails) Descriptor() ([]byte, []int) {
-	return file_public_proto_rawDescGZIP(), []int{4}
+	return file_public_proto_rawDescGZIP(), []int{8}
 }
 
 func (x *VerifyEmails) GetData() []*VerifyEmail {
@@ -412,7 +698,7 @@ var File_public_proto protoreflect.FileDescriptor
 var file_public_proto_rawDesc = []byte{
 	0x0a, 0x0c, 0x70, 0x75, 0x62, 0x6c, 0x69, 0x63, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x02,
 	0x70, 0x62, 0x1a, 0x0b, 0x6d, 0x6f, 0x64, 0x65, 0x6c, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0xe5, 0x01, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x04,
+	0xcf, 0x02, 0x0a, 0x08, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x1e, 0x0a, 0x04,
 	0x75, 0x73, 0x65, 0x72, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e,
 	0x55, 0x73, 0x65, 0x72, 0x48, 0x00, 0x52, 0x04, 0x75, 0x73, 0x65, 0x72, 0x12, 0x2a, 0x0a, 0x08,
 	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0c,
@@ -422,31 +708,61 @@ var file_public_proto_rawDesc = []byte{
 	0x48, 0x00, 0x52, 0x04, 0x74, 0x61, 0x73, 0x6b, 0x12, 0x33, 0x0a, 0x0b, 0x76, 0x65, 0x72, 0x69,
 	0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x18, 0x04, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e,
 	0x70, 0x62, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x48, 0x00,
-	0x52, 0x0b, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x16, 0x0a,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73,
-	0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65,
-	0x18, 0x06, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42,
-	0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0xef, 0x01, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74,
-	0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x30, 0x0a, 0x0a, 0x63, 0x61, 0x74, 0x65,
-	0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70,
-	0x62, 0x2e, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0a,
-	0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x05, 0x74, 0x61,
-	0x73, 0x6b, 0x73, 0x18, 0x02, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x54,
-	0x61, 0x73, 0x6b, 0x73, 0x48, 0x00, 0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x12, 0x1f, 0x0a,
-	0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75, 0x6e, 0x74, 0x18, 0x03, 0x20, 0x01,
-	0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43, 0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12,
-	0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01, 0x28, 0x05, 0x52, 0x04, 0x70, 0x61,
-	0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65, 0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18,
-	0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67, 0x65, 0x53, 0x69, 0x7a, 0x65, 0x12,
-	0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18, 0x06, 0x20, 0x01, 0x28, 0x05, 0x52,
-	0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61,
-	0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67,
-	0x65, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x2e, 0x0a, 0x0a, 0x43, 0x61, 0x74,
-	0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
-	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x61, 0x74, 0x65, 0x67,
-	0x6f, 0x72, 0x79, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x25, 0x0a, 0x05, 0x54, 0x61, 0x73,
-	0x6b, 0x73, 0x12, 0x1c, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b,
-	0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x52, 0x0b, 0x76, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x12, 0x1e, 0x0a,
+	0x04, 0x74, 0x6f, 0x74, 0x70, 0x18, 0x07, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62,
+	0x2e, 0x54, 0x4f, 0x54, 0x50, 0x48, 0x00, 0x52, 0x04, 0x74, 0x6f, 0x74, 0x70, 0x12, 0x2b, 0x0a,
+	0x09, 0x61, 0x70, 0x69, 0x5f, 0x74, 0x6f, 0x6b, 0x65, 0x6e, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b,
+	0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x41, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x48, 0x00,
+	0x52, 0x08, 0x61, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x12, 0x1b, 0x0a, 0x03, 0x74, 0x61,
+	0x67, 0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x67,
+	0x48, 0x00, 0x52, 0x03, 0x74, 0x61, 0x67, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12,
+	0x18, 0x0a, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x06, 0x20, 0x01, 0x28, 0x09,
+	0x52, 0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0xab, 0x03, 0x0a, 0x0c, 0x4c, 0x69, 0x73, 0x74, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e,
+	0x73, 0x65, 0x12, 0x30, 0x0a, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x69, 0x65, 0x73,
+	0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0e, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x61, 0x74, 0x65,
+	0x67, 0x6f, 0x72, 0x69, 0x65, 0x73, 0x48, 0x00, 0x52, 0x0a, 0x63, 0x61, 0x74, 0x65, 0x67, 0x6f,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x21, 0x0a, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x73, 0x48, 0x00,
+	0x52, 0x05, 0x74, 0x61, 0x73, 0x6b, 0x73, 0x12, 0x2e, 0x0a, 0x0a, 0x61, 0x70, 0x69, 0x5f, 0x74,
+	0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x18, 0x08, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0d, 0x2e, 0x70, 0x62,
+	0x2e, 0x41, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x48, 0x00, 0x52, 0x09, 0x61, 0x70,
+	0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x21, 0x0a, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73,
+	0x18, 0x09, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x73, 0x48, 0x00, 0x52, 0x05, 0x75, 0x73, 0x65, 0x72, 0x73, 0x12, 0x1e, 0x0a, 0x04, 0x74, 0x61,
+	0x67, 0x73, 0x18, 0x0a, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61,
+	0x67, 0x73, 0x48, 0x00, 0x52, 0x04, 0x74, 0x61, 0x67, 0x73, 0x12, 0x47, 0x0a, 0x13, 0x74, 0x61,
+	0x73, 0x6b, 0x5f, 0x73, 0x65, 0x61, 0x72, 0x63, 0x68, 0x5f, 0x72, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x73, 0x18, 0x0b, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x73,
+	0x6b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x48, 0x00,
+	0x52, 0x11, 0x74, 0x61, 0x73, 0x6b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75,
+	0x6c, 0x74, 0x73, 0x12, 0x1f, 0x0a, 0x0b, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x5f, 0x63, 0x6f, 0x75,
+	0x6e, 0x74, 0x18, 0x03, 0x20, 0x01, 0x28, 0x05, 0x52, 0x0a, 0x74, 0x6f, 0x74, 0x61, 0x6c, 0x43,
+	0x6f, 0x75, 0x6e, 0x74, 0x12, 0x12, 0x0a, 0x04, 0x70, 0x61, 0x67, 0x65, 0x18, 0x04, 0x20, 0x01,
+	0x28, 0x05, 0x52, 0x04, 0x70, 0x61, 0x67, 0x65, 0x12, 0x1b, 0x0a, 0x09, 0x70, 0x61, 0x67, 0x65,
+	0x5f, 0x73, 0x69, 0x7a, 0x65, 0x18, 0x05, 0x20, 0x01, 0x28, 0x05, 0x52, 0x08, 0x70, 0x61, 0x67,
+	0x65, 0x53, 0x69, 0x7a, 0x65, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x18,
+	0x06, 0x20, 0x01, 0x28, 0x05, 0x52, 0x06, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x18, 0x0a,
+	0x07, 0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x18, 0x07, 0x20, 0x01, 0x28, 0x09, 0x52, 0x07,
+	0x6d, 0x65, 0x73, 0x73, 0x61, 0x67, 0x65, 0x42, 0x06, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22,
+	0x25, 0x0a, 0x05, 0x55, 0x73, 0x65, 0x72, 0x73, 0x12, 0x1c, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61,
+	0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x55, 0x73, 0x65, 0x72,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x2e, 0x0a, 0x0a, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f,
+	0x72, 0x69, 0x65, 0x73, 0x12, 0x20, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70, 0x62, 0x2e, 0x43, 0x61, 0x74, 0x65, 0x67, 0x6f, 0x72, 0x79,
+	0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x23, 0x0a, 0x04, 0x54, 0x61, 0x67, 0x73, 0x12, 0x1b,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x07, 0x2e, 0x70,
+	0x62, 0x2e, 0x54, 0x61, 0x67, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61, 0x22, 0x25, 0x0a, 0x05, 0x54,
+	0x61, 0x73, 0x6b, 0x73, 0x12, 0x1c, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03,
+	0x28, 0x0b, 0x32, 0x08, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x52, 0x04, 0x64, 0x61,
+	0x74, 0x61, 0x22, 0x3d, 0x0a, 0x11, 0x54, 0x61, 0x73, 0x6b, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68,
+	0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x73, 0x12, 0x28, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18,
+	0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x14, 0x2e, 0x70, 0x62, 0x2e, 0x54, 0x61, 0x73, 0x6b, 0x53,
+	0x65, 0x61, 0x72, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74, 0x52, 0x04, 0x64, 0x61, 0x74,
+	0x61, 0x22, 0x2d, 0x0a, 0x09, 0x41, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x73, 0x12, 0x20,
+	0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0c, 0x2e, 0x70,
+	0x62, 0x2e, 0x41, 0x70, 0x69, 0x54, 0x6f, 0x6b, 0x65, 0x6e, 0x52, 0x04, 0x64, 0x61, 0x74, 0x61,
 	0x22, 0x33, 0x0a, 0x0c, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x73,
 	0x12, 0x23, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x03, 0x28, 0x0b, 0x32, 0x0f,
 	0x2e, 0x70, 0x62, 0x2e, 0x56, 0x65, 0x72, 0x69, 0x66, 0x79, 0x45, 0x6d, 0x61, 0x69, 0x6c, 0x52,
@@ -467,33 +783,52 @@ func file_public_proto_rawDescGZIP() []byte {
 	return file_public_proto_rawDescData
 }
 
-var file_public_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_public_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
 var file_public_proto_goTypes = []interface{}{
-	(*Response)(nil),     // 0: pb.Response
-	(*ListResponse)(nil), // 1: pb.ListResponse
-	(*Categories)(nil),   // 2: pb.Categories
-	(*Tasks)(nil),        // 3: pb.Tasks
-	(*VerifyEmails)(nil), // 4: pb.VerifyEmails
-	(*User)(nil),         // 5: pb.User
-	(*Category)(nil),     // 6: pb.Category
-	(*Task)(nil),         // 7: pb.Task
-	(*VerifyEmail)(nil),  // 8: pb.VerifyEmail
+	(*Response)(nil),          // 0: pb.Response
+	(*ListResponse)(nil),      // 1: pb.ListResponse
+	(*Users)(nil),             // 2: pb.Users
+	(*Categories)(nil),        // 3: pb.Categories
+	(*Tags)(nil),              // 4: pb.Tags
+	(*Tasks)(nil),             // 5: pb.Tasks
+	(*TaskSearchResults)(nil), // 6: pb.TaskSearchResults
+	(*ApiTokens)(nil),         // 7: pb.ApiTokens
+	(*VerifyEmails)(nil),      // 8: pb.VerifyEmails
+	(*User)(nil),              // 9: pb.User
+	(*Category)(nil),          // 10: pb.Category
+	(*Task)(nil),              // 11: pb.Task
+	(*VerifyEmail)(nil),       // 12: pb.VerifyEmail
+	(*TOTP)(nil),              // 13: pb.TOTP
+	(*ApiToken)(nil),          // 14: pb.ApiToken
+	(*Tag)(nil),               // 15: pb.Tag
+	(*TaskSearchResult)(nil),  // 16: pb.TaskSearchResult
 }
 var file_public_proto_depIdxs = []int32{
-	5, // 0: pb.Response.user:type_name -> pb.User
-	6, // 1: pb.Response.category:type_name -> pb.Category
-	7, // 2: pb.Response.task:type_name -> pb.Task
-	8, // 3: pb.Response.verifyEmail:type_name -> pb.VerifyEmail
-	2, // 4: pb.ListResponse.categories:type_name -> pb.Categories
-	3, // 5: pb.ListResponse.tasks:type_name -> pb.Tasks
-	6, // 6: pb.Categories.data:type_name -> pb.Category
-	7, // 7: pb.Tasks.data:type_name -> pb.Task
-	8, // 8: pb.VerifyEmails.data:type_name -> pb.VerifyEmail
-	9, // [9:9] is the sub-list for method output_type
-	9, // [9:9] is the sub-list for method input_type
-	9, // [9:9] is the sub-list for extension type_name
-	9, // [9:9] is the sub-list for extension extendee
-	0, // [0:9] is the sub-list for field type_name
+	9,  // 0: pb.Response.user:type_name -> pb.User
+	10, // 1: pb.Response.category:type_name -> pb.Category
+	11, // 2: pb.Response.task:type_name -> pb.Task
+	12, // 3: pb.Response.verifyEmail:type_name -> pb.VerifyEmail
+	13, // 4: pb.Response.totp:type_name -> pb.TOTP
+	14, // 5: pb.Response.api_token:type_name -> pb.ApiToken
+	15, // 6: pb.Response.tag:type_name -> pb.Tag
+	3,  // 7: pb.ListResponse.categories:type_name -> pb.Categories
+	5,  // 8: pb.ListResponse.tasks:type_name -> pb.Tasks
+	7,  // 9: pb.ListResponse.api_tokens:type_name -> pb.ApiTokens
+	2,  // 10: pb.ListResponse.users:type_name -> pb.Users
+	4,  // 11: pb.ListResponse.tags:type_name -> pb.Tags
+	6,  // 12: pb.ListResponse.task_search_results:type_name -> pb.TaskSearchResults
+	9,  // 13: pb.Users.data:type_name -> pb.User
+	10, // 14: pb.Categories.data:type_name -> pb.Category
+	15, // 15: pb.Tags.data:type_name -> pb.Tag
+	11, // 16: pb.Tasks.data:type_name -> pb.Task
+	16, // 17: pb.TaskSearchResults.data:type_name -> pb.TaskSearchResult
+	14, // 18: pb.ApiTokens.data:type_name -> pb.ApiToken
+	12, // 19: pb.VerifyEmails.data:type_name -> pb.VerifyEmail
+	20, // [20:20] is the sub-list for method output_type
+	20, // [20:20] is the sub-list for method input_type
+	20, // [20:20] is the sub-list for extension type_name
+	20, // [20:20] is the sub-list for extension extendee
+	0,  // [0:20] is the sub-list for field type_name
 }
 
 func init() { file_public_proto_init() }
@@ -528,7 +863,7 @@ func file_public_proto_init() {
 			}
 		}
 		file_public_proto_msgTypes[2].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Categories); i {
+			switch v := v.(*Users); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -540,7 +875,7 @@ func file_public_proto_init() {
 			}
 		}
 		file_public_proto_msgTypes[3].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*Tasks); i {
+			switch v := v.(*Categories); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -552,6 +887,54 @@ func file_public_proto_init() {
 			}
 		}
 		file_public_proto_msgTypes[4].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Tags); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_public_proto_msgTypes[5].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Tasks); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_public_proto_msgTypes[6].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*TaskSearchResults); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_public_proto_msgTypes[7].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*ApiTokens); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_public_proto_msgTypes[8].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*VerifyEmails); i {
 			case 0:
 				return &v.state
@@ -569,10 +952,17 @@ func file_public_proto_init() {
 		(*Response_Category)(nil),
 		(*Response_Task)(nil),
 		(*Response_VerifyEmail)(nil),
+		(*Response_Totp)(nil),
+		(*Response_ApiToken)(nil),
+		(*Response_Tag)(nil),
 	}
 	file_public_proto_msgTypes[1].OneofWrappers = []interface{}{
 		(*ListResponse_Categories)(nil),
 		(*ListResponse_Tasks)(nil),
+		(*ListResponse_ApiTokens)(nil),
+		(*ListResponse_Users)(nil),
+		(*ListResponse_Tags)(nil),
+		(*ListResponse_TaskSearchResults)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
@@ -580,7 +970,7 @@ func file_public_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_public_proto_rawDesc,
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   9,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -19,20 +19,44 @@ import (
 const _ = grpc.SupportPackageIsVersion8
 
 const (
-	ToDoList_Login_FullMethodName          = "/pb.ToDoList/Login"
-	ToDoList_RegisterUser_FullMethodName   = "/pb.ToDoList/RegisterUser"
-	ToDoList_UpdateUser_FullMethodName     = "/pb.ToDoList/UpdateUser"
-	ToDoList_CreateCategory_FullMethodName = "/pb.ToDoList/CreateCategory"
-	ToDoList_GetCategory_FullMethodName    = "/pb.ToDoList/GetCategory"
-	ToDoList_ListCategory_FullMethodName   = "/pb.ToDoList/ListCategory"
-	ToDoList_UpdateCategory_FullMethodName = "/pb.ToDoList/UpdateCategory"
-	ToDoList_DeleteCategory_FullMethodName = "/pb.ToDoList/DeleteCategory"
-	ToDoList_CreateTask_FullMethodName     = "/pb.ToDoList/CreateTask"
-	ToDoList_GetTask_FullMethodName        = "/pb.ToDoList/GetTask"
-	ToDoList_ListTask_FullMethodName       = "/pb.ToDoList/ListTask"
-	ToDoList_UpdateTask_FullMethodName     = "/pb.ToDoList/UpdateTask"
-	ToDoList_DeleteTask_FullMethodName     = "/pb.ToDoList/DeleteTask"
-	ToDoList_VerifyEmail_FullMethodName    = "/pb.ToDoList/VerifyEmail"
+	ToDoList_Login_FullMethodName                   = "/pb.ToDoList/Login"
+	ToDoList_RegisterUser_FullMethodName            = "/pb.ToDoList/RegisterUser"
+	ToDoList_UpdateUser_FullMethodName              = "/pb.ToDoList/UpdateUser"
+	ToDoList_RefreshToken_FullMethodName            = "/pb.ToDoList/RefreshToken"
+	ToDoList_Logout_FullMethodName                  = "/pb.ToDoList/Logout"
+	ToDoList_LoginTOTP_FullMethodName               = "/pb.ToDoList/LoginTOTP"
+	ToDoList_EnrollTOTP_FullMethodName              = "/pb.ToDoList/EnrollTOTP"
+	ToDoList_ConfirmTOTP_FullMethodName             = "/pb.ToDoList/ConfirmTOTP"
+	ToDoList_DisableTOTP_FullMethodName             = "/pb.ToDoList/DisableTOTP"
+	ToDoList_DeactivateAccount_FullMethodName       = "/pb.ToDoList/DeactivateAccount"
+	ToDoList_ReactivateAccount_FullMethodName       = "/pb.ToDoList/ReactivateAccount"
+	ToDoList_CreateApiToken_FullMethodName          = "/pb.ToDoList/CreateApiToken"
+	ToDoList_ListApiTokens_FullMethodName           = "/pb.ToDoList/ListApiTokens"
+	ToDoList_RevokeApiToken_FullMethodName          = "/pb.ToDoList/RevokeApiToken"
+	ToDoList_ListUsers_FullMethodName               = "/pb.ToDoList/ListUsers"
+	ToDoList_DisableUser_FullMethodName             = "/pb.ToDoList/DisableUser"
+	ToDoList_EnableUser_FullMethodName              = "/pb.ToDoList/EnableUser"
+	ToDoList_VerifyUserEmail_FullMethodName         = "/pb.ToDoList/VerifyUserEmail"
+	ToDoList_CreateCategory_FullMethodName          = "/pb.ToDoList/CreateCategory"
+	ToDoList_GetCategory_FullMethodName             = "/pb.ToDoList/GetCategory"
+	ToDoList_ListCategory_FullMethodName            = "/pb.ToDoList/ListCategory"
+	ToDoList_UpdateCategory_FullMethodName          = "/pb.ToDoList/UpdateCategory"
+	ToDoList_DeleteCategory_FullMethodName          = "/pb.ToDoList/DeleteCategory"
+	ToDoList_CreateTask_FullMethodName              = "/pb.ToDoList/CreateTask"
+	ToDoList_GetTask_FullMethodName                 = "/pb.ToDoList/GetTask"
+	ToDoList_ListTask_FullMethodName                = "/pb.ToDoList/ListTask"
+	ToDoList_SearchTasks_FullMethodName             = "/pb.ToDoList/SearchTasks"
+	ToDoList_UpdateTask_FullMethodName              = "/pb.ToDoList/UpdateTask"
+	ToDoList_DeleteTask_FullMethodName              = "/pb.ToDoList/DeleteTask"
+	ToDoList_CreateTag_FullMethodName               = "/pb.ToDoList/CreateTag"
+	ToDoList_GetTag_FullMethodName                  = "/pb.ToDoList/GetTag"
+	ToDoList_ListTag_FullMethodName                 = "/pb.ToDoList/ListTag"
+	ToDoList_UpdateTag_FullMethodName               = "/pb.ToDoList/UpdateTag"
+	ToDoList_DeleteTag_FullMethodName               = "/pb.ToDoList/DeleteTag"
+	ToDoList_VerifyEmail_FullMethodName             = "/pb.ToDoList/VerifyEmail"
+	ToDoList_ResendVerificationEmail_FullMethodName = "/pb.ToDoList/ResendVerificationEmail"
+	ToDoList_RequestPasswordReset_FullMethodName    = "/pb.ToDoList/RequestPasswordReset"
+	ToDoList_ResetPassword_FullMethodName           = "/pb.ToDoList/ResetPassword"
 )
 
 // ToDoListClient is the client API for ToDoList service.
@@ -43,6 +67,23 @@ type ToDoListClient interface {
 	Login(ctx context.Context, in *LoginRequest, opts ...grpc.CallOption) (*Response, error)
 	RegisterUser(ctx context.Context, in *RegisterUserRequest, opts ...grpc.CallOption) (*Response, error)
 	UpdateUser(ctx context.Context, in *UpdateUserRequest, opts ...grpc.CallOption) (*Response, error)
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*Response, error)
+	Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Response, error)
+	LoginTOTP(ctx context.Context, in *LoginTOTPRequest, opts ...grpc.CallOption) (*Response, error)
+	EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*Response, error)
+	ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...grpc.CallOption) (*Response, error)
+	DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*Response, error)
+	DeactivateAccount(ctx context.Context, in *DeactivateAccountRequest, opts ...grpc.CallOption) (*Response, error)
+	ReactivateAccount(ctx context.Context, in *ReactivateAccountRequest, opts ...grpc.CallOption) (*Response, error)
+	// API token
+	CreateApiToken(ctx context.Context, in *CreateApiTokenRequest, opts ...grpc.CallOption) (*Response, error)
+	ListApiTokens(ctx context.Context, in *ListApiTokensRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	RevokeApiToken(ctx context.Context, in *RevokeApiTokenRequest, opts ...grpc.CallOption) (*Response, error)
+	// Admin
+	ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	DisableUser(ctx context.Context, in *DisableUserRequest, opts ...grpc.CallOption) (*Response, error)
+	EnableUser(ctx context.Context, in *EnableUserRequest, opts ...grpc.CallOption) (*Response, error)
+	VerifyUserEmail(ctx context.Context, in *VerifyUserEmailRequest, opts ...grpc.CallOption) (*Response, error)
 	// Category
 	CreateCategory(ctx context.Context, in *CreateCategoryRequest, opts ...grpc.CallOption) (*Response, error)
 	GetCategory(ctx context.Context, in *GetCategoryRequest, opts ...grpc.CallOption) (*Response, error)
@@ -53,10 +94,21 @@ type ToDoListClient interface {
 	CreateTask(ctx context.Context, in *CreateTaskRequest, opts ...grpc.CallOption) (*Response, error)
 	GetTask(ctx context.Context, in *GetTaskRequest, opts ...grpc.CallOption) (*Response, error)
 	ListTask(ctx context.Context, in *ListTaskRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	SearchTasks(ctx context.Context, in *SearchTasksRequest, opts ...grpc.CallOption) (*ListResponse, error)
 	UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*Response, error)
 	DeleteTask(ctx context.Context, in *DeleteTaskRequest, opts ...grpc.CallOption) (*Response, error)
+	// Tag
+	CreateTag(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Response, error)
+	GetTag(ctx context.Context, in *GetTagRequest, opts ...grpc.CallOption) (*Response, error)
+	ListTag(ctx context.Context, in *ListTagRequest, opts ...grpc.CallOption) (*ListResponse, error)
+	UpdateTag(ctx context.Context, in *UpdateTagRequest, opts ...grpc.CallOption) (*Response, error)
+	DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*Response, error)
 	// Verify email
 	VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*Response, error)
+	ResendVerificationEmail(ctx context.Context, in *ResendVerificationEmailRequest, opts ...grpc.CallOption) (*Response, error)
+	// Password reset
+	RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*Response, error)
+	ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*Response, error)
 }
 
 type toDoListClient struct {
@@ -97,6 +149,156 @@ func (c *toDoListClient) UpdateUser(ctx context.Context, in *UpdateUserRequest,
 	return out, nil
 }
 
+func (c *toDoListClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) Logout(ctx context.Context, in *LogoutRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_Logout_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) LoginTOTP(ctx context.Context, in *LoginTOTPRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_LoginTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) EnrollTOTP(ctx context.Context, in *EnrollTOTPRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_EnrollTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ConfirmTOTP(ctx context.Context, in *ConfirmTOTPRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_ConfirmTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) DisableTOTP(ctx context.Context, in *DisableTOTPRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_DisableTOTP_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) DeactivateAccount(ctx context.Context, in *DeactivateAccountRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_DeactivateAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ReactivateAccount(ctx context.Context, in *ReactivateAccountRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_ReactivateAccount_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) CreateApiToken(ctx context.Context, in *CreateApiTokenRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_CreateApiToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ListApiTokens(ctx context.Context, in *ListApiTokensRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, ToDoList_ListApiTokens_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) RevokeApiToken(ctx context.Context, in *RevokeApiTokenRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_RevokeApiToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ListUsers(ctx context.Context, in *ListUsersRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, ToDoList_ListUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) DisableUser(ctx context.Context, in *DisableUserRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_DisableUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) EnableUser(ctx context.Context, in *EnableUserRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_EnableUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) VerifyUserEmail(ctx context.Context, in *VerifyUserEmailRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_VerifyUserEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *toDoListClient) CreateCategory(ctx context.Context, in *CreateCategoryRequest, opts ...grpc.CallOption) (*Response, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Response)
@@ -177,6 +379,16 @@ func (c *toDoListClient) ListTask(ctx context.Context, in *ListTaskRequest, opts
 	return out, nil
 }
 
+func (c *toDoListClient) SearchTasks(ctx context.Context, in *SearchTasksRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, ToDoList_SearchTasks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *toDoListClient) UpdateTask(ctx context.Context, in *UpdateTaskRequest, opts ...grpc.CallOption) (*Response, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Response)
@@ -197,6 +409,56 @@ func (c *toDoListClient) DeleteTask(ctx context.Context, in *DeleteTaskRequest,
 	return out, nil
 }
 
+func (c *toDoListClient) CreateTag(ctx context.Context, in *CreateTagRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_CreateTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) GetTag(ctx context.Context, in *GetTagRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_GetTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ListTag(ctx context.Context, in *ListTagRequest, opts ...grpc.CallOption) (*ListResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListResponse)
+	err := c.cc.Invoke(ctx, ToDoList_ListTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) UpdateTag(ctx context.Context, in *UpdateTagRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_UpdateTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) DeleteTag(ctx context.Context, in *DeleteTagRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_DeleteTag_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *toDoListClient) VerifyEmail(ctx context.Context, in *VerifyEmailRequest, opts ...grpc.CallOption) (*Response, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(Response)
@@ -207,6 +469,36 @@ func (c *toDoListClient) VerifyEmail(ctx context.Context, in *VerifyEmailRequest
 	return out, nil
 }
 
+func (c *toDoListClient) ResendVerificationEmail(ctx context.Context, in *ResendVerificationEmailRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_ResendVerificationEmail_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) RequestPasswordReset(ctx context.Context, in *RequestPasswordResetRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_RequestPasswordReset_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *toDoListClient) ResetPassword(ctx context.Context, in *ResetPasswordRequest, opts ...grpc.CallOption) (*Response, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Response)
+	err := c.cc.Invoke(ctx, ToDoList_ResetPassword_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // ToDoListServer is the server API for ToDoList service.
 // All implementations must embed UnimplementedToDoListServer
 // for forward compatibility
@@ -215,6 +507,23 @@ type ToDoListServer interface {
 	Login(context.Context, *LoginRequest) (*Response, error)
 	RegisterUser(context.Context, *RegisterUserRequest) (*Response, error)
 	UpdateUser(context.Context, *UpdateUserRequest) (*Response, error)
+	RefreshToken(context.Context, *RefreshTokenRequest) (*Response, error)
+	Logout(context.Context, *LogoutRequest) (*Response, error)
+	LoginTOTP(context.Context, *LoginTOTPRequest) (*Response, error)
+	EnrollTOTP(context.Context, *EnrollTOTPRequest) (*Response, error)
+	ConfirmTOTP(context.Context, *ConfirmTOTPRequest) (*Response, error)
+	DisableTOTP(context.Context, *DisableTOTPRequest) (*Response, error)
+	DeactivateAccount(context.Context, *DeactivateAccountRequest) (*Response, error)
+	ReactivateAccount(context.Context, *ReactivateAccountRequest) (*Response, error)
+	// API token
+	CreateApiToken(context.Context, *CreateApiTokenRequest) (*Response, error)
+	ListApiTokens(context.Context, *ListApiTokensRequest) (*ListResponse, error)
+	RevokeApiToken(context.Context, *RevokeApiTokenRequest) (*Response, error)
+	// Admin
+	ListUsers(context.Context, *ListUsersRequest) (*ListResponse, error)
+	DisableUser(context.Context, *DisableUserRequest) (*Response, error)
+	EnableUser(context.Context, *EnableUserRequest) (*Response, error)
+	VerifyUserEmail(context.Context, *VerifyUserEmailRequest) (*Response, error)
 	// Category
 	CreateCategory(context.Context, *CreateCategoryRequest) (*Response, error)
 	GetCategory(context.Context, *GetCategoryRequest) (*Response, error)
@@ -225,10 +534,21 @@ type ToDoListServer interface {
 	CreateTask(context.Context, *CreateTaskRequest) (*Response, error)
 	GetTask(context.Context, *GetTaskRequest) (*Response, error)
 	ListTask(context.Context, *ListTaskRequest) (*ListResponse, error)
+	SearchTasks(context.Context, *SearchTasksRequest) (*ListResponse, error)
 	UpdateTask(context.Context, *UpdateTaskRequest) (*Response, error)
 	DeleteTask(context.Context, *DeleteTaskRequest) (*Response, error)
+	// Tag
+	CreateTag(context.Context, *CreateTagRequest) (*Response, error)
+	GetTag(context.Context, *GetTagRequest) (*Response, error)
+	ListTag(context.Context, *ListTagRequest) (*ListResponse, error)
+	UpdateTag(context.Context, *UpdateTagRequest) (*Response, error)
+	DeleteTag(context.Context, *DeleteTagRequest) (*Response, error)
 	// Verify email
 	VerifyEmail(context.Context, *VerifyEmailRequest) (*Response, error)
+	ResendVerificationEmail(context.Context, *ResendVerificationEmailRequest) (*Response, error)
+	// Password reset
+	RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*Response, error)
+	ResetPassword(context.Context, *ResetPasswordRequest) (*Response, error)
 	mustEmbedUnimplementedToDoListServer()
 }
 
@@ -245,6 +565,51 @@ func (UnimplementedToDoListServer) RegisterUser(context.Context, *RegisterUserRe
 func (UnimplementedToDoListServer) UpdateUser(context.Context, *UpdateUserRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateUser not implemented")
 }
+func (UnimplementedToDoListServer) RefreshToken(context.Context, *RefreshTokenRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedToDoListServer) Logout(context.Context, *LogoutRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Logout not implemented")
+}
+func (UnimplementedToDoListServer) LoginTOTP(context.Context, *LoginTOTPRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method LoginTOTP not implemented")
+}
+func (UnimplementedToDoListServer) EnrollTOTP(context.Context, *EnrollTOTPRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnrollTOTP not implemented")
+}
+func (UnimplementedToDoListServer) ConfirmTOTP(context.Context, *ConfirmTOTPRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ConfirmTOTP not implemented")
+}
+func (UnimplementedToDoListServer) DisableTOTP(context.Context, *DisableTOTPRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableTOTP not implemented")
+}
+func (UnimplementedToDoListServer) DeactivateAccount(context.Context, *DeactivateAccountRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeactivateAccount not implemented")
+}
+func (UnimplementedToDoListServer) ReactivateAccount(context.Context, *ReactivateAccountRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReactivateAccount not implemented")
+}
+func (UnimplementedToDoListServer) CreateApiToken(context.Context, *CreateApiTokenRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateApiToken not implemented")
+}
+func (UnimplementedToDoListServer) ListApiTokens(context.Context, *ListApiTokensRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListApiTokens not implemented")
+}
+func (UnimplementedToDoListServer) RevokeApiToken(context.Context, *RevokeApiTokenRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevokeApiToken not implemented")
+}
+func (UnimplementedToDoListServer) ListUsers(context.Context, *ListUsersRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListUsers not implemented")
+}
+func (UnimplementedToDoListServer) DisableUser(context.Context, *DisableUserRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DisableUser not implemented")
+}
+func (UnimplementedToDoListServer) EnableUser(context.Context, *EnableUserRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EnableUser not implemented")
+}
+func (UnimplementedToDoListServer) VerifyUserEmail(context.Context, *VerifyUserEmailRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method VerifyUserEmail not implemented")
+}
 func (UnimplementedToDoListServer) CreateCategory(context.Context, *CreateCategoryRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method CreateCategory not implemented")
 }
@@ -269,15 +634,42 @@ func (UnimplementedToDoListServer) GetTask(context.Context, *GetTaskRequest) (*R
 func (UnimplementedToDoListServer) ListTask(context.Context, *ListTaskRequest) (*ListResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListTask not implemented")
 }
+func (UnimplementedToDoListServer) SearchTasks(context.Context, *SearchTasksRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchTasks not implemented")
+}
 func (UnimplementedToDoListServer) UpdateTask(context.Context, *UpdateTaskRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method UpdateTask not implemented")
 }
 func (UnimplementedToDoListServer) DeleteTask(context.Context, *DeleteTaskRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteTask not implemented")
 }
+func (UnimplementedToDoListServer) CreateTag(context.Context, *CreateTagRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateTag not implemented")
+}
+func (UnimplementedToDoListServer) GetTag(context.Context, *GetTagRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetTag not implemented")
+}
+func (UnimplementedToDoListServer) ListTag(context.Context, *ListTagRequest) (*ListResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListTag not implemented")
+}
+func (UnimplementedToDoListServer) UpdateTag(context.Context, *UpdateTagRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateTag not implemented")
+}
+func (UnimplementedToDoListServer) DeleteTag(context.Context, *DeleteTagRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteTag not implemented")
+}
 func (UnimplementedToDoListServer) VerifyEmail(context.Context, *VerifyEmailRequest) (*Response, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method VerifyEmail not implemented")
 }
+func (UnimplementedToDoListServer) ResendVerificationEmail(context.Context, *ResendVerificationEmailRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResendVerificationEmail not implemented")
+}
+func (UnimplementedToDoListServer) RequestPasswordReset(context.Context, *RequestPasswordResetRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RequestPasswordReset not implemented")
+}
+func (UnimplementedToDoListServer) ResetPassword(context.Context, *ResetPasswordRequest) (*Response, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResetPassword not implemented")
+}
 func (UnimplementedToDoListServer) mustEmbedUnimplementedToDoListServer() {}
 
 // UnsafeToDoListServer may be embedded to opt out of forward compatibility for this service.
@@ -345,146 +737,434 @@ func _ToDoList_UpdateUser_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_CreateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateCategoryRequest)
+func _ToDoList_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).CreateCategory(ctx, in)
+		return srv.(ToDoListServer).RefreshToken(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_CreateCategory_FullMethodName,
+		FullMethod: ToDoList_RefreshToken_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).CreateCategory(ctx, req.(*CreateCategoryRequest))
+		return srv.(ToDoListServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_GetCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetCategoryRequest)
+func _ToDoList_Logout_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LogoutRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).GetCategory(ctx, in)
+		return srv.(ToDoListServer).Logout(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_GetCategory_FullMethodName,
+		FullMethod: ToDoList_Logout_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).GetCategory(ctx, req.(*GetCategoryRequest))
+		return srv.(ToDoListServer).Logout(ctx, req.(*LogoutRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_ListCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListCategoryRequest)
+func _ToDoList_LoginTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LoginTOTPRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).ListCategory(ctx, in)
+		return srv.(ToDoListServer).LoginTOTP(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_ListCategory_FullMethodName,
+		FullMethod: ToDoList_LoginTOTP_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).ListCategory(ctx, req.(*ListCategoryRequest))
+		return srv.(ToDoListServer).LoginTOTP(ctx, req.(*LoginTOTPRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_UpdateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(UpdateCategoryRequest)
+func _ToDoList_EnrollTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnrollTOTPRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).UpdateCategory(ctx, in)
+		return srv.(ToDoListServer).EnrollTOTP(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_UpdateCategory_FullMethodName,
+		FullMethod: ToDoList_EnrollTOTP_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).UpdateCategory(ctx, req.(*UpdateCategoryRequest))
+		return srv.(ToDoListServer).EnrollTOTP(ctx, req.(*EnrollTOTPRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_DeleteCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(DeleteCategoryRequest)
+func _ToDoList_ConfirmTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConfirmTOTPRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).DeleteCategory(ctx, in)
+		return srv.(ToDoListServer).ConfirmTOTP(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_DeleteCategory_FullMethodName,
+		FullMethod: ToDoList_ConfirmTOTP_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).DeleteCategory(ctx, req.(*DeleteCategoryRequest))
+		return srv.(ToDoListServer).ConfirmTOTP(ctx, req.(*ConfirmTOTPRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(CreateTaskRequest)
+func _ToDoList_DisableTOTP_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableTOTPRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).CreateTask(ctx, in)
+		return srv.(ToDoListServer).DisableTOTP(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_CreateTask_FullMethodName,
+		FullMethod: ToDoList_DisableTOTP_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).CreateTask(ctx, req.(*CreateTaskRequest))
+		return srv.(ToDoListServer).DisableTOTP(ctx, req.(*DisableTOTPRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(GetTaskRequest)
+func _ToDoList_DeactivateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeactivateAccountRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).GetTask(ctx, in)
+		return srv.(ToDoListServer).DeactivateAccount(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_GetTask_FullMethodName,
+		FullMethod: ToDoList_DeactivateAccount_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).GetTask(ctx, req.(*GetTaskRequest))
+		return srv.(ToDoListServer).DeactivateAccount(ctx, req.(*DeactivateAccountRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
 
-func _ToDoList_ListTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
-	in := new(ListTaskRequest)
+func _ToDoList_ReactivateAccount_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReactivateAccountRequest)
 	if err := dec(in); err != nil {
 		return nil, err
 	}
 	if interceptor == nil {
-		return srv.(ToDoListServer).ListTask(ctx, in)
+		return srv.(ToDoListServer).ReactivateAccount(ctx, in)
 	}
 	info := &grpc.UnaryServerInfo{
 		Server:     srv,
-		FullMethod: ToDoList_ListTask_FullMethodName,
+		FullMethod: ToDoList_ReactivateAccount_FullMethodName,
 	}
 	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
-		return srv.(ToDoListServer).ListTask(ctx, req.(*ListTaskRequest))
+		return srv.(ToDoListServer).ReactivateAccount(ctx, req.(*ReactivateAccountRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_CreateApiToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateApiTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).CreateApiToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_CreateApiToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).CreateApiToken(ctx, req.(*CreateApiTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ListApiTokens_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListApiTokensRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ListApiTokens(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ListApiTokens_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ListApiTokens(ctx, req.(*ListApiTokensRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_RevokeApiToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeApiTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).RevokeApiToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_RevokeApiToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).RevokeApiToken(ctx, req.(*RevokeApiTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ListUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ListUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ListUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ListUsers(ctx, req.(*ListUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_DisableUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DisableUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).DisableUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_DisableUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).DisableUser(ctx, req.(*DisableUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_EnableUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnableUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).EnableUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_EnableUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).EnableUser(ctx, req.(*EnableUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_VerifyUserEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VerifyUserEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).VerifyUserEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_VerifyUserEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).VerifyUserEmail(ctx, req.(*VerifyUserEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_CreateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).CreateCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_CreateCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).CreateCategory(ctx, req.(*CreateCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_GetCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).GetCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_GetCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).GetCategory(ctx, req.(*GetCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ListCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ListCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ListCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ListCategory(ctx, req.(*ListCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_UpdateCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).UpdateCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_UpdateCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).UpdateCategory(ctx, req.(*UpdateCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_DeleteCategory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCategoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).DeleteCategory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_DeleteCategory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).DeleteCategory(ctx, req.(*DeleteCategoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_CreateTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).CreateTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_CreateTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).CreateTask(ctx, req.(*CreateTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_GetTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).GetTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_GetTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).GetTask(ctx, req.(*GetTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ListTask_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTaskRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ListTask(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ListTask_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ListTask(ctx, req.(*ListTaskRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_SearchTasks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTasksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).SearchTasks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_SearchTasks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).SearchTasks(ctx, req.(*SearchTasksRequest))
 	}
 	return interceptor(ctx, in, info, handler)
 }
@@ -525,6 +1205,96 @@ func _ToDoList_DeleteTask_Handler(srv interface{}, ctx context.Context, dec func
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ToDoList_CreateTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).CreateTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_CreateTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).CreateTag(ctx, req.(*CreateTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_GetTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).GetTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_GetTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).GetTag(ctx, req.(*GetTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ListTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ListTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ListTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ListTag(ctx, req.(*ListTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_UpdateTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).UpdateTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_UpdateTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).UpdateTag(ctx, req.(*UpdateTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_DeleteTag_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteTagRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).DeleteTag(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_DeleteTag_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).DeleteTag(ctx, req.(*DeleteTagRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _ToDoList_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(VerifyEmailRequest)
 	if err := dec(in); err != nil {
@@ -543,6 +1313,60 @@ func _ToDoList_VerifyEmail_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _ToDoList_ResendVerificationEmail_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResendVerificationEmailRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ResendVerificationEmail(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ResendVerificationEmail_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ResendVerificationEmail(ctx, req.(*ResendVerificationEmailRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_RequestPasswordReset_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RequestPasswordResetRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).RequestPasswordReset(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_RequestPasswordReset_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).RequestPasswordReset(ctx, req.(*RequestPasswordResetRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ToDoList_ResetPassword_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetPasswordRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ToDoListServer).ResetPassword(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ToDoList_ResetPassword_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ToDoListServer).ResetPassword(ctx, req.(*ResetPasswordRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // ToDoList_ServiceDesc is the grpc.ServiceDesc for ToDoList service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -562,6 +1386,66 @@ var ToDoList_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UpdateUser",
 			Handler:    _ToDoList_UpdateUser_Handler,
 		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _ToDoList_RefreshToken_Handler,
+		},
+		{
+			MethodName: "Logout",
+			Handler:    _ToDoList_Logout_Handler,
+		},
+		{
+			MethodName: "LoginTOTP",
+			Handler:    _ToDoList_LoginTOTP_Handler,
+		},
+		{
+			MethodName: "EnrollTOTP",
+			Handler:    _ToDoList_EnrollTOTP_Handler,
+		},
+		{
+			MethodName: "ConfirmTOTP",
+			Handler:    _ToDoList_ConfirmTOTP_Handler,
+		},
+		{
+			MethodName: "DisableTOTP",
+			Handler:    _ToDoList_DisableTOTP_Handler,
+		},
+		{
+			MethodName: "DeactivateAccount",
+			Handler:    _ToDoList_DeactivateAccount_Handler,
+		},
+		{
+			MethodName: "ReactivateAccount",
+			Handler:    _ToDoList_ReactivateAccount_Handler,
+		},
+		{
+			MethodName: "CreateApiToken",
+			Handler:    _ToDoList_CreateApiToken_Handler,
+		},
+		{
+			MethodName: "ListApiTokens",
+			Handler:    _ToDoList_ListApiTokens_Handler,
+		},
+		{
+			MethodName: "RevokeApiToken",
+			Handler:    _ToDoList_RevokeApiToken_Handler,
+		},
+		{
+			MethodName: "ListUsers",
+			Handler:    _ToDoList_ListUsers_Handler,
+		},
+		{
+			MethodName: "DisableUser",
+			Handler:    _ToDoList_DisableUser_Handler,
+		},
+		{
+			MethodName: "EnableUser",
+			Handler:    _ToDoList_EnableUser_Handler,
+		},
+		{
+			MethodName: "VerifyUserEmail",
+			Handler:    _ToDoList_VerifyUserEmail_Handler,
+		},
 		{
 			MethodName: "CreateCategory",
 			Handler:    _ToDoList_CreateCategory_Handler,
@@ -594,6 +1478,10 @@ var ToDoList_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListTask",
 			Handler:    _ToDoList_ListTask_Handler,
 		},
+		{
+			MethodName: "SearchTasks",
+			Handler:    _ToDoList_SearchTasks_Handler,
+		},
 		{
 			MethodName: "UpdateTask",
 			Handler:    _ToDoList_UpdateTask_Handler,
@@ -602,10 +1490,42 @@ var ToDoList_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteTask",
 			Handler:    _ToDoList_DeleteTask_Handler,
 		},
+		{
+			MethodName: "CreateTag",
+			Handler:    _ToDoList_CreateTag_Handler,
+		},
+		{
+			MethodName: "GetTag",
+			Handler:    _ToDoList_GetTag_Handler,
+		},
+		{
+			MethodName: "ListTag",
+			Handler:    _ToDoList_ListTag_Handler,
+		},
+		{
+			MethodName: "UpdateTag",
+			Handler:    _ToDoList_UpdateTag_Handler,
+		},
+		{
+			MethodName: "DeleteTag",
+			Handler:    _ToDoList_DeleteTag_Handler,
+		},
 		{
 			MethodName: "VerifyEmail",
 			Handler:    _ToDoList_VerifyEmail_Handler,
 		},
+		{
+			MethodName: "ResendVerificationEmail",
+			Handler:    _ToDoList_ResendVerificationEmail_Handler,
+		},
+		{
+			MethodName: "RequestPasswordReset",
+			Handler:    _ToDoList_RequestPasswordReset_Handler,
+		},
+		{
+			MethodName: "ResetPassword",
+			Handler:    _ToDoList_ResetPassword_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "todolist.proto",
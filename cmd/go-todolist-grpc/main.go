@@ -2,13 +2,18 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/config"
 	"go-todolist-grpc/internal/middleware"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
 	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"go-todolist-grpc/internal/pkg/oauth"
+	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service"
 	"go-todolist-grpc/internal/service/queue"
 	logger "log"
@@ -17,11 +22,14 @@ import (
 	"os"
 	"os/signal"
 	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
@@ -46,6 +54,18 @@ func main() {
 	// Init log
 	log.Init(cnf.LogLevel, cnf.LogFolderPath, strconv.Itoa(os.Getpid()), cnf.EnableConsoleOutput, cnf.EnableFileOutput)
 
+	// Init JWT keys
+	keySetErr := util.InitKeySet(&util.KeySetOption{
+		SigningMethod:  cnf.JwtSigningMethod,
+		SecretKey:      cnf.JwtSecretKey,
+		SigningKeyId:   cnf.JwtSigningKeyId,
+		KeyDir:         cnf.JwtKeyDir,
+		PublicKeyPaths: cnf.JwtPublicKeys,
+	})
+	if keySetErr != nil {
+		logger.Fatal(keySetErr)
+	}
+
 	// Init database
 	initDBErr := db.Init(&db.Option{
 		Host:                     cnf.DBHost,
@@ -71,19 +91,77 @@ func main() {
 	}
 	taskDistributor := queue.NewRedisTaskDistributor(redisOpt)
 
+	// Init login lockout, the counters live in Redis so that every instance shares them
+	loginGuard := lockout.NewGuard(
+		lockout.NewRedisStore(redis.NewClient(&redis.Options{Addr: redisOpt.Addr})),
+		lockout.Policy{
+			MaxEmailAttempts: cnf.LoginMaxEmailAttempts,
+			MaxIPAttempts:    cnf.LoginMaxIpAttempts,
+			Window:           time.Duration(cnf.LoginAttemptWindow) * time.Minute,
+			BaseLockout:      time.Duration(cnf.LoginLockoutBase) * time.Minute,
+			MaxLockout:       time.Duration(cnf.LoginLockoutMax) * time.Minute,
+		},
+	)
+	middleware.SetTrustedProxyCount(cnf.TrustedProxyCount)
+
 	// Create a context that listens for the interrupt signal from the OS.
 	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
 	defer stop()
 	waitGroup, ctx := errgroup.WithContext(ctx)
 
+	// Init mailer
+	mailer, mailerErr := mail.New(&mail.Option{
+		Driver:             cnf.MailDriver,
+		Region:             cnf.AWSRegion,
+		AWSAccessKeyId:     cnf.AWSAccessKeyId,
+		AWSSecretAccessKey: cnf.AWSSecretAccessKey,
+		ConfigurationSet:   cnf.SESConfigurationSet,
+		SMTPHost:           cnf.SMTPHost,
+		SMTPPort:           cnf.SMTPPort,
+		SMTPUsername:       cnf.SMTPUsername,
+		SMTPPassword:       cnf.SMTPPassword,
+		SMTPStartTLS:       cnf.SMTPStartTLS,
+		FileDir:            cnf.MailFileDir,
+	})
+	if mailerErr != nil {
+		logger.Fatal(mailerErr)
+	}
+
+	// Load mail templates
+	mailTemplates, mailTemplatesErr := mail.LoadRegistry(cnf.MailTemplateDir)
+	if mailTemplatesErr != nil {
+		logger.Fatal(mailTemplatesErr)
+	}
+
+	// Init the builder of the links in outgoing mail
+	linkBuilder, linkBuilderErr := util.NewLinkBuilder(cnf.PublicBaseUrl, cnf.LinkSigningKey)
+	if linkBuilderErr != nil {
+		logger.Fatal(linkBuilderErr)
+	}
+	if cnf.ResetPasswordUrl == "" {
+		logger.Fatal("RESET_PASSWORD_URL is not set")
+	}
+	if cnf.SecretCodeHashKey == "" {
+		logger.Fatal("SECRET_CODE_HASH_KEY is not set")
+	}
+	if cnf.TotpEncryptionKey == "" {
+		logger.Fatal("TOTP_ENCRYPTION_KEY is not set")
+	}
+
+	// Init OAuth login, the providers are optional
+	oauthFlow, oauthFlowErr := initOAuthFlow(ctx, cnf)
+	if oauthFlowErr != nil {
+		logger.Fatal(oauthFlowErr)
+	}
+
 	// Init Redis queue
-	runTaskProcessor(redisOpt, ctx, waitGroup)
+	runTaskProcessor(redisOpt, mailer, mailTemplates, linkBuilder, ctx, waitGroup)
 
 	// Init Http server
-	runGatewayServer(cnf, ctx, waitGroup, taskDistributor)
+	runGatewayServer(cnf, ctx, waitGroup, taskDistributor, loginGuard, oauthFlow)
 
 	// Init gRPC server
-	runGrpcServer(cnf, ctx, waitGroup, taskDistributor)
+	runGrpcServer(cnf, ctx, waitGroup, taskDistributor, loginGuard)
 
 	err := waitGroup.Wait()
 	if err != nil {
@@ -91,8 +169,8 @@ func main() {
 	}
 }
 
-func runTaskProcessor(redisOpt asynq.RedisClientOpt, ctx context.Context, waitGroup *errgroup.Group) {
-	taskProcessor := queue.NewRedisTaskProcessor(redisOpt)
+func runTaskProcessor(redisOpt asynq.RedisClientOpt, mailer mail.Mailer, templates *mail.Registry, links *util.LinkBuilder, ctx context.Context, waitGroup *errgroup.Group) {
+	taskProcessor := queue.NewRedisTaskProcessor(redisOpt, mailer, templates, links)
 	log.Info.Print("start task processor")
 
 	err := taskProcessor.Start()
@@ -111,13 +189,13 @@ func runTaskProcessor(redisOpt asynq.RedisClientOpt, ctx context.Context, waitGr
 	})
 }
 
-func runGrpcServer(cnf *config.Config, ctx context.Context, waitGroup *errgroup.Group, taskDistributor queue.TaskDistributor) {
+func runGrpcServer(cnf *config.Config, ctx context.Context, waitGroup *errgroup.Group, taskDistributor queue.TaskDistributor, loginGuard *lockout.Guard) {
 	grpcServer := grpc.NewServer(
 		grpc.UnaryInterceptor(grpc_middleware.ChainUnaryServer(
 			middleware.VerifyTokenByGrpc(cnf),
 		)),
 	)
-	pb.RegisterToDoListServer(grpcServer, service.NewServer(taskDistributor))
+	pb.RegisterToDoListServer(grpcServer, service.NewServer(taskDistributor, loginGuard))
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", ":"+cnf.GprcServerPort)
@@ -149,7 +227,7 @@ func runGrpcServer(cnf *config.Config, ctx context.Context, waitGroup *errgroup.
 	})
 }
 
-func runGatewayServer(cnf *config.Config, ctx context.Context, waitGroup *errgroup.Group, taskDistributor queue.TaskDistributor) {
+func runGatewayServer(cnf *config.Config, ctx context.Context, waitGroup *errgroup.Group, taskDistributor queue.TaskDistributor, loginGuard *lockout.Guard, oauthFlow *oauth.Flow) {
 	jsonOption := runtime.WithMarshalerOption(runtime.MIMEWildcard, &runtime.JSONPb{
 		MarshalOptions: protojson.MarshalOptions{
 			UseProtoNames: true,
@@ -168,13 +246,18 @@ func runGatewayServer(cnf *config.Config, ctx context.Context, waitGroup *errgro
 		return md
 	})
 
+	server := service.NewServer(taskDistributor, loginGuard)
 	grpcMux := runtime.NewServeMux(jsonOption, option)
-	if err := pb.RegisterToDoListHandlerServer(ctx, grpcMux, service.NewServer(taskDistributor)); err != nil {
+	if err := pb.RegisterToDoListHandlerServer(ctx, grpcMux, server); err != nil {
 		log.Error.Printf("cannot register handler server: %v", err)
 	}
 
 	mux := http.NewServeMux()
 	mux.Handle("/", grpcMux)
+	mux.HandleFunc("/.well-known/jwks.json", serveJWKS)
+	if oauthFlow != nil {
+		mux.Handle("/v1/oauth/", server.OAuthHandler(oauthFlow))
+	}
 
 	handler := middleware.VerifyTokenByGateway(cnf)(mux)
 
@@ -212,3 +295,64 @@ func runGatewayServer(cnf *config.Config, ctx context.Context, waitGroup *errgro
 		return nil
 	})
 }
+
+// initOAuthFlow enables the providers whose client ID is set, it returns nil when there is none
+func initOAuthFlow(ctx context.Context, cnf *config.Config) (*oauth.Flow, error) {
+	configs := []oauth.ProviderConfig{}
+	if cnf.GoogleClientId != "" {
+		issuer := cnf.GoogleIssuer
+		if issuer == "" {
+			issuer = "https://accounts.google.com"
+		}
+		configs = append(configs, oauth.ProviderConfig{
+			Name:         "google",
+			Kind:         oauth.KindOIDC,
+			ClientId:     cnf.GoogleClientId,
+			ClientSecret: cnf.GoogleClientSecret,
+			Issuer:       issuer,
+		})
+	}
+	if cnf.GithubClientId != "" {
+		configs = append(configs, oauth.ProviderConfig{
+			Name:         "github",
+			Kind:         oauth.KindGitHub,
+			ClientId:     cnf.GithubClientId,
+			ClientSecret: cnf.GithubClientSecret,
+		})
+	}
+	if len(configs) == 0 {
+		return nil, nil
+	}
+
+	providers := make([]oauth.Provider, 0, len(configs))
+	for _, providerConfig := range configs {
+		providerConfig.RedirectURL = strings.TrimRight(cnf.PublicBaseUrl, "/") + "/v1/oauth/" + providerConfig.Name + "/callback"
+		provider, err := oauth.NewProvider(ctx, providerConfig)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, provider)
+	}
+
+	flow, err := oauth.NewFlow(cnf.OauthStateKey, strings.HasPrefix(cnf.PublicBaseUrl, "https://"), providers...)
+	if err != nil {
+		return nil, err
+	}
+	log.Info.Printf("oauth providers: %s", strings.Join(flow.Providers(), ", "))
+
+	return flow, nil
+}
+
+// serveJWKS publishes the public keys so that other services can verify our tokens
+func serveJWKS(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	if err := json.NewEncoder(w).Encode(util.GetKeySet().JWKS()); err != nil {
+		log.Error.Printf("failed to encode JWKS: %v", err)
+	}
+}
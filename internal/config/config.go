@@ -14,6 +14,23 @@ type Config struct {
 	HttpServerPort string `mapstructure:"HTTP_SERVER_PORT"`
 	GprcServerPort string `mapstructure:"GRPC_SERVER_PORT"`
 
+	PublicBaseUrl    string `mapstructure:"PUBLIC_BASE_URL"`
+	LinkSigningKey   string `mapstructure:"LINK_SIGNING_KEY"`
+	ResetPasswordUrl string `mapstructure:"RESET_PASSWORD_URL"`
+
+	SecretCodeHashKey string `mapstructure:"SECRET_CODE_HASH_KEY"`
+
+	TotpEncryptionKey string `mapstructure:"TOTP_ENCRYPTION_KEY"`
+	TotpIssuer        string `mapstructure:"TOTP_ISSUER"`
+	TotpChallengeTtl  int    `mapstructure:"TOTP_CHALLENGE_TTL"`
+
+	OauthStateKey      string `mapstructure:"OAUTH_STATE_KEY"`
+	GoogleClientId     string `mapstructure:"GOOGLE_CLIENT_ID"`
+	GoogleClientSecret string `mapstructure:"GOOGLE_CLIENT_SECRET"`
+	GoogleIssuer       string `mapstructure:"GOOGLE_ISSUER"`
+	GithubClientId     string `mapstructure:"GITHUB_CLIENT_ID"`
+	GithubClientSecret string `mapstructure:"GITHUB_CLIENT_SECRET"`
+
 	DBHost                     string `mapstructure:"DB_HOST"`
 	DBPort                     string `mapstructure:"DB_PORT"`
 	DBUser                     string `mapstructure:"DB_USER"`
@@ -26,19 +43,44 @@ type Config struct {
 
 	RedisPort string `mapstructure:"REDIS_PORT"`
 
-	BcryptCost   int    `mapstructure:"BCRYPT_COST"`
-	JwtSecretKey string `mapstructure:"JWT_SECRET_KEY"`
-	JwtTtl       int    `mapstructure:"JWT_TTL"`
+	BcryptCost    int    `mapstructure:"BCRYPT_COST"`
+	JwtSecretKey  string `mapstructure:"JWT_SECRET_KEY"`
+	JwtTtl        int    `mapstructure:"JWT_TTL"`
+	JwtRefreshTtl int    `mapstructure:"JWT_REFRESH_TTL"`
+
+	JwtSigningMethod string `mapstructure:"JWT_SIGNING_METHOD"`
+	JwtSigningKeyId  string `mapstructure:"JWT_SIGNING_KEY_ID"`
+	JwtKeyDir        string `mapstructure:"JWT_KEY_DIR"`
+	JwtPublicKeys    string `mapstructure:"JWT_PUBLIC_KEYS"`
+
+	LoginMaxEmailAttempts int `mapstructure:"LOGIN_MAX_EMAIL_ATTEMPTS"`
+	LoginMaxIpAttempts    int `mapstructure:"LOGIN_MAX_IP_ATTEMPTS"`
+	LoginAttemptWindow    int `mapstructure:"LOGIN_ATTEMPT_WINDOW"`
+	LoginLockoutBase      int `mapstructure:"LOGIN_LOCKOUT_BASE"`
+	LoginLockoutMax       int `mapstructure:"LOGIN_LOCKOUT_MAX"`
+	TrustedProxyCount     int `mapstructure:"TRUSTED_PROXY_COUNT"`
 
 	LogLevel            int    `mapstructure:"LOG_LEVEL"`
 	LogFolderPath       string `mapstructure:"LOG_FOLDER_PATH"`
 	EnableConsoleOutput bool   `mapstructure:"ENABLE_CONSOLE_OUTPUT"`
 	EnableFileOutput    bool   `mapstructure:"ENABLE_FILE_OUTPUT"`
 
-	AWSAccessKeyId     string `mapstructure:"AWS_ACCESS_KEY_ID"`
-	AWSSecretAccessKey string `mapstructure:"AWS_SECRET_ACCESS_KEY"`
-	EmailSenderName    string `mapstructure:"EMAIL_SENDER_NAME"`
-	EmailSenderAddress string `mapstructure:"EMAIL_SENDER_ADDRESS"`
+	AWSAccessKeyId      string `mapstructure:"AWS_ACCESS_KEY_ID"`
+	AWSSecretAccessKey  string `mapstructure:"AWS_SECRET_ACCESS_KEY"`
+	AWSRegion           string `mapstructure:"AWS_REGION"`
+	SESConfigurationSet string `mapstructure:"SES_CONFIGURATION_SET"`
+	EmailSenderName     string `mapstructure:"EMAIL_SENDER_NAME"`
+	EmailSenderAddress  string `mapstructure:"EMAIL_SENDER_ADDRESS"`
+
+	MailDriver   string `mapstructure:"MAIL_DRIVER"`
+	SMTPHost     string `mapstructure:"SMTP_HOST"`
+	SMTPPort     string `mapstructure:"SMTP_PORT"`
+	SMTPUsername string `mapstructure:"SMTP_USERNAME"`
+	SMTPPassword string `mapstructure:"SMTP_PASSWORD"`
+	SMTPStartTLS bool   `mapstructure:"SMTP_STARTTLS"`
+	MailFileDir  string `mapstructure:"MAIL_FILE_DIR"`
+
+	MailTemplateDir string `mapstructure:"MAIL_TEMPLATE_DIR"`
 }
 
 func Load() error {
@@ -17,6 +17,14 @@ func TestLoadAndGet(t *testing.T) {
 		var mockConfigContent bytes.Buffer
 		mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
 		mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+		mockConfigContent.WriteString("PUBLIC_BASE_URL=" + config.PublicBaseUrl + "\n")
+		mockConfigContent.WriteString("LINK_SIGNING_KEY=" + config.LinkSigningKey + "\n")
+		mockConfigContent.WriteString("RESET_PASSWORD_URL=" + config.ResetPasswordUrl + "\n")
+		mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+		mockConfigContent.WriteString("TOTP_ENCRYPTION_KEY=" + config.TotpEncryptionKey + "\n")
+		mockConfigContent.WriteString("TOTP_ISSUER=" + config.TotpIssuer + "\n")
+		mockConfigContent.WriteString("TOTP_CHALLENGE_TTL=" + strconv.Itoa(config.TotpChallengeTtl) + "\n")
+		mockConfigContent.WriteString("OAUTH_STATE_KEY=" + config.OauthStateKey + "\n")
 		mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
 		mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
 		mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
@@ -28,6 +36,7 @@ func TestLoadAndGet(t *testing.T) {
 		mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
 		mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
 		mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+		mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
 		mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
 		mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
 		mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
@@ -48,6 +57,14 @@ func TestLoadAndGet(t *testing.T) {
 		cnf := config.Get()
 		assert.Equal(t, config.HttpPort, cnf.HttpServerPort)
 		assert.Equal(t, config.GrpcPort, cnf.GprcServerPort)
+		assert.Equal(t, config.PublicBaseUrl, cnf.PublicBaseUrl)
+		assert.Equal(t, config.LinkSigningKey, cnf.LinkSigningKey)
+		assert.Equal(t, config.ResetPasswordUrl, cnf.ResetPasswordUrl)
+		assert.Equal(t, config.SecretCodeHashKey, cnf.SecretCodeHashKey)
+		assert.Equal(t, config.TotpEncryptionKey, cnf.TotpEncryptionKey)
+		assert.Equal(t, config.TotpIssuer, cnf.TotpIssuer)
+		assert.Equal(t, config.TotpChallengeTtl, cnf.TotpChallengeTtl)
+		assert.Equal(t, config.OauthStateKey, cnf.OauthStateKey)
 		assert.Equal(t, config.SourceHost, cnf.DBHost)
 		assert.Equal(t, config.SourcePort, cnf.DBPort)
 		assert.Equal(t, config.SourceUser, cnf.DBUser)
@@ -59,6 +76,7 @@ func TestLoadAndGet(t *testing.T) {
 		assert.Equal(t, config.BcryptCost, cnf.BcryptCost)
 		assert.Equal(t, config.JwtSecretKey, cnf.JwtSecretKey)
 		assert.Equal(t, config.JwtTtl, cnf.JwtTtl)
+		assert.Equal(t, config.JwtRefreshTtl, cnf.JwtRefreshTtl)
 		assert.Equal(t, config.LogLevel, cnf.LogLevel)
 		assert.Equal(t, config.LogFolderPath, cnf.LogFolderPath)
 		assert.True(t, cnf.EnableConsoleOutput)
@@ -5,53 +5,132 @@ import (
 	"encoding/json"
 	"errors"
 	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
 	"go-todolist-grpc/internal/pkg/log"
 	"go-todolist-grpc/internal/pkg/util"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
 
-// List of methods that require authentication
-var authRequiredMethods = map[string]bool{
-	// gRPC
-	"/pb.ToDoList/UpdateUser":     true,
-	"/pb.ToDoList/CreateCategory": true,
-	"/pb.ToDoList/GetCategory":    true,
-	"/pb.ToDoList/ListCategory":   true,
-	"/pb.ToDoList/UpdateCategory": true,
-	"/pb.ToDoList/DeleteCategory": true,
-	"/pb.ToDoList/CreateTask":     true,
-	"/pb.ToDoList/GetTask":        true,
-	"/pb.ToDoList/ListTask":       true,
-	"/pb.ToDoList/UpdateTask":     true,
-	"/pb.ToDoList/DeleteTask":     true,
-
-	// gateway
-	"/v1/user/update":     true,
-	"/v1/category/create": true,
-	"/v1/category/get":    true,
-	"/v1/category/list":   true,
-	"/v1/category/update": true,
-	"/v1/category/delete": true,
-	"/v1/task/create":     true,
-	"/v1/task/get":        true,
-	"/v1/task/list":       true,
-	"/v1/task/update":     true,
-	"/v1/task/delete":     true,
+// The last use of an API token is recorded at most this often, not on every request
+const apiTokenTouchInterval = time.Minute
+
+var (
+	errInvalidToken    = errors.New("invalid token")
+	errAccountDisabled = errors.New("account has been disabled")
+)
+
+// authenticate returns the claims of a JWT access token or of a personal API token
+func authenticate(cnf *config.Config, token string) (*util.CustomClaims, error) {
+	if util.IsApiToken(token) {
+		return authenticateApiToken(cnf, token)
+	}
+
+	// Validate token
+	claims, err := util.ParseToken(util.GetKeySet(), token)
+	if err != nil {
+		log.Error.Printf("invalid token: %v", err)
+		return nil, errInvalidToken
+	}
+
+	// Only access tokens of sessions that are still active are accepted
+	if claims.TokenType != util.TokenTypeAccess {
+		return nil, errInvalidToken
+	}
+	isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), claims.ID)
+	if revokedErr != nil {
+		log.Error.Printf("failed to check if the token is revoked: %v", revokedErr)
+		return nil, errInvalidToken
+	}
+	if isRevoked {
+		return nil, errInvalidToken
+	}
+
+	// The tokens issued before the account was disabled stop working at once
+	if !model.IsUserActive(db.GetConn(), claims.UserID) {
+		return nil, errAccountDisabled
+	}
+
+	return claims, nil
+}
+
+// authenticateApiToken grants the permissions of the role of the owner that the scopes of the token allow
+func authenticateApiToken(cnf *config.Config, token string) (*util.CustomClaims, error) {
+	conn := db.GetConn()
+	now := time.Now().UTC()
+
+	apiToken := model.GetApiTokenByHash(conn, util.HashSecretCode(cnf.SecretCodeHashKey, token))
+	if apiToken == nil || !apiToken.IsActive(now) {
+		return nil, errInvalidToken
+	}
+
+	user := model.GetUserByID(conn, apiToken.UserId)
+	if user == nil {
+		return nil, errInvalidToken
+	}
+	if !user.Status {
+		return nil, errAccountDisabled
+	}
+
+	rolePermissions, err := model.ListPermissionNamesByRole(conn, user.Role)
+	if err != nil {
+		log.Error.Printf("failed to get permissions: %v", err)
+		return nil, errInvalidToken
+	}
+	permissions := make([]string, 0, len(rolePermissions))
+	for _, permission := range rolePermissions {
+		if util.HasScope(apiToken.Scopes, permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenTouchInterval {
+		if err := model.TouchApiToken(conn, apiToken.ID, now); err != nil {
+			log.Error.Printf("failed to record the use of api token: %v", err)
+		}
+	}
+
+	return &util.CustomClaims{
+		UserID:      user.ID,
+		Role:        user.Role,
+		TokenType:   util.TokenTypeApi,
+		Permissions: permissions,
+		Scopes:      apiToken.Scopes,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID: strconv.Itoa(apiToken.ID),
+		},
+	}, nil
 }
 
 func VerifyTokenByGrpc(cnf *config.Config) grpc.UnaryServerInterceptor {
+	policies := loadPolicies()
+
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Never trust claims sent by the client
+		md, ok := metadata.FromIncomingContext(ctx)
+		if ok {
+			md = md.Copy()
+			md.Delete("x-auth-claims")
+			ctx = metadata.NewIncomingContext(ctx, md)
+		}
+
 		// Check if the method requires authentication
-		if !authRequiredMethods[info.FullMethod] {
+		policy, declared := policies.byMethod(info.FullMethod)
+		if !declared {
+			return nil, errPermissionDenied
+		}
+		if !policy.required {
 			return handler(ctx, req)
 		}
 
 		// Extract token from metadata
-		md, ok := metadata.FromIncomingContext(ctx)
 		if !ok {
 			return nil, errors.New("missing metadata")
 		}
@@ -63,11 +142,13 @@ func VerifyTokenByGrpc(cnf *config.Config) grpc.UnaryServerInterceptor {
 
 		token := strings.TrimPrefix(tokens[0], "Bearer ")
 
-		// Validate token
-		claims, err := util.ParseToken(cnf.JwtSecretKey, token)
+		claims, err := authenticate(cnf, token)
 		if err != nil {
-			log.Error.Printf("invalid token: %v", err)
-			return nil, errors.New("invalid token")
+			return nil, err
+		}
+
+		if err := authorize(claims, policy.permissions); err != nil {
+			return nil, err
 		}
 
 		claimsJSON, _ := json.Marshal(claims)
@@ -83,10 +164,24 @@ func VerifyTokenByGrpc(cnf *config.Config) grpc.UnaryServerInterceptor {
 }
 
 func VerifyTokenByGateway(cnf *config.Config) func(http.Handler) http.Handler {
+	policies := loadPolicies()
+
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Check if the method requires authentication
-			if !authRequiredMethods[r.URL.Path] {
+			// Never trust claims sent by the client
+			r.Header.Del("X-Auth-Claims")
+
+			// Check if the method requires authentication, the paths not served by the methods are left to their handlers
+			policy, declared, isRoute := policies.byRoute(r.URL.Path)
+			if !isRoute {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !declared {
+				http.Error(w, errPermissionDenied.Error(), http.StatusForbidden)
+				return
+			}
+			if !policy.required {
 				next.ServeHTTP(w, r)
 				return
 			}
@@ -100,11 +195,14 @@ func VerifyTokenByGateway(cnf *config.Config) func(http.Handler) http.Handler {
 
 			token := strings.TrimPrefix(authHeader, "Bearer ")
 
-			// Validate token
-			claims, err := util.ParseToken(cnf.JwtSecretKey, token)
+			claims, err := authenticate(cnf, token)
 			if err != nil {
-				log.Error.Printf("invalid token: %v", err)
-				http.Error(w, "invalid token", http.StatusUnauthorized)
+				http.Error(w, err.Error(), http.StatusUnauthorized)
+				return
+			}
+
+			if err := authorize(claims, policy.permissions); err != nil {
+				http.Error(w, err.Error(), http.StatusForbidden)
 				return
 			}
 
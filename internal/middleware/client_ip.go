@@ -0,0 +1,56 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// The number of proxies in front of the servers that append to X-Forwarded-For, e.g. 1 behind the ingress
+var trustedProxyCount atomic.Int32
+
+// SetTrustedProxyCount sets the number of proxies in front of the servers, a negative count is taken as 0
+func SetTrustedProxyCount(n int) {
+	trustedProxyCount.Store(int32(max(n, 0)))
+}
+
+// GetClientIPFromContext returns the address of the client, or an empty string when it is unknown.
+//
+// The hops the request went through are the X-Forwarded-For entries followed by the peer address,
+// the gateway runs in process so it has no peer but appends the remote address it saw to X-Forwarded-For instead.
+// The client may send any X-Forwarded-For entries of its own, so only the hops from the right are trusted:
+// the last hop is the one connected to the servers and every trusted proxy adds the hop before it.
+func GetClientIPFromContext(ctx context.Context) string {
+	hops := make([]string, 0)
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for _, forwarded := range md.Get("x-forwarded-for") {
+			for _, hop := range strings.Split(forwarded, ",") {
+				hops = append(hops, strings.TrimSpace(hop))
+			}
+		}
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		hops = append(hops, host)
+	}
+
+	if len(hops) == 0 {
+		return ""
+	}
+
+	// Fewer hops than proxies means every hop was added by a trusted proxy
+	i := max(len(hops)-1-int(trustedProxyCount.Load()), 0)
+	if ip := net.ParseIP(hops[i]); ip != nil {
+		return ip.String()
+	}
+
+	return ""
+}
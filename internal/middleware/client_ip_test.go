@@ -0,0 +1,46 @@
+package middleware_test
+
+import (
+	"context"
+	"go-todolist-grpc/internal/middleware"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestGetClientIPFromContext(t *testing.T) {
+	defer middleware.SetTrustedProxyCount(0)
+
+	withPeer := func(ctx context.Context, ip string) context.Context {
+		return peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}})
+	}
+	withForwarded := func(forwarded string) context.Context {
+		return metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", forwarded))
+	}
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		proxies int
+		want    string
+	}{
+		{"Peer", withPeer(context.Background(), "203.0.113.7"), 0, "203.0.113.7"},
+		{"SpoofedForwardedIgnored", withPeer(withForwarded("1.2.3.4"), "203.0.113.7"), 0, "203.0.113.7"},
+		{"GatewayRemoteAddr", withForwarded("1.2.3.4, 203.0.113.7"), 0, "203.0.113.7"},
+		{"BehindIngress", withForwarded("1.2.3.4, 203.0.113.7, 10.0.0.2"), 1, "203.0.113.7"},
+		{"BehindIngressGrpc", withPeer(withForwarded("1.2.3.4, 203.0.113.7"), "10.0.0.2"), 1, "203.0.113.7"},
+		{"FewerHopsThanProxies", withForwarded("203.0.113.7"), 2, "203.0.113.7"},
+		{"InvalidHop", withForwarded("1.2.3.4, unknown"), 0, ""},
+		{"Unknown", context.Background(), 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			middleware.SetTrustedProxyCount(tt.proxies)
+			assert.Equal(t, tt.want, middleware.GetClientIPFromContext(tt.ctx))
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+	errPermissionDenied  = errors.New("permission denied")
+	errInsufficientScope = errors.New("insufficient scope")
+)
+
+// methodPolicy is the authorization policy a method declares with the options of api/proto/auth.proto
+type methodPolicy struct {
+	required    bool
+	permissions []string
+}
+
+// policies maps the methods of the service, by gRPC full method and by gateway path, to their policy.
+// A method without a policy is denied, so a method added without the options is never public by mistake.
+type policies struct {
+	methods map[string]*methodPolicy
+	routes  map[string]string
+}
+
+// loadPolicies reads the policies of the ToDoList service from its descriptor
+func loadPolicies() *policies {
+	return newPolicies(pb.File_todolist_proto.Services().ByName("ToDoList"))
+}
+
+func newPolicies(service protoreflect.ServiceDescriptor) *policies {
+	p := &policies{
+		methods: make(map[string]*methodPolicy),
+		routes:  make(map[string]string),
+	}
+
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+		opts := method.Options()
+
+		if path := httpRulePath(proto.GetExtension(opts, annotations.E_Http).(*annotations.HttpRule)); path != "" {
+			p.routes[path] = fullMethod
+		}
+
+		if !proto.HasExtension(opts, pb.E_Required) {
+			log.Error.Printf("method %s declares no auth policy, it is denied", fullMethod)
+			continue
+		}
+
+		p.methods[fullMethod] = &methodPolicy{
+			required:    proto.GetExtension(opts, pb.E_Required).(bool),
+			permissions: proto.GetExtension(opts, pb.E_Permissions).([]string),
+		}
+	}
+
+	return p
+}
+
+// httpRulePath returns the path the gateway serves the method at
+func httpRulePath(rule *annotations.HttpRule) string {
+	if rule == nil {
+		return ""
+	}
+
+	switch pattern := rule.GetPattern().(type) {
+	case *annotations.HttpRule_Get:
+		return pattern.Get
+	case *annotations.HttpRule_Post:
+		return pattern.Post
+	case *annotations.HttpRule_Put:
+		return pattern.Put
+	case *annotations.HttpRule_Delete:
+		return pattern.Delete
+	case *annotations.HttpRule_Patch:
+		return pattern.Patch
+	default:
+		return ""
+	}
+}
+
+// byMethod returns the policy of a gRPC method, declared is false when the method declares none
+func (p *policies) byMethod(fullMethod string) (policy *methodPolicy, declared bool) {
+	policy = p.methods[fullMethod]
+	return policy, policy != nil
+}
+
+// byRoute returns the policy of the method served at the gateway path,
+// isRoute is false for the paths that are not served by the methods of the service
+func (p *policies) byRoute(path string) (policy *methodPolicy, declared bool, isRoute bool) {
+	fullMethod, isRoute := p.routes[path]
+	if !isRoute {
+		return nil, false, false
+	}
+
+	policy, declared = p.byMethod(fullMethod)
+	return policy, declared, true
+}
+
+// authorize checks the claims hold the permissions, an API token holds at most the permissions its scopes allow
+func authorize(claims *util.CustomClaims, permissions []string) error {
+	for _, permission := range permissions {
+		if claims.HasPermission(permission) {
+			continue
+		}
+
+		if claims.TokenType == util.TokenTypeApi {
+			return errInsufficientScope
+		}
+
+		return errPermissionDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+package middleware_test
+
+import (
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestMethodsDeclarePolicy(t *testing.T) {
+	methods := pb.File_todolist_proto.Services().ByName("ToDoList").Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+		opts := method.Options()
+
+		assert.True(t, proto.HasExtension(opts, pb.E_Required), "%s declares no auth policy", method.Name())
+		if proto.GetExtension(opts, pb.E_Required).(bool) {
+			assert.NotEmpty(t, proto.GetExtension(opts, pb.E_Permissions), "%s requires no permission", method.Name())
+		}
+	}
+}
+
+func TestVerifyTokenByGrpc(t *testing.T) {
+	interceptor := middleware.VerifyTokenByGrpc(&config.Config{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	call := func(fullMethod string) (interface{}, error) {
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+		return interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: fullMethod}, handler)
+	}
+
+	t.Run("Success_Public", func(t *testing.T) {
+		res, err := call("/pb.ToDoList/Login")
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res)
+	})
+
+	t.Run("Failure_MissingToken", func(t *testing.T) {
+		res, err := call("/pb.ToDoList/ListTask")
+		assert.EqualError(t, err, "missing token")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Undeclared", func(t *testing.T) {
+		res, err := call("/pb.ToDoList/Unknown")
+		assert.EqualError(t, err, "permission denied")
+		assert.Nil(t, res)
+	})
+}
+
+func TestVerifyTokenByGateway(t *testing.T) {
+	handler := middleware.VerifyTokenByGateway(&config.Config{})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func(method string, path string) int {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(method, path, nil))
+		return w.Code
+	}
+
+	t.Run("Success_Public", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, call(http.MethodPost, "/v1/user/login"))
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/v1/user/verify_email"))
+	})
+
+	t.Run("Success_NotRoute", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, call(http.MethodGet, "/.well-known/jwks.json"))
+	})
+
+	t.Run("Failure_MissingToken", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/v1/task/list"))
+		assert.Equal(t, http.StatusUnauthorized, call(http.MethodPost, "/v1/admin/user/list"))
+	})
+}
@@ -0,0 +1,22 @@
+ALTER TABLE "public"."categories" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_category";
+
+-- Names are unique again across users, categories sharing a name are merged into the oldest one
+-- and their tasks are moved to it.
+UPDATE "public"."tasks" AS "t" SET "category_id" = "keep"."id"
+FROM "public"."categories" AS "c", (
+  SELECT DISTINCT ON ("name") "id", "name" FROM "public"."categories" ORDER BY "name", "id"
+) AS "keep"
+WHERE "t"."category_id" = "c"."id"
+  AND "c"."name" = "keep"."name"
+  AND "c"."id" <> "keep"."id";
+
+DELETE FROM "public"."categories" AS "c"
+USING "public"."categories" AS "keep"
+WHERE "c"."name" = "keep"."name" AND "keep"."id" < "c"."id";
+
+DROP INDEX IF EXISTS "user_id_name_uidx";
+CREATE UNIQUE INDEX "name_uidx" ON "public"."categories" USING btree (
+  "name"
+);
+
+ALTER TABLE "public"."categories" DROP COLUMN IF EXISTS "user_id";
\ No newline at end of file
@@ -0,0 +1,36 @@
+ALTER TABLE "public"."categories" ADD COLUMN "user_id" int4;
+
+COMMENT ON COLUMN "public"."categories"."user_id" IS '用戶ID';
+
+-- Existing categories are handed to the owner of their oldest task,
+-- every other user with tasks in the category gets a copy of their own and their tasks are moved to it.
+UPDATE "public"."categories" AS "c" SET "user_id" = (
+  SELECT "t"."user_id" FROM "public"."tasks" AS "t" WHERE "t"."category_id" = "c"."id" ORDER BY "t"."id" LIMIT 1
+);
+
+INSERT INTO "public"."categories" ("name", "user_id", "created_at", "updated_at")
+SELECT DISTINCT "c"."name", "t"."user_id", "c"."created_at", "c"."updated_at"
+FROM "public"."categories" AS "c"
+INNER JOIN "public"."tasks" AS "t" ON "t"."category_id" = "c"."id"
+WHERE "t"."user_id" <> "c"."user_id";
+
+UPDATE "public"."tasks" AS "t" SET "category_id" = "copy"."id"
+FROM "public"."categories" AS "c", "public"."categories" AS "copy"
+WHERE "t"."category_id" = "c"."id"
+  AND "t"."user_id" <> "c"."user_id"
+  AND "copy"."name" = "c"."name"
+  AND "copy"."user_id" = "t"."user_id";
+
+-- Categories no task refers to have no owner and are removed.
+DELETE FROM "public"."categories" WHERE "user_id" IS NULL;
+
+ALTER TABLE "public"."categories" ALTER COLUMN "user_id" SET NOT NULL;
+
+DROP INDEX IF EXISTS "name_uidx";
+CREATE UNIQUE INDEX "user_id_name_uidx" ON "public"."categories" USING btree (
+  "user_id",
+  "name"
+);
+
+ALTER TABLE "public"."categories"
+  ADD CONSTRAINT "users_user_id_foreign_category" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1,18 @@
+DROP INDEX IF EXISTS "user_id_category_id_title_uidx";
+
+-- Titles were only unique per user and category,
+-- every task but the oldest one of a title gets its ID appended to the title.
+UPDATE "public"."tasks" AS "t"
+SET "title" = left("t"."title", 100 - length(' (#' || "t"."id" || ')')) || ' (#' || "t"."id" || ')'
+FROM (
+  SELECT "id", row_number() OVER (
+    PARTITION BY "title"
+    ORDER BY "id"
+  ) AS "rn"
+  FROM "public"."tasks"
+) AS "d"
+WHERE "t"."id" = "d"."id" AND "d"."rn" > 1;
+
+CREATE UNIQUE INDEX "title_uidx" ON "public"."tasks" USING btree (
+  "title"
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+DROP INDEX IF EXISTS "title_uidx";
+
+CREATE UNIQUE INDEX "user_id_category_id_title_uidx" ON "public"."tasks" USING btree (
+  "user_id",
+  "category_id",
+  "title"
+);
\ No newline at end of file
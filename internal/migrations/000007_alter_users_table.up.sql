@@ -0,0 +1,2 @@
+ALTER TABLE "public"."users" ADD COLUMN "role" varchar(16) NOT NULL DEFAULT 'user';
+COMMENT ON COLUMN "public"."users"."role" IS '角色 (user:一般用戶 admin:管理員)';
\ No newline at end of file
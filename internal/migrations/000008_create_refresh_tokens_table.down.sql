@@ -0,0 +1,5 @@
+ALTER TABLE "public"."refresh_tokens" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_refresh";
+
+DROP INDEX IF EXISTS "access_jti_idx";
+DROP INDEX IF EXISTS "jti_uidx";
+DROP TABLE IF EXISTS "public"."refresh_tokens";
\ No newline at end of file
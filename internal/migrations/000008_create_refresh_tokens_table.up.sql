@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS "public"."refresh_tokens" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "jti" varchar(64) NOT NULL,
+  "access_jti" varchar(64) NOT NULL,
+  "is_revoked" bool DEFAULT FALSE,
+  "expired_at" timestamptz NOT NULL,
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."refresh_tokens"."jti" IS '刷新令牌ID';
+COMMENT ON COLUMN "public"."refresh_tokens"."access_jti" IS '存取令牌ID';
+COMMENT ON COLUMN "public"."refresh_tokens"."is_revoked" IS '是否撤銷';
+COMMENT ON COLUMN "public"."refresh_tokens"."expired_at" IS '過期時間';
+COMMENT ON COLUMN "public"."refresh_tokens"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."refresh_tokens"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "jti_uidx" ON "public"."refresh_tokens" USING btree (
+  "jti"
+);
+
+CREATE INDEX "access_jti_idx" ON "public"."refresh_tokens" USING btree (
+  "access_jti"
+);
+
+ALTER TABLE "public"."refresh_tokens" ADD CONSTRAINT "users_user_id_foreign_refresh" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
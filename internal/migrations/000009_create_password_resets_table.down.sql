@@ -0,0 +1,3 @@
+ALTER TABLE "public"."password_resets" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_password_reset";
+
+DROP TABLE IF EXISTS "public"."password_resets";
\ No newline at end of file
@@ -0,0 +1,19 @@
+CREATE TABLE IF NOT EXISTS "public"."password_resets" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "email" varchar(64) NOT NULL,
+  "secret_code" varchar(255) NOT NULL,
+  "is_used" bool DEFAULT FALSE,
+  "expired_at" timestamptz NOT NULL DEFAULT (CURRENT_TIMESTAMP + interval '15 minutes'),
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."password_resets"."email" IS '信箱';
+COMMENT ON COLUMN "public"."password_resets"."secret_code" IS '安全碼';
+COMMENT ON COLUMN "public"."password_resets"."is_used" IS '是否使用';
+COMMENT ON COLUMN "public"."password_resets"."expired_at" IS '過期時間';
+COMMENT ON COLUMN "public"."password_resets"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."password_resets"."updated_at" IS '更新時間';
+
+ALTER TABLE "public"."password_resets" ADD CONSTRAINT "users_user_id_foreign_password_reset" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "language";
\ No newline at end of file
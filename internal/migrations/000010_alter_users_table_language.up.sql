@@ -0,0 +1,2 @@
+ALTER TABLE "public"."users" ADD COLUMN "language" varchar(16) NOT NULL DEFAULT 'en';
+COMMENT ON COLUMN "public"."users"."language" IS '語系 (en:英文 zh-TW:繁體中文)';
\ No newline at end of file
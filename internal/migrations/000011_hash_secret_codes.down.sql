@@ -0,0 +1,8 @@
+-- The plain text codes cannot be restored, the column keeps the hashes
+ALTER TABLE "public"."verify_emails" ALTER COLUMN "secret_code_hash" TYPE varchar(255);
+ALTER TABLE "public"."verify_emails" RENAME COLUMN "secret_code_hash" TO "secret_code";
+COMMENT ON COLUMN "public"."verify_emails"."secret_code" IS '安全碼';
+
+ALTER TABLE "public"."password_resets" ALTER COLUMN "secret_code_hash" TYPE varchar(255);
+ALTER TABLE "public"."password_resets" RENAME COLUMN "secret_code_hash" TO "secret_code";
+COMMENT ON COLUMN "public"."password_resets"."secret_code" IS '安全碼';
\ No newline at end of file
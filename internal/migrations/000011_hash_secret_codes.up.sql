@@ -0,0 +1,15 @@
+-- Outstanding codes were stored in plain text, expire them so that users request a new link
+UPDATE "public"."verify_emails" SET "expired_at" = CURRENT_TIMESTAMP WHERE "is_used" = FALSE AND "expired_at" > CURRENT_TIMESTAMP;
+UPDATE "public"."password_resets" SET "expired_at" = CURRENT_TIMESTAMP WHERE "is_used" = FALSE AND "expired_at" > CURRENT_TIMESTAMP;
+
+-- Do not keep the old plain text values around
+UPDATE "public"."verify_emails" SET "secret_code" = encode(sha256("secret_code"::bytea), 'hex');
+UPDATE "public"."password_resets" SET "secret_code" = encode(sha256("secret_code"::bytea), 'hex');
+
+ALTER TABLE "public"."verify_emails" RENAME COLUMN "secret_code" TO "secret_code_hash";
+ALTER TABLE "public"."verify_emails" ALTER COLUMN "secret_code_hash" TYPE varchar(64);
+COMMENT ON COLUMN "public"."verify_emails"."secret_code_hash" IS '安全碼雜湊 (HMAC-SHA256)';
+
+ALTER TABLE "public"."password_resets" RENAME COLUMN "secret_code" TO "secret_code_hash";
+ALTER TABLE "public"."password_resets" ALTER COLUMN "secret_code_hash" TYPE varchar(64);
+COMMENT ON COLUMN "public"."password_resets"."secret_code_hash" IS '安全碼雜湊 (HMAC-SHA256)';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "public"."audit_logs" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_audit_log";
+
+DROP TABLE IF EXISTS "public"."audit_logs";
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "public"."audit_logs" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4,
+  "action" varchar(64) NOT NULL,
+  "subject" varchar(128) NOT NULL DEFAULT '',
+  "ip" varchar(64) NOT NULL DEFAULT '',
+  "detail" text NOT NULL DEFAULT '',
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN "public"."audit_logs"."user_id" IS '用戶ID (可為空)';
+COMMENT ON COLUMN "public"."audit_logs"."action" IS '事件';
+COMMENT ON COLUMN "public"."audit_logs"."subject" IS '對象';
+COMMENT ON COLUMN "public"."audit_logs"."ip" IS '來源IP';
+COMMENT ON COLUMN "public"."audit_logs"."detail" IS '詳細內容';
+COMMENT ON COLUMN "public"."audit_logs"."created_at" IS '新增時間';
+
+CREATE INDEX "audit_logs_action_created_at_idx" ON "public"."audit_logs" USING btree (
+  "action",
+  "created_at"
+);
+
+ALTER TABLE "public"."audit_logs" ADD CONSTRAINT "users_user_id_foreign_audit_log" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE SET NULL ON UPDATE NO ACTION;
\ No newline at end of file
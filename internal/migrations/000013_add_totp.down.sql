@@ -0,0 +1,8 @@
+ALTER TABLE "public"."recovery_codes" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_recovery_code";
+
+DROP INDEX IF EXISTS "recovery_codes_user_id_idx";
+DROP TABLE IF EXISTS "public"."recovery_codes";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "totp_last_step";
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "is_totp_enabled";
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "totp_secret";
\ No newline at end of file
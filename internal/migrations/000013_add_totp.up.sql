@@ -0,0 +1,27 @@
+ALTER TABLE "public"."users" ADD COLUMN "totp_secret" varchar(255);
+ALTER TABLE "public"."users" ADD COLUMN "is_totp_enabled" bool NOT NULL DEFAULT FALSE;
+ALTER TABLE "public"."users" ADD COLUMN "totp_last_step" int4 NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "public"."users"."totp_secret" IS '兩步驟驗證金鑰 (加密)';
+COMMENT ON COLUMN "public"."users"."is_totp_enabled" IS '是否啟用兩步驟驗證';
+COMMENT ON COLUMN "public"."users"."totp_last_step" IS '最後使用的驗證碼時段';
+
+CREATE TABLE IF NOT EXISTS "public"."recovery_codes" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "code_hash" varchar(64) NOT NULL,
+  "is_used" bool NOT NULL DEFAULT FALSE,
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."recovery_codes"."code_hash" IS '復原碼雜湊';
+COMMENT ON COLUMN "public"."recovery_codes"."is_used" IS '是否使用';
+COMMENT ON COLUMN "public"."recovery_codes"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."recovery_codes"."updated_at" IS '更新時間';
+
+CREATE INDEX "recovery_codes_user_id_idx" ON "public"."recovery_codes" USING btree (
+  "user_id"
+);
+
+ALTER TABLE "public"."recovery_codes" ADD CONSTRAINT "users_user_id_foreign_recovery_code" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
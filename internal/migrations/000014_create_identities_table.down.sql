@@ -0,0 +1,5 @@
+ALTER TABLE "public"."identities" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_identity";
+
+DROP INDEX IF EXISTS "identities_user_id_idx";
+DROP INDEX IF EXISTS "identities_provider_subject_uidx";
+DROP TABLE IF EXISTS "public"."identities";
\ No newline at end of file
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS "public"."identities" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "provider" varchar(32) NOT NULL,
+  "subject" varchar(255) NOT NULL,
+  "email" varchar(64) NOT NULL DEFAULT '',
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."identities"."provider" IS '登入提供者 (google, github)';
+COMMENT ON COLUMN "public"."identities"."subject" IS '提供者的用戶ID';
+COMMENT ON COLUMN "public"."identities"."email" IS '提供者的信箱';
+COMMENT ON COLUMN "public"."identities"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."identities"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "identities_provider_subject_uidx" ON "public"."identities" USING btree (
+  "provider",
+  "subject"
+);
+
+CREATE INDEX "identities_user_id_idx" ON "public"."identities" USING btree (
+  "user_id"
+);
+
+ALTER TABLE "public"."identities" ADD CONSTRAINT "users_user_id_foreign_identity" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1,5 @@
+ALTER TABLE "public"."api_tokens" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_api_token";
+
+DROP INDEX IF EXISTS "api_tokens_user_id_idx";
+DROP INDEX IF EXISTS "api_tokens_token_hash_uidx";
+DROP TABLE IF EXISTS "public"."api_tokens";
\ No newline at end of file
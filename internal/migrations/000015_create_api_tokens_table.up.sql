@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS "public"."api_tokens" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "name" varchar(64) NOT NULL,
+  "token_hash" varchar(64) NOT NULL,
+  "token_prefix" varchar(16) NOT NULL,
+  "scopes" text[] NOT NULL DEFAULT '{}',
+  "is_revoked" bool NOT NULL DEFAULT FALSE,
+  "last_used_at" timestamptz(6),
+  "expired_at" timestamptz(6),
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."api_tokens"."name" IS '權杖名稱';
+COMMENT ON COLUMN "public"."api_tokens"."token_hash" IS '權杖雜湊';
+COMMENT ON COLUMN "public"."api_tokens"."token_prefix" IS '權杖前綴 (用於辨識)';
+COMMENT ON COLUMN "public"."api_tokens"."scopes" IS '權限範圍';
+COMMENT ON COLUMN "public"."api_tokens"."is_revoked" IS '是否撤銷';
+COMMENT ON COLUMN "public"."api_tokens"."last_used_at" IS '最後使用時間';
+COMMENT ON COLUMN "public"."api_tokens"."expired_at" IS '過期時間 (空值為永久)';
+COMMENT ON COLUMN "public"."api_tokens"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."api_tokens"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "api_tokens_token_hash_uidx" ON "public"."api_tokens" USING btree (
+  "token_hash"
+);
+
+CREATE INDEX "api_tokens_user_id_idx" ON "public"."api_tokens" USING btree (
+  "user_id"
+);
+
+ALTER TABLE "public"."api_tokens" ADD CONSTRAINT "users_user_id_foreign_api_token" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1,13 @@
+ALTER TABLE "public"."users" DROP CONSTRAINT IF EXISTS "roles_name_foreign_user";
+
+ALTER TABLE "public"."role_permissions" DROP CONSTRAINT IF EXISTS "permissions_permission_id_foreign_role_permission";
+ALTER TABLE "public"."role_permissions" DROP CONSTRAINT IF EXISTS "roles_role_id_foreign_role_permission";
+
+DROP INDEX IF EXISTS "role_permissions_role_id_permission_id_uidx";
+DROP TABLE IF EXISTS "public"."role_permissions";
+
+DROP INDEX IF EXISTS "permissions_name_uidx";
+DROP TABLE IF EXISTS "public"."permissions";
+
+DROP INDEX IF EXISTS "roles_name_uidx";
+DROP TABLE IF EXISTS "public"."roles";
\ No newline at end of file
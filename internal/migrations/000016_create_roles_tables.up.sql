@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS "public"."roles" (
+  "id" SERIAL PRIMARY KEY,
+  "name" varchar(16) NOT NULL,
+  "description" varchar(64) NOT NULL DEFAULT '',
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."roles"."name" IS '角色名稱';
+COMMENT ON COLUMN "public"."roles"."description" IS '角色說明';
+COMMENT ON COLUMN "public"."roles"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."roles"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "roles_name_uidx" ON "public"."roles" USING btree (
+  "name"
+);
+
+CREATE TABLE IF NOT EXISTS "public"."permissions" (
+  "id" SERIAL PRIMARY KEY,
+  "name" varchar(32) NOT NULL,
+  "description" varchar(64) NOT NULL DEFAULT '',
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."permissions"."name" IS '權限名稱';
+COMMENT ON COLUMN "public"."permissions"."description" IS '權限說明';
+COMMENT ON COLUMN "public"."permissions"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."permissions"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "permissions_name_uidx" ON "public"."permissions" USING btree (
+  "name"
+);
+
+CREATE TABLE IF NOT EXISTS "public"."role_permissions" (
+  "id" SERIAL PRIMARY KEY,
+  "role_id" int4 NOT NULL,
+  "permission_id" int4 NOT NULL,
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMENT ON COLUMN "public"."role_permissions"."role_id" IS '角色ID';
+COMMENT ON COLUMN "public"."role_permissions"."permission_id" IS '權限ID';
+COMMENT ON COLUMN "public"."role_permissions"."created_at" IS '新增時間';
+
+CREATE UNIQUE INDEX "role_permissions_role_id_permission_id_uidx" ON "public"."role_permissions" USING btree (
+  "role_id",
+  "permission_id"
+);
+
+ALTER TABLE "public"."role_permissions" ADD CONSTRAINT "roles_role_id_foreign_role_permission" FOREIGN KEY ("role_id") REFERENCES "public"."roles" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+ALTER TABLE "public"."role_permissions" ADD CONSTRAINT "permissions_permission_id_foreign_role_permission" FOREIGN KEY ("permission_id") REFERENCES "public"."permissions" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+INSERT INTO "public"."roles" ("name", "description") VALUES
+  ('user', '一般用戶'),
+  ('admin', '管理員');
+
+INSERT INTO "public"."permissions" ("name", "description") VALUES
+  ('account', '管理自己的帳號'),
+  ('tasks:read', '讀取分類與任務'),
+  ('tasks:write', '新增、修改、刪除分類與任務'),
+  ('users:read', '查詢所有用戶'),
+  ('users:write', '停用用戶、驗證信箱');
+
+INSERT INTO "public"."role_permissions" ("role_id", "permission_id")
+SELECT "r"."id", "p"."id" FROM "public"."roles" "r", "public"."permissions" "p"
+WHERE "r"."name" = 'admin' OR "p"."name" IN ('account', 'tasks:read', 'tasks:write');
+
+-- Every user must hold a known role
+ALTER TABLE "public"."users" ADD CONSTRAINT "roles_name_foreign_user" FOREIGN KEY ("role") REFERENCES "public"."roles" ("name") ON DELETE NO ACTION ON UPDATE CASCADE;
\ No newline at end of file
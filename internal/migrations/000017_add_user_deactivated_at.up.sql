@@ -0,0 +1,3 @@
+ALTER TABLE "public"."users" ADD COLUMN "deactivated_at" timestamptz(6);
+
+COMMENT ON COLUMN "public"."users"."deactivated_at" IS '使用者自行停用時間，管理員停用時為空';
\ No newline at end of file
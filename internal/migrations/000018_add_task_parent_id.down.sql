@@ -0,0 +1,5 @@
+ALTER TABLE "public"."tasks" DROP CONSTRAINT IF EXISTS "tasks_parent_id_foreign";
+
+DROP INDEX IF EXISTS "tasks_parent_id_idx";
+
+ALTER TABLE "public"."tasks" DROP COLUMN IF EXISTS "parent_id";
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE "public"."tasks" ADD COLUMN "parent_id" int4 DEFAULT NULL;
+
+COMMENT ON COLUMN "public"."tasks"."parent_id" IS '父任務 ID，頂層任務為空';
+
+CREATE INDEX "tasks_parent_id_idx" ON "public"."tasks" USING btree (
+  "parent_id"
+);
+
+ALTER TABLE "public"."tasks" ADD CONSTRAINT "tasks_parent_id_foreign" FOREIGN KEY ("parent_id") REFERENCES "public"."tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
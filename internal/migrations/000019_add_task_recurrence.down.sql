@@ -0,0 +1,25 @@
+DROP INDEX IF EXISTS "user_id_category_id_title_uidx";
+
+-- Completed tasks may share the title of another task since the index only covered open ones,
+-- every task but the open or else the latest one of a title gets its ID appended to the title.
+UPDATE "public"."tasks" AS "t"
+SET "title" = left("t"."title", 100 - length(' #' || "t"."id")) || ' #' || "t"."id"
+FROM (
+  SELECT "id", row_number() OVER (
+    PARTITION BY "user_id", "category_id", "title"
+    ORDER BY "is_complete" IS TRUE, "id" DESC
+  ) AS "rn"
+  FROM "public"."tasks"
+) AS "d"
+WHERE "t"."id" = "d"."id" AND "d"."rn" > 1;
+
+CREATE UNIQUE INDEX "user_id_category_id_title_uidx" ON "public"."tasks" USING btree (
+  "user_id",
+  "category_id",
+  "title"
+);
+
+ALTER TABLE "public"."tasks" DROP COLUMN IF EXISTS "recurrence_index";
+ALTER TABLE "public"."tasks" DROP COLUMN IF EXISTS "recurrence_rule";
+
+ALTER TABLE "public"."users" DROP COLUMN IF EXISTS "time_zone";
\ No newline at end of file
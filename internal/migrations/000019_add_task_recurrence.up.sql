@@ -0,0 +1,15 @@
+ALTER TABLE "public"."users" ADD COLUMN "time_zone" varchar(64) NOT NULL DEFAULT 'Asia/Taipei';
+COMMENT ON COLUMN "public"."users"."time_zone" IS 'IANA 時區，重複任務依此計算下一次';
+
+ALTER TABLE "public"."tasks" ADD COLUMN "recurrence_rule" text DEFAULT NULL;
+ALTER TABLE "public"."tasks" ADD COLUMN "recurrence_index" int4 NOT NULL DEFAULT 0;
+COMMENT ON COLUMN "public"."tasks"."recurrence_rule" IS '重複規則 (RRULE)，非重複任務為空';
+COMMENT ON COLUMN "public"."tasks"."recurrence_index" IS '第幾次重複，從 1 開始，非重複任務為 0';
+
+DROP INDEX IF EXISTS "user_id_category_id_title_uidx";
+
+CREATE UNIQUE INDEX "user_id_category_id_title_uidx" ON "public"."tasks" USING btree (
+  "user_id",
+  "category_id",
+  "title"
+) WHERE "is_complete" IS NOT TRUE;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE "public"."task_reminders" DROP CONSTRAINT IF EXISTS "tasks_task_id_foreign_task_reminder";
+
+DROP INDEX IF EXISTS "task_reminders_task_id_offset_minutes_uidx";
+DROP TABLE IF EXISTS "public"."task_reminders";
\ No newline at end of file
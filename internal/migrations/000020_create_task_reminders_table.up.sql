@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS "public"."task_reminders" (
+  "id" SERIAL PRIMARY KEY,
+  "task_id" int4 NOT NULL,
+  "offset_minutes" int4 NOT NULL,
+  "remind_at" timestamptz(6) NOT NULL,
+  "sent_at" timestamptz(6),
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."task_reminders"."task_id" IS '任務 ID';
+COMMENT ON COLUMN "public"."task_reminders"."offset_minutes" IS '提前幾分鐘提醒';
+COMMENT ON COLUMN "public"."task_reminders"."remind_at" IS '提醒時間';
+COMMENT ON COLUMN "public"."task_reminders"."sent_at" IS '寄出時間';
+COMMENT ON COLUMN "public"."task_reminders"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."task_reminders"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "task_reminders_task_id_offset_minutes_uidx" ON "public"."task_reminders" USING btree (
+  "task_id",
+  "offset_minutes"
+);
+
+ALTER TABLE "public"."task_reminders" ADD CONSTRAINT "tasks_task_id_foreign_task_reminder" FOREIGN KEY ("task_id") REFERENCES "public"."tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1,10 @@
+ALTER TABLE "public"."task_tags" DROP CONSTRAINT IF EXISTS "tasks_task_id_foreign_task_tag";
+ALTER TABLE "public"."task_tags" DROP CONSTRAINT IF EXISTS "tags_tag_id_foreign_task_tag";
+
+DROP INDEX IF EXISTS "task_tags_tag_id_idx";
+DROP TABLE IF EXISTS "public"."task_tags";
+
+ALTER TABLE "public"."tags" DROP CONSTRAINT IF EXISTS "users_user_id_foreign_tag";
+
+DROP INDEX IF EXISTS "tags_user_id_name_uidx";
+DROP TABLE IF EXISTS "public"."tags";
\ No newline at end of file
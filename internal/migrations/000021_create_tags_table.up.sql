@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS "public"."tags" (
+  "id" SERIAL PRIMARY KEY,
+  "user_id" int4 NOT NULL,
+  "name" varchar(32) NOT NULL,
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  "updated_at" timestamptz(6)
+);
+
+COMMENT ON COLUMN "public"."tags"."user_id" IS '使用者 ID';
+COMMENT ON COLUMN "public"."tags"."name" IS '標籤名稱';
+COMMENT ON COLUMN "public"."tags"."created_at" IS '新增時間';
+COMMENT ON COLUMN "public"."tags"."updated_at" IS '更新時間';
+
+CREATE UNIQUE INDEX "tags_user_id_name_uidx" ON "public"."tags" USING btree (
+  "user_id",
+  "name"
+);
+
+ALTER TABLE "public"."tags" ADD CONSTRAINT "users_user_id_foreign_tag" FOREIGN KEY ("user_id") REFERENCES "public"."users" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
+
+CREATE TABLE IF NOT EXISTS "public"."task_tags" (
+  "task_id" int4 NOT NULL,
+  "tag_id" int4 NOT NULL,
+  "created_at" timestamptz(6) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY ("task_id", "tag_id")
+);
+
+COMMENT ON COLUMN "public"."task_tags"."task_id" IS '任務 ID';
+COMMENT ON COLUMN "public"."task_tags"."tag_id" IS '標籤 ID';
+COMMENT ON COLUMN "public"."task_tags"."created_at" IS '新增時間';
+
+CREATE INDEX "task_tags_tag_id_idx" ON "public"."task_tags" USING btree (
+  "tag_id"
+);
+
+ALTER TABLE "public"."task_tags"
+  ADD CONSTRAINT "tasks_task_id_foreign_task_tag" FOREIGN KEY ("task_id") REFERENCES "public"."tasks" ("id") ON DELETE CASCADE ON UPDATE NO ACTION,
+  ADD CONSTRAINT "tags_tag_id_foreign_task_tag" FOREIGN KEY ("tag_id") REFERENCES "public"."tags" ("id") ON DELETE CASCADE ON UPDATE NO ACTION;
\ No newline at end of file
@@ -0,0 +1,9 @@
+ALTER TABLE "public"."tasks" ADD COLUMN "search_vector" tsvector GENERATED ALWAYS AS (
+  setweight(to_tsvector('simple', coalesce("title", '')), 'A') ||
+  setweight(to_tsvector('simple', coalesce("note", '')), 'B')
+) STORED;
+COMMENT ON COLUMN "public"."tasks"."search_vector" IS '全文檢索向量，由標題 (權重 A) 與備註 (權重 B) 產生';
+
+CREATE INDEX "tasks_search_vector_idx" ON "public"."tasks" USING gin (
+  "search_vector"
+);
\ No newline at end of file
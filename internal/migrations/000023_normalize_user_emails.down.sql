@@ -0,0 +1,2 @@
+-- The original case of the lowercased emails is not kept
+DROP INDEX IF EXISTS "lower_email_uidx";
\ No newline at end of file
@@ -0,0 +1,22 @@
+-- Emails are matched case-insensitively and stored in lowercase from now on,
+-- users sharing an email in different cases have to be resolved by hand before migrating.
+DO $$
+DECLARE
+  "duplicates" text;
+BEGIN
+  SELECT string_agg(DISTINCT lower("u"."email"), ', ') INTO "duplicates"
+  FROM "public"."users" AS "u"
+  WHERE EXISTS (
+    SELECT 1 FROM "public"."users" AS "o" WHERE "o"."id" <> "u"."id" AND lower("o"."email") = lower("u"."email")
+  );
+
+  IF "duplicates" IS NOT NULL THEN
+    RAISE EXCEPTION 'users share an email in different cases, merge or rename them first: %', "duplicates";
+  END IF;
+END $$;
+
+UPDATE "public"."users" SET "email" = lower("email") WHERE "email" <> lower("email");
+
+CREATE UNIQUE INDEX "lower_email_uidx" ON "public"."users" USING btree (
+  lower("email")
+);
\ No newline at end of file
@@ -328,6 +328,20 @@ func ParseConditionParams(paramsGetter func(string) map[string]string, holder in
 					conInt.CONTAIN = list
 				}
 			}
+			if v, valOK := param["overlap"]; valOK {
+				if len(v) > 0 {
+					list := []int{}
+					for _, v := range strings.Split(v, ",") {
+						if intV, intErr := strconv.Atoi(v); intErr == nil {
+							if !isValidCon {
+								isValidCon = true
+							}
+							list = append(list, intV)
+						}
+					}
+					conInt.OVERLAP = list
+				}
+			}
 			if isValidCon {
 				valueField.Set(reflect.ValueOf(conInt))
 			}
@@ -400,6 +414,10 @@ func ParseConditionParams(paramsGetter func(string) map[string]string, holder in
 			if isValidCon {
 				valueField.Set(reflect.ValueOf(conJSON))
 			}
+		case *condition.TextSearch:
+			if v, valOK := param["match"]; valOK && len(v) > 0 {
+				valueField.Set(reflect.ValueOf(&condition.TextSearch{Match: &v}))
+			}
 		case *condition.String:
 			conString := &condition.String{}
 			isValidCon := false
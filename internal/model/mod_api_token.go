@@ -0,0 +1,149 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	tableNameApiToken string = "api_tokens"
+)
+
+// ApiToken is a personal access token, only the hash of the token is stored
+type ApiToken struct {
+	ID          int            `json:"id"`
+	UserId      int            `json:"user_id"`
+	Name        string         `json:"name"`
+	TokenHash   string         `json:"-"`
+	TokenPrefix string         `json:"token_prefix"`
+	Scopes      pq.StringArray `json:"scopes" gorm:"type:text[]"`
+	IsRevoked   bool           `json:"is_revoked"`
+	LastUsedAt  *time.Time     `json:"-"`
+	ExpiredAt   *time.Time     `json:"-"`
+	CreatedAt   time.Time      `json:"-"`
+	UpdatedAt   time.Time      `json:"-"`
+}
+
+func (u ApiToken) TableName() string {
+	return tableNameApiToken
+}
+
+// IsActive reports whether the token can still be used
+func (u ApiToken) IsActive(now time.Time) bool {
+	return !u.IsRevoked && (u.ExpiredAt == nil || now.Before(*u.ExpiredAt))
+}
+
+type ApiTokenFieldValues struct {
+	ID          field.Int         `db_col:"id"`
+	UserId      field.Int         `db_col:"user_id"`
+	Name        field.String      `db_col:"name"`
+	TokenHash   field.String      `db_col:"token_hash"`
+	TokenPrefix field.String      `db_col:"token_prefix"`
+	Scopes      field.StringArray `db_col:"scopes"`
+	IsRevoked   field.Bool        `db_col:"is_revoked"`
+	LastUsedAt  field.NullTime    `db_col:"last_used_at"`
+	ExpiredAt   field.NullTime    `db_col:"expired_at"`
+	CreatedAt   field.Time        `db_col:"created_at"`
+	UpdatedAt   field.Time        `db_col:"updated_at"`
+}
+
+func (val ApiTokenFieldValues) TableName() string {
+	return tableNameApiToken
+}
+
+type ApiTokenConditions struct {
+	ID        *condition.Int    `db_col:"id"`
+	UserId    *condition.Int    `db_col:"user_id"`
+	TokenHash *condition.String `db_col:"token_hash"`
+	IsRevoked *condition.Bool   `db_col:"is_revoked"`
+}
+
+func (val ApiTokenConditions) TableName() string {
+	return tableNameApiToken
+}
+
+func CreateApiToken(conn DBExecutable, values *ApiTokenFieldValues) (*ApiTokenFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func getApiToken(conn DBExecutable, cons *ApiTokenConditions) *ApiToken {
+	apiToken := &ApiToken{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(apiToken).Error; err != nil {
+		return nil
+	}
+
+	return apiToken
+}
+
+func GetApiTokenByHash(conn DBExecutable, tokenHash string) *ApiToken {
+	cons := &ApiTokenConditions{
+		TokenHash: &condition.String{
+			EQ: &tokenHash,
+		},
+	}
+
+	return getApiToken(conn, cons)
+}
+
+// ListApiTokensByUserID returns the tokens of the user, the newest first
+func ListApiTokensByUserID(conn DBExecutable, userId int) []ApiToken {
+	apiTokens := make([]ApiToken, 0)
+	cons := &ApiTokenConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Order("id DESC").Find(&apiTokens).Error; err != nil {
+		return apiTokens
+	}
+
+	return apiTokens
+}
+
+// RevokeApiToken revokes a token of the user that is still active and reports whether this call revoked it
+func RevokeApiToken(conn DBExecutable, id int, userId int) (bool, error) {
+	isRevoked := false
+	cons := &ApiTokenConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		IsRevoked: &condition.Bool{
+			EQ: &isRevoked,
+		},
+	}
+	values := &ApiTokenFieldValues{
+		IsRevoked: GiveColBool(true),
+		UpdatedAt: GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+func TouchApiToken(conn DBExecutable, id int, usedAt time.Time) error {
+	values := &ApiTokenFieldValues{
+		LastUsedAt: GiveColNullTime(&usedAt),
+	}
+
+	return db.GormDriver(conn).Where(ApiToken{ID: id}).Updates(values).Error
+}
@@ -0,0 +1,86 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameAuditLog string = "audit_logs"
+)
+
+// Actions recorded in the audit log
+const (
+	AuditActionLoginLockout = "login_lockout"
+)
+
+type AuditLog struct {
+	ID        int       `json:"id"`
+	UserId    *int      `json:"user_id"`
+	Action    string    `json:"action"`
+	Subject   string    `json:"subject"`
+	Ip        string    `json:"ip"`
+	Detail    string    `json:"detail"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (u AuditLog) TableName() string {
+	return tableNameAuditLog
+}
+
+type AuditLogFieldValues struct {
+	ID        field.Int     `db_col:"id"`
+	UserId    field.NullInt `db_col:"user_id"`
+	Action    field.String  `db_col:"action"`
+	Subject   field.String  `db_col:"subject"`
+	Ip        field.String  `db_col:"ip"`
+	Detail    field.String  `db_col:"detail"`
+	CreatedAt field.Time    `db_col:"created_at"`
+}
+
+func (val AuditLogFieldValues) TableName() string {
+	return tableNameAuditLog
+}
+
+type AuditLogConditions struct {
+	ID      *condition.Int    `db_col:"id"`
+	UserId  *condition.Int    `db_col:"user_id"`
+	Action  *condition.String `db_col:"action"`
+	Subject *condition.String `db_col:"subject"`
+}
+
+func (val AuditLogConditions) TableName() string {
+	return tableNameAuditLog
+}
+
+func CreateAuditLog(conn DBExecutable, values *AuditLogFieldValues) (*AuditLogFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+// GetLatestAuditLog returns the newest entry of the action about the subject
+func GetLatestAuditLog(conn DBExecutable, action string, subject string) *AuditLog {
+	auditLog := &AuditLog{}
+	cons := &AuditLogConditions{
+		Action: &condition.String{
+			EQ: &action,
+		},
+		Subject: &condition.String{
+			EQ: &subject,
+		},
+	}
+
+	gormConn := db.GormDriver(conn)
+	if err := gormConn.Where(BuildWhereClause(cons)).Order("id DESC").Take(auditLog).Error; err != nil {
+		return nil
+	}
+
+	return auditLog
+}
@@ -17,6 +17,7 @@ const (
 
 type Category struct {
 	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
 	Name      string    `json:"name"`
 	CreatedAt time.Time `json:"-"`
 	UpdatedAt time.Time `json:"-"`
@@ -28,6 +29,7 @@ func (u Category) TableName() string {
 
 type CategoryFieldValues struct {
 	ID        field.Int    `db_col:"id"`
+	UserId    field.Int    `db_col:"user_id"`
 	Name      field.String `db_col:"name"`
 	CreatedAt field.Time   `db_col:"created_at"`
 	UpdatedAt field.Time   `db_col:"updated_at"`
@@ -38,8 +40,9 @@ func (val CategoryFieldValues) TableName() string {
 }
 
 type CategoryConditions struct {
-	ID   *condition.Int    `db_col:"id"`
-	Name *condition.String `db_col:"name"`
+	ID     *condition.Int    `db_col:"id"`
+	UserId *condition.Int    `db_col:"user_id"`
+	Name   *condition.String `db_col:"name"`
 }
 
 func (val CategoryConditions) TableName() string {
@@ -80,8 +83,11 @@ func getCategory(conn DBExecutable, cons *CategoryConditions) *Category {
 	return category
 }
 
-func GetCategoryByName(conn *sql.DB, name string) *Category {
+func GetCategoryByName(conn *sql.DB, userId int, name string) *Category {
 	cons := &CategoryConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
 		Name: &condition.String{
 			EQ: &name,
 		},
@@ -100,6 +106,19 @@ func GetCategoryByID(conn DBExecutable, id int) *Category {
 	return getCategory(conn, cons)
 }
 
+func GetCategoryByIDAndUserID(conn DBExecutable, id int, userId int) *Category {
+	cons := &CategoryConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	return getCategory(conn, cons)
+}
+
 func ListCategory(conn *sql.DB, cons *CategoryConditions, orderBys *CategoryOrderBy, limit *int, offset *int) []Category {
 	categories := make([]Category, 0)
 
@@ -46,9 +46,25 @@ func setDownModCategory() {
 	sqlDBCategory.Close()
 }
 
-func createCategory(name string) (*model.CategoryFieldValues, error) {
+func createCategoryUser() (*model.UserFieldValues, error) {
+	now := time.Now().UTC()
+	userValues := &model.UserFieldValues{
+		Email:           model.GiveColString(util.RandomEmail()),
+		Username:        model.GiveColString(util.RandomString(6)),
+		Password:        model.GiveColString(util.RandomString(8)),
+		Status:          model.GiveColBool(true),
+		CreatedAt:       model.GiveColTime(now),
+		UpdatedAt:       model.GiveColTime(now),
+		IsEmailVerified: model.GiveColBool(true),
+	}
+
+	return model.CreateUser(sqlDBCategory, userValues)
+}
+
+func createCategory(userId int, name string) (*model.CategoryFieldValues, error) {
 	now := time.Now().UTC()
 	categoryValues := &model.CategoryFieldValues{
+		UserId:    model.GiveColInt(userId),
 		Name:      model.GiveColString(name),
 		CreatedAt: model.GiveColTime(now),
 		UpdatedAt: model.GiveColTime(now),
@@ -61,8 +77,11 @@ func TestCreateCategory(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
-		category, err := createCategory(util.RandomString(6))
+		category, err := createCategory(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
 		assert.NotNil(t, category)
 		assert.NotZero(t, category.ID.Val)
@@ -70,12 +89,25 @@ func TestCreateCategory(t *testing.T) {
 
 	t.Run("Failure_DuplicateName", func(t *testing.T) {
 		name := util.RandomString(6)
-		_, err := createCategory(name)
+		_, err := createCategory(user.ID.Val, name)
 		assert.Nil(t, err)
 
-		_, err = createCategory(name)
+		_, err = createCategory(user.ID.Val, name)
 		assert.NotNil(t, err)
-		assert.EqualError(t, err, "pq: duplicate key value violates unique constraint \"name_uidx\"")
+		assert.EqualError(t, err, "pq: duplicate key value violates unique constraint \"user_id_name_uidx\"")
+	})
+
+	t.Run("Success_SameNameOtherUser", func(t *testing.T) {
+		name := util.RandomString(6)
+		_, err := createCategory(user.ID.Val, name)
+		assert.Nil(t, err)
+
+		otherUser, err := createCategoryUser()
+		assert.Nil(t, err)
+
+		category, err := createCategory(otherUser.ID.Val, name)
+		assert.Nil(t, err)
+		assert.Equal(t, otherUser.ID.Val, category.UserId.Val)
 	})
 }
 
@@ -83,18 +115,21 @@ func TestGetCategoryByName(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		name := util.RandomString(6)
-		_, err := createCategory(name)
+		_, err := createCategory(user.ID.Val, name)
 		assert.Nil(t, err)
 
-		category := model.GetCategoryByName(sqlDBCategory, name)
+		category := model.GetCategoryByName(sqlDBCategory, user.ID.Val, name)
 		assert.NotNil(t, category)
 		assert.Equal(t, name, category.Name)
 	})
 
 	t.Run("Failure_Non-ExistentName", func(t *testing.T) {
-		category := model.GetCategoryByName(sqlDBCategory, "nonexistent-category")
+		category := model.GetCategoryByName(sqlDBCategory, user.ID.Val, "nonexistent-category")
 		assert.Nil(t, category)
 	})
 }
@@ -103,9 +138,12 @@ func TestGetCategoryByID(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		name := util.RandomString(6)
-		createCategory, err := createCategory(name)
+		createCategory, err := createCategory(user.ID.Val, name)
 		assert.Nil(t, err)
 
 		category := model.GetCategoryByID(sqlDBCategory, createCategory.ID.Val)
@@ -119,15 +157,44 @@ func TestGetCategoryByID(t *testing.T) {
 	})
 }
 
+func TestGetCategoryByIDAndUserID(t *testing.T) {
+	setUpModCategory()
+	defer setDownModCategory()
+
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
+	createCategory, err := createCategory(user.ID.Val, util.RandomString(6))
+	assert.Nil(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		category := model.GetCategoryByIDAndUserID(sqlDBCategory, createCategory.ID.Val, user.ID.Val)
+		assert.NotNil(t, category)
+		assert.Equal(t, createCategory.ID.Val, category.ID)
+		assert.Equal(t, user.ID.Val, category.UserId)
+	})
+
+	t.Run("Failure_OtherUser", func(t *testing.T) {
+		otherUser, err := createCategoryUser()
+		assert.Nil(t, err)
+
+		category := model.GetCategoryByIDAndUserID(sqlDBCategory, createCategory.ID.Val, otherUser.ID.Val)
+		assert.Nil(t, category)
+	})
+}
+
 func TestListCategory(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		// Create categories for testing
-		_, err := createCategory(util.RandomString(6))
+		_, err := createCategory(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
-		_, err = createCategory(util.RandomString(6))
+		_, err = createCategory(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
 
 		// Get category count for assertions
@@ -169,12 +236,15 @@ func TestGetCategoryCount(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		initialCount, err := model.GetCategoryCount(sqlDBCategory, &model.CategoryConditions{})
 		assert.Nil(t, err)
 
 		name := util.RandomString(6)
-		_, createCategoryErr := createCategory(name)
+		_, createCategoryErr := createCategory(user.ID.Val, name)
 		assert.Nil(t, createCategoryErr)
 
 		newCount, err := model.GetCategoryCount(sqlDBCategory, &model.CategoryConditions{})
@@ -187,9 +257,12 @@ func TestUpdateCategory(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		name := util.RandomString(6)
-		createCategory, err := createCategory(name)
+		createCategory, err := createCategory(user.ID.Val, name)
 		assert.Nil(t, err)
 
 		newName := util.RandomString(6)
@@ -215,9 +288,12 @@ func TestDeleteCategory(t *testing.T) {
 	setUpModCategory()
 	defer setDownModCategory()
 
+	user, userErr := createCategoryUser()
+	assert.Nil(t, userErr)
+
 	t.Run("Success", func(t *testing.T) {
 		name := util.RandomString(6)
-		createCategory, err := createCategory(name)
+		createCategory, err := createCategory(user.ID.Val, name)
 		assert.Nil(t, err)
 
 		deleteErr := model.DeleteCategory(sqlTxCategory, createCategory.ID.Val)
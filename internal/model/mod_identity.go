@@ -0,0 +1,86 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameIdentity string = "identities"
+)
+
+// Identity links an account at an OAuth provider to a user
+type Identity struct {
+	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	Provider  string    `json:"provider"`
+	Subject   string    `json:"-"`
+	Email     string    `json:"email"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (u Identity) TableName() string {
+	return tableNameIdentity
+}
+
+type IdentityFieldValues struct {
+	ID        field.Int    `db_col:"id"`
+	UserId    field.Int    `db_col:"user_id"`
+	Provider  field.String `db_col:"provider"`
+	Subject   field.String `db_col:"subject"`
+	Email     field.String `db_col:"email"`
+	CreatedAt field.Time   `db_col:"created_at"`
+	UpdatedAt field.Time   `db_col:"updated_at"`
+}
+
+func (val IdentityFieldValues) TableName() string {
+	return tableNameIdentity
+}
+
+type IdentityConditions struct {
+	ID       *condition.Int    `db_col:"id"`
+	UserId   *condition.Int    `db_col:"user_id"`
+	Provider *condition.String `db_col:"provider"`
+	Subject  *condition.String `db_col:"subject"`
+}
+
+func (val IdentityConditions) TableName() string {
+	return tableNameIdentity
+}
+
+func CreateIdentity(conn DBExecutable, values *IdentityFieldValues) (*IdentityFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func getIdentity(conn DBExecutable, cons *IdentityConditions) *Identity {
+	identity := &Identity{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(identity).Error; err != nil {
+		return nil
+	}
+
+	return identity
+}
+
+func GetIdentityBySubject(conn DBExecutable, provider string, subject string) *Identity {
+	cons := &IdentityConditions{
+		Provider: &condition.String{
+			EQ: &provider,
+		},
+		Subject: &condition.String{
+			EQ: &subject,
+		},
+	}
+
+	return getIdentity(conn, cons)
+}
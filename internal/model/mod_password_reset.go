@@ -0,0 +1,154 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNamePasswordReset string = "password_resets"
+)
+
+type PasswordReset struct {
+	ID             int       `json:"id"`
+	UserId         int       `json:"user_id"`
+	Email          string    `json:"email"`
+	SecretCodeHash string    `json:"-"`
+	IsUsed         bool      `json:"is_used"`
+	ExpiredAt      time.Time `json:"-"`
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
+}
+
+func (u PasswordReset) TableName() string {
+	return tableNamePasswordReset
+}
+
+type PasswordResetFieldValues struct {
+	ID             field.Int    `db_col:"id"`
+	UserId         field.Int    `db_col:"user_id"`
+	Email          field.String `db_col:"email"`
+	SecretCodeHash field.String `db_col:"secret_code_hash"`
+	IsUsed         field.Bool   `db_col:"is_used"`
+	ExpiredAt      field.Time   `db_col:"expired_at"`
+	CreatedAt      field.Time   `db_col:"created_at"`
+	UpdatedAt      field.Time   `db_col:"updated_at"`
+}
+
+func (val PasswordResetFieldValues) TableName() string {
+	return tableNamePasswordReset
+}
+
+type PasswordResetConditions struct {
+	ID        *condition.Int    `db_col:"id"`
+	UserId    *condition.Int    `db_col:"user_id"`
+	Email     *condition.String `db_col:"email"`
+	IsUsed    *condition.Bool   `db_col:"is_used"`
+	ExpiredAt *condition.Time   `db_col:"expired_at"`
+	CreatedAt *condition.Time   `db_col:"created_at"`
+}
+
+func (val PasswordResetConditions) TableName() string {
+	return tableNamePasswordReset
+}
+
+func CreatePasswordReset(conn DBExecutable, values *PasswordResetFieldValues) (*PasswordResetFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func getPasswordReset(conn DBExecutable, cons *PasswordResetConditions) *PasswordReset {
+	passwordReset := &PasswordReset{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(passwordReset).Error; err != nil {
+		return nil
+	}
+
+	return passwordReset
+}
+
+func GetPasswordResetByID(conn DBExecutable, id int, isUsed bool, expiredAt *time.Time) *PasswordReset {
+	cons := &PasswordResetConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+		ExpiredAt: &condition.Time{
+			GTE: expiredAt,
+		},
+	}
+
+	return getPasswordReset(conn, cons)
+}
+
+// UsePasswordReset marks the code as used and reports whether this call did it,
+// so that a code can never be redeemed twice even by concurrent requests.
+func UsePasswordReset(conn DBExecutable, id int) (bool, error) {
+	isUsed := false
+	cons := &PasswordResetConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+	}
+	values := &PasswordResetFieldValues{
+		IsUsed:    GiveColBool(true),
+		UpdatedAt: GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetPasswordResetSince returns a password reset of the address created at or after the given time
+func GetPasswordResetSince(conn DBExecutable, email string, since time.Time) *PasswordReset {
+	cons := &PasswordResetConditions{
+		Email: &condition.String{
+			EQ: &email,
+		},
+		CreatedAt: &condition.Time{
+			GTE: &since,
+		},
+	}
+
+	return getPasswordReset(conn, cons)
+}
+
+// ExpirePasswordResetsByUserID expires every unused code of the user so that only the newest link works
+func ExpirePasswordResetsByUserID(conn DBExecutable, userId int) error {
+	now := time.Now().UTC()
+	isUsed := false
+	cons := &PasswordResetConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+		ExpiredAt: &condition.Time{
+			GT: &now,
+		},
+	}
+	values := &PasswordResetFieldValues{
+		ExpiredAt: GiveColTime(now),
+		UpdatedAt: GiveColTime(now),
+	}
+
+	return db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values).Error
+}
@@ -0,0 +1,108 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameRecoveryCode string = "recovery_codes"
+)
+
+type RecoveryCode struct {
+	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	CodeHash  string    `json:"-"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (u RecoveryCode) TableName() string {
+	return tableNameRecoveryCode
+}
+
+type RecoveryCodeFieldValues struct {
+	ID        field.Int    `db_col:"id"`
+	UserId    field.Int    `db_col:"user_id"`
+	CodeHash  field.String `db_col:"code_hash"`
+	IsUsed    field.Bool   `db_col:"is_used"`
+	CreatedAt field.Time   `db_col:"created_at"`
+	UpdatedAt field.Time   `db_col:"updated_at"`
+}
+
+func (val RecoveryCodeFieldValues) TableName() string {
+	return tableNameRecoveryCode
+}
+
+type RecoveryCodeConditions struct {
+	ID       *condition.Int    `db_col:"id"`
+	UserId   *condition.Int    `db_col:"user_id"`
+	CodeHash *condition.String `db_col:"code_hash"`
+	IsUsed   *condition.Bool   `db_col:"is_used"`
+}
+
+func (val RecoveryCodeConditions) TableName() string {
+	return tableNameRecoveryCode
+}
+
+// ReplaceRecoveryCodes drops every recovery code of the user and stores the new hashes
+func ReplaceRecoveryCodes(conn DBExecutable, userId int, codeHashes []string) error {
+	if err := DeleteRecoveryCodesByUserID(conn, userId); err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	values := make([]*RecoveryCodeFieldValues, 0, len(codeHashes))
+	for _, codeHash := range codeHashes {
+		values = append(values, &RecoveryCodeFieldValues{
+			UserId:    GiveColInt(userId),
+			CodeHash:  GiveColString(codeHash),
+			IsUsed:    GiveColBool(false),
+			CreatedAt: GiveColTime(now),
+			UpdatedAt: GiveColTime(now),
+		})
+	}
+
+	return db.GormDriver(conn).Create(values).Error
+}
+
+func DeleteRecoveryCodesByUserID(conn DBExecutable, userId int) error {
+	cons := &RecoveryCodeConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	return db.GormDriver(conn).Where(BuildWhereClause(cons)).Delete(&RecoveryCode{}).Error
+}
+
+// UseRecoveryCode marks the unused code of the user as used and reports whether this call did it,
+// so that a code can never be redeemed twice even by concurrent requests.
+func UseRecoveryCode(conn DBExecutable, userId int, codeHash string) (bool, error) {
+	isUsed := false
+	cons := &RecoveryCodeConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		CodeHash: &condition.String{
+			EQ: &codeHash,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+	}
+	values := &RecoveryCodeFieldValues{
+		IsUsed:    GiveColBool(true),
+		UpdatedAt: GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameRefreshToken string = "refresh_tokens"
+)
+
+type RefreshToken struct {
+	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	Jti       string    `json:"-"`
+	AccessJti string    `json:"-"`
+	IsRevoked bool      `json:"is_revoked"`
+	ExpiredAt time.Time `json:"-"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (u RefreshToken) TableName() string {
+	return tableNameRefreshToken
+}
+
+type RefreshTokenFieldValues struct {
+	ID        field.Int    `db_col:"id"`
+	UserId    field.Int    `db_col:"user_id"`
+	Jti       field.String `db_col:"jti"`
+	AccessJti field.String `db_col:"access_jti"`
+	IsRevoked field.Bool   `db_col:"is_revoked"`
+	ExpiredAt field.Time   `db_col:"expired_at"`
+	CreatedAt field.Time   `db_col:"created_at"`
+	UpdatedAt field.Time   `db_col:"updated_at"`
+}
+
+func (val RefreshTokenFieldValues) TableName() string {
+	return tableNameRefreshToken
+}
+
+type RefreshTokenConditions struct {
+	ID        *condition.Int    `db_col:"id"`
+	UserId    *condition.Int    `db_col:"user_id"`
+	Jti       *condition.String `db_col:"jti"`
+	AccessJti *condition.String `db_col:"access_jti"`
+	IsRevoked *condition.Bool   `db_col:"is_revoked"`
+}
+
+func (val RefreshTokenConditions) TableName() string {
+	return tableNameRefreshToken
+}
+
+func CreateRefreshToken(conn DBExecutable, values *RefreshTokenFieldValues) (*RefreshTokenFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func getRefreshToken(conn DBExecutable, cons *RefreshTokenConditions) *RefreshToken {
+	refreshToken := &RefreshToken{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(refreshToken).Error; err != nil {
+		return nil
+	}
+
+	return refreshToken
+}
+
+func GetRefreshTokenByJti(conn DBExecutable, jti string) *RefreshToken {
+	cons := &RefreshTokenConditions{
+		Jti: &condition.String{
+			EQ: &jti,
+		},
+	}
+
+	return getRefreshToken(conn, cons)
+}
+
+// IsAccessTokenRevoked reports whether the session that issued the access token has been revoked,
+// the error is returned as is so that the caller can deny the token when the lookup fails.
+func IsAccessTokenRevoked(conn DBExecutable, accessJti string) (bool, error) {
+	var count int64
+	isRevoked := true
+	cons := &RefreshTokenConditions{
+		AccessJti: &condition.String{
+			EQ: &accessJti,
+		},
+		IsRevoked: &condition.Bool{
+			EQ: &isRevoked,
+		},
+	}
+
+	if err := db.GormDriver(conn).Model(RefreshToken{}).Where(BuildWhereClause(cons)).Count(&count).Error; err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func revokeRefreshTokens(conn DBExecutable, cons *RefreshTokenConditions) (int64, error) {
+	values := &RefreshTokenFieldValues{
+		IsRevoked: GiveColBool(true),
+		UpdatedAt: GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+
+	return result.RowsAffected, result.Error
+}
+
+// RevokeRefreshTokenByID revokes a token that is still active and reports whether this call revoked it,
+// so that two concurrent rotations of the same token cannot both succeed.
+func RevokeRefreshTokenByID(conn DBExecutable, id int) (bool, error) {
+	isRevoked := false
+	cons := &RefreshTokenConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		IsRevoked: &condition.Bool{
+			EQ: &isRevoked,
+		},
+	}
+
+	affected, err := revokeRefreshTokens(conn, cons)
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func RevokeRefreshTokenByAccessJti(conn DBExecutable, userId int, accessJti string) error {
+	cons := &RefreshTokenConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		AccessJti: &condition.String{
+			EQ: &accessJti,
+		},
+	}
+
+	_, err := revokeRefreshTokens(conn, cons)
+	return err
+}
+
+func RevokeRefreshTokensByUserID(conn DBExecutable, userId int) error {
+	cons := &RefreshTokenConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	_, err := revokeRefreshTokens(conn, cons)
+	return err
+}
+
+// RevokeOtherRefreshTokensByUserID revokes every session of the user except the one that issued the access token
+func RevokeOtherRefreshTokensByUserID(conn DBExecutable, userId int, accessJti string) error {
+	cons := &RefreshTokenConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		AccessJti: &condition.String{
+			NEQ: &accessJti,
+		},
+	}
+
+	_, err := revokeRefreshTokens(conn, cons)
+	return err
+}
@@ -0,0 +1,137 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"time"
+)
+
+const (
+	tableNameRole           string = "roles"
+	tableNamePermission     string = "permissions"
+	tableNameRolePermission string = "role_permissions"
+)
+
+type Role struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+func (u Role) TableName() string {
+	return tableNameRole
+}
+
+type RoleConditions struct {
+	ID   *condition.Int    `db_col:"id"`
+	Name *condition.String `db_col:"name"`
+}
+
+func (val RoleConditions) TableName() string {
+	return tableNameRole
+}
+
+type Permission struct {
+	ID          int       `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"-"`
+	UpdatedAt   time.Time `json:"-"`
+}
+
+func (u Permission) TableName() string {
+	return tableNamePermission
+}
+
+type PermissionConditions struct {
+	ID   *condition.Int    `db_col:"id"`
+	Name *condition.String `db_col:"name"`
+}
+
+func (val PermissionConditions) TableName() string {
+	return tableNamePermission
+}
+
+// RolePermission grants a permission to a role
+type RolePermission struct {
+	ID           int       `json:"id"`
+	RoleId       int       `json:"role_id"`
+	PermissionId int       `json:"permission_id"`
+	CreatedAt    time.Time `json:"-"`
+}
+
+func (u RolePermission) TableName() string {
+	return tableNameRolePermission
+}
+
+type RolePermissionConditions struct {
+	RoleId       *condition.Int `db_col:"role_id"`
+	PermissionId *condition.Int `db_col:"permission_id"`
+}
+
+func (val RolePermissionConditions) TableName() string {
+	return tableNameRolePermission
+}
+
+func GetRoleByName(conn DBExecutable, name string) *Role {
+	role := &Role{}
+	cons := &RoleConditions{
+		Name: &condition.String{
+			EQ: &name,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Take(role).Error; err != nil {
+		return nil
+	}
+
+	return role
+}
+
+// ListPermissionNamesByRole returns the names of the permissions granted to the role, none for an unknown role
+func ListPermissionNamesByRole(conn DBExecutable, roleName string) ([]string, error) {
+	names := make([]string, 0)
+
+	role := GetRoleByName(conn, roleName)
+	if role == nil {
+		return names, nil
+	}
+
+	gormConn := db.GormDriver(conn)
+
+	rolePermissions := make([]RolePermission, 0)
+	rpCons := &RolePermissionConditions{
+		RoleId: &condition.Int{
+			EQ: &role.ID,
+		},
+	}
+	if err := gormConn.Where(BuildWhereClause(rpCons)).Find(&rolePermissions).Error; err != nil {
+		return nil, err
+	}
+	if len(rolePermissions) == 0 {
+		return names, nil
+	}
+
+	permissionIds := make([]int, 0, len(rolePermissions))
+	for _, rp := range rolePermissions {
+		permissionIds = append(permissionIds, rp.PermissionId)
+	}
+
+	permissions := make([]Permission, 0)
+	pCons := &PermissionConditions{
+		ID: &condition.Int{
+			IN: permissionIds,
+		},
+	}
+	if err := gormConn.Where(BuildWhereClause(pCons)).Order("name").Find(&permissions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, p := range permissions {
+		names = append(names, p.Name)
+	}
+
+	return names, nil
+}
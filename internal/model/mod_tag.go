@@ -0,0 +1,272 @@
+package model
+
+import (
+	"database/sql"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/builder"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameTag     string = "tags"
+	tableNameTaskTag string = "task_tags"
+)
+
+// Tag is a free-form label of a user, a task may have any number of them
+type Tag struct {
+	ID        int       `json:"id"`
+	UserId    int       `json:"user_id"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"-"`
+	UpdatedAt time.Time `json:"-"`
+}
+
+func (u Tag) TableName() string {
+	return tableNameTag
+}
+
+type TagFieldValues struct {
+	ID        field.Int    `db_col:"id"`
+	UserId    field.Int    `db_col:"user_id"`
+	Name      field.String `db_col:"name"`
+	CreatedAt field.Time   `db_col:"created_at"`
+	UpdatedAt field.Time   `db_col:"updated_at"`
+}
+
+func (val TagFieldValues) TableName() string {
+	return tableNameTag
+}
+
+type TagConditions struct {
+	ID     *condition.Int    `db_col:"id"`
+	UserId *condition.Int    `db_col:"user_id"`
+	Name   *condition.String `db_col:"name"`
+}
+
+func (val TagConditions) TableName() string {
+	return tableNameTag
+}
+
+type TagOrderBy struct {
+	ID   *builder.OrderBy `db_col:"id"`
+	Name *builder.OrderBy `db_col:"name"`
+}
+
+func (ob TagOrderBy) TableName() string {
+	return tableNameTag
+}
+
+func (ob *TagOrderBy) Parse(params map[string]bool) {
+	ParseOrderByParams(params, ob)
+}
+
+// TaskTag links a task to one of the tags of its user
+type TaskTag struct {
+	TaskId    int       `json:"task_id"`
+	TagId     int       `json:"tag_id"`
+	CreatedAt time.Time `json:"-"`
+}
+
+func (u TaskTag) TableName() string {
+	return tableNameTaskTag
+}
+
+type TaskTagConditions struct {
+	TaskId *condition.Int `db_col:"task_id"`
+	TagId  *condition.Int `db_col:"tag_id"`
+}
+
+func (val TaskTagConditions) TableName() string {
+	return tableNameTaskTag
+}
+
+func CreateTag(conn *sql.DB, values *TagFieldValues) (*TagFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func getTag(conn DBExecutable, cons *TagConditions) *Tag {
+	tag := &Tag{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(tag).Error; err != nil {
+		return nil
+	}
+
+	return tag
+}
+
+func GetTagByName(conn DBExecutable, userId int, name string) *Tag {
+	cons := &TagConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		Name: &condition.String{
+			EQ: &name,
+		},
+	}
+
+	return getTag(conn, cons)
+}
+
+func GetTagByIDAndUserID(conn DBExecutable, id int, userId int) *Tag {
+	cons := &TagConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	return getTag(conn, cons)
+}
+
+// ListTagsByIDs returns the tags of the user among the given IDs, a missing one is not the user's
+func ListTagsByIDs(conn DBExecutable, userId int, ids []int) []Tag {
+	tags := make([]Tag, 0)
+	if len(ids) == 0 {
+		return tags
+	}
+
+	cons := &TagConditions{
+		ID: &condition.Int{
+			IN: ids,
+		},
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Order("name").Find(&tags).Error; err != nil {
+		return tags
+	}
+
+	return tags
+}
+
+func ListTag(conn *sql.DB, cons *TagConditions, orderBys *TagOrderBy, limit *int, offset *int) []Tag {
+	tags := make([]Tag, 0)
+
+	stmt := db.GormDriver(conn).Model(Tag{})
+
+	// conditions
+	where := BuildWhereClause(cons)
+	if len(where.Exprs) > 0 {
+		stmt = stmt.Where(where)
+	}
+
+	// sorting
+	orderBy := BuildOrderByClause(orderBys)
+	if len(orderBy.Columns) > 0 {
+		stmt = stmt.Clauses(orderBy)
+	}
+
+	if limit != nil {
+		stmt = stmt.Limit(*limit)
+	}
+
+	if offset != nil {
+		stmt = stmt.Offset(*offset)
+	}
+
+	if err := stmt.Find(&tags).Error; err != nil {
+		return tags
+	}
+
+	return tags
+}
+
+func GetTagCount(conn *sql.DB, cons *TagConditions) (int32, error) {
+	var count int64
+
+	stmt := db.GormDriver(conn).Model(Tag{})
+
+	where := BuildWhereClause(cons)
+	if len(where.Exprs) > 0 {
+		stmt = stmt.Where(where)
+	}
+
+	if err := stmt.Count(&count).Error; err != nil {
+		return 0, err
+	}
+
+	return int32(count), nil
+}
+
+func UpdateTag(conn *sql.Tx, id int, values *TagFieldValues) error {
+	return db.GormDriver(conn).Where(Tag{ID: id}).Updates(values).Error
+}
+
+func DeleteTag(conn DBExecutable, id int) error {
+	return db.GormDriver(conn).Delete(&Tag{}, id).Error
+}
+
+// ReplaceTaskTags replaces the tags of the task with the given ones, an empty list removes them all
+func ReplaceTaskTags(conn DBExecutable, taskId int, tagIds []int) error {
+	gormConn := db.GormDriver(conn)
+	cons := &TaskTagConditions{
+		TaskId: &condition.Int{
+			EQ: &taskId,
+		},
+	}
+
+	if err := gormConn.Where(BuildWhereClause(cons)).Delete(&TaskTag{}).Error; err != nil {
+		return err
+	}
+
+	if len(tagIds) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	taskTags := make([]TaskTag, 0, len(tagIds))
+	for _, tagId := range tagIds {
+		taskTags = append(taskTags, TaskTag{TaskId: taskId, TagId: tagId, CreatedAt: now})
+	}
+
+	return gormConn.Create(&taskTags).Error
+}
+
+// ListTagsByTaskIDs returns the tags of the tasks by task ID, ordered by name
+func ListTagsByTaskIDs(conn DBExecutable, taskIds []int) map[int][]Tag {
+	tagsByTask := make(map[int][]Tag)
+	if len(taskIds) == 0 {
+		return tagsByTask
+	}
+
+	type taskTagRow struct {
+		TaskId int
+		Tag
+	}
+
+	rows := make([]taskTagRow, 0)
+	cons := &TaskTagConditions{
+		TaskId: &condition.Int{
+			IN: taskIds,
+		},
+	}
+
+	err := db.GormDriver(conn).Model(TaskTag{}).
+		Select(`"task_tags"."task_id", "tags".*`).
+		Joins(`INNER JOIN "public"."tags" ON "tags"."id" = "task_tags"."tag_id"`).
+		Where(BuildWhereClause(cons)).
+		Order(`"task_tags"."task_id", "tags"."name"`).
+		Scan(&rows).Error
+	if err != nil {
+		return tagsByTask
+	}
+
+	for _, row := range rows {
+		tagsByTask[row.TaskId] = append(tagsByTask[row.TaskId], row.Tag)
+	}
+
+	return tagsByTask
+}
@@ -8,6 +8,7 @@ import (
 	"go-todolist-grpc/internal/pkg/db/field"
 	"time"
 
+	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
 
@@ -26,6 +27,9 @@ type Task struct {
 	IsSpecifyTime   bool      `json:"is_specify_time"`
 	Priority        int       `json:"priority"`
 	IsComplete      bool      `json:"is_complete"`
+	ParentId        *int      `json:"parent_id"`
+	RecurrenceRule  *string   `json:"recurrence_rule"`
+	RecurrenceIndex int       `json:"recurrence_index"`
 	CreatedAt       time.Time `json:"created_at"`
 	UpdatedAt       time.Time `json:"updated_at"`
 }
@@ -35,18 +39,21 @@ func (u Task) TableName() string {
 }
 
 type TaskFieldValues struct {
-	ID              field.Int      `db_col:"id"`
-	UserId          field.Int      `db_col:"user_id"`
-	CategoryId      field.Int      `db_col:"category_id"`
-	Title           field.String   `db_col:"title"`
-	Note            field.String   `db_col:"note"`
-	Url             field.String   `db_col:"url"`
-	SpecifyDatetime field.NullTime `db_col:"specify_datetime"`
-	IsSpecifyTime   field.Bool     `db_col:"is_specify_time"`
-	Priority        field.Int      `db_col:"priority"`
-	IsComplete      field.Bool     `db_col:"is_complete"`
-	CreatedAt       field.Time     `db_col:"created_at"`
-	UpdatedAt       field.Time     `db_col:"updated_at"`
+	ID              field.Int        `db_col:"id"`
+	UserId          field.Int        `db_col:"user_id"`
+	CategoryId      field.Int        `db_col:"category_id"`
+	Title           field.String     `db_col:"title"`
+	Note            field.String     `db_col:"note"`
+	Url             field.String     `db_col:"url"`
+	SpecifyDatetime field.NullTime   `db_col:"specify_datetime"`
+	IsSpecifyTime   field.Bool       `db_col:"is_specify_time"`
+	Priority        field.Int        `db_col:"priority"`
+	IsComplete      field.Bool       `db_col:"is_complete"`
+	ParentId        field.NullInt    `db_col:"parent_id"`
+	RecurrenceRule  field.NullString `db_col:"recurrence_rule"`
+	RecurrenceIndex field.Int        `db_col:"recurrence_index"`
+	CreatedAt       field.Time       `db_col:"created_at"`
+	UpdatedAt       field.Time       `db_col:"updated_at"`
 }
 
 func (val TaskFieldValues) TableName() string {
@@ -61,6 +68,14 @@ type TaskConditions struct {
 	IsSpecifyTime *condition.Bool   `db_col:"is_specify_time"`
 	Priority      *condition.Int    `db_col:"priority"`
 	IsComplete    *condition.Bool   `db_col:"is_complete"`
+	ParentId      *condition.Int    `db_col:"parent_id"`
+	// The rule and the position of an occurrence of a recurring task
+	RecurrenceRule  *condition.String `db_col:"recurrence_rule"`
+	RecurrenceIndex *condition.Int    `db_col:"recurrence_index"`
+	// The IDs of the tags of the task, aggregated by joinTaskTagIds
+	TagIds *condition.Int `db_col:"tag_ids" db_alias:"task_tag_ids"`
+	// The full-text search vector over the title and the note
+	SearchVector *condition.TextSearch `db_col:"search_vector"`
 }
 
 func (val TaskConditions) TableName() string {
@@ -81,7 +96,7 @@ func (ob *TaskOrderBy) Parse(params map[string]bool) {
 	ParseOrderByParams(params, ob)
 }
 
-func CreateTask(conn *sql.DB, values *TaskFieldValues) (*TaskFieldValues, error) {
+func CreateTask(conn DBExecutable, values *TaskFieldValues) (*TaskFieldValues, error) {
 	gormConn := db.GormDriver(conn)
 
 	if err := gormConn.Create(values).Error; err != nil {
@@ -102,11 +117,47 @@ func getTask(conn DBExecutable, cons *TaskConditions) *Task {
 	return task
 }
 
-func GetTaskByTitle(conn *sql.DB, title string) *Task {
+// GetOpenTaskByTitle returns the task of the category with the title that is not complete yet,
+// the completed ones do not take the title as the occurrences of a recurring task share it
+func GetOpenTaskByTitle(conn DBExecutable, userId int, categoryId int, title string) *Task {
+	isComplete := false
 	cons := &TaskConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		CategoryId: &condition.Int{
+			EQ: &categoryId,
+		},
 		Title: &condition.String{
 			EQ: &title,
 		},
+		IsComplete: &condition.Bool{
+			EQ: &isComplete,
+		},
+	}
+
+	return getTask(conn, cons)
+}
+
+// GetLaterOccurrence returns an occurrence of the recurring task with the title that comes after the index,
+// whether it is complete or not
+func GetLaterOccurrence(conn DBExecutable, userId int, categoryId int, title string, recurrenceRule string, recurrenceIndex int) *Task {
+	cons := &TaskConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		CategoryId: &condition.Int{
+			EQ: &categoryId,
+		},
+		Title: &condition.String{
+			EQ: &title,
+		},
+		RecurrenceRule: &condition.String{
+			EQ: &recurrenceRule,
+		},
+		RecurrenceIndex: &condition.Int{
+			GT: &recurrenceIndex,
+		},
 	}
 
 	return getTask(conn, cons)
@@ -122,10 +173,35 @@ func GetTaskByID(conn DBExecutable, id int) *Task {
 	return getTask(conn, cons)
 }
 
+func GetTaskByIDAndUserID(conn DBExecutable, id int, userId int) *Task {
+	cons := &TaskConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+	}
+
+	return getTask(conn, cons)
+}
+
+// joinTaskTagIds joins the array of the tag IDs of every task as "task_tag_ids"."tag_ids" when the tags are filtered on,
+// a task without tags is left out as it matches no tag.
+func joinTaskTagIds(stmt *gorm.DB, cons *TaskConditions) *gorm.DB {
+	if cons == nil || cons.TagIds == nil {
+		return stmt
+	}
+
+	return stmt.Select(`"tasks".*`).
+		Joins(`INNER JOIN (SELECT "task_id", array_agg("tag_id") AS "tag_ids" FROM "public"."task_tags" GROUP BY "task_id") AS "task_tag_ids" ON "task_tag_ids"."task_id" = "tasks"."id"`)
+}
+
 func ListTask(conn *sql.DB, cons *TaskConditions, orderBys *TaskOrderBy, limit *int, offset *int) []Task {
 	tasks := make([]Task, 0)
 
 	stmt := db.GormDriver(conn).Model(Task{}).Preload(clause.Associations)
+	stmt = joinTaskTagIds(stmt, cons)
 
 	// conditions
 	where := BuildWhereClause(cons)
@@ -154,10 +230,66 @@ func ListTask(conn *sql.DB, cons *TaskConditions, orderBys *TaskOrderBy, limit *
 	return tasks
 }
 
+// TaskSearchResult is a task matching a full-text search along with its rank and the matches highlighted
+type TaskSearchResult struct {
+	Task
+	Rank           float32
+	TitleHighlight string
+	NoteSnippet    string
+}
+
+// escapeHTMLColumn escapes the HTML special characters of the column in SQL, so that the only markup
+// in the output of ts_headline is the <b></b> it adds around the matches
+func escapeHTMLColumn(column string) string {
+	return `replace(replace(replace(replace(` + column + `, '&', '&amp;'), '<', '&lt;'), '>', '&gt;'), '"', '&quot;')`
+}
+
+// SearchTask returns the tasks matching the query of cons.SearchVector, the best match first. The title is highlighted
+// as a whole while the note is cut down to the fragments around the matches, both wrap the matches in <b></b>.
+func SearchTask(conn *sql.DB, cons *TaskConditions, limit *int, offset *int) []TaskSearchResult {
+	results := make([]TaskSearchResult, 0)
+	if cons == nil || cons.SearchVector == nil || cons.SearchVector.Match == nil {
+		return results
+	}
+	query := *cons.SearchVector.Match
+
+	stmt := db.GormDriver(conn).Model(Task{})
+	stmt = joinTaskTagIds(stmt, cons)
+	stmt = stmt.Select(
+		`"tasks".*, `+
+			`ts_rank("tasks"."search_vector", `+condition.TSQuery+`) AS "rank", `+
+			`ts_headline('`+condition.TextSearchConfig+`', `+escapeHTMLColumn(`"tasks"."title"`)+`, `+condition.TSQuery+`, 'HighlightAll=true') AS "title_highlight", `+
+			`ts_headline('`+condition.TextSearchConfig+`', `+escapeHTMLColumn(`"tasks"."note"`)+`, `+condition.TSQuery+`, 'MaxFragments=2, MaxWords=20, MinWords=5, FragmentDelimiter=" ... "') AS "note_snippet"`,
+		query, query, query,
+	)
+
+	where := BuildWhereClause(cons)
+	if len(where.Exprs) > 0 {
+		stmt = stmt.Where(where)
+	}
+
+	stmt = stmt.Order(`"rank" DESC, "tasks"."id" DESC`)
+
+	if limit != nil {
+		stmt = stmt.Limit(*limit)
+	}
+
+	if offset != nil {
+		stmt = stmt.Offset(*offset)
+	}
+
+	if err := stmt.Scan(&results).Error; err != nil {
+		return results
+	}
+
+	return results
+}
+
 func GetTaskCount(conn *sql.DB, cons *TaskConditions) (int32, error) {
 	var count int64
 
 	stmt := db.GormDriver(conn).Model(Task{})
+	stmt = joinTaskTagIds(stmt, cons)
 
 	where := BuildWhereClause(cons)
 	if len(where.Exprs) > 0 {
@@ -178,3 +310,96 @@ func UpdateTask(conn *sql.Tx, id int, values *TaskFieldValues) error {
 func DeleteTask(conn DBExecutable, id int) error {
 	return db.GormDriver(conn).Delete(&Task{}, id).Error
 }
+
+// ListSubtasks returns the subtasks of the task in the order they were created
+func ListSubtasks(conn DBExecutable, parentId int) []Task {
+	tasks := make([]Task, 0)
+	cons := &TaskConditions{
+		ParentId: &condition.Int{
+			EQ: &parentId,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Order("id").Find(&tasks).Error; err != nil {
+		return tasks
+	}
+
+	return tasks
+}
+
+// SubtaskProgress counts the subtasks of a task and the completed ones
+type SubtaskProgress struct {
+	ParentId  int
+	Total     int
+	Completed int
+}
+
+// GetSubtaskProgress returns the progress of the tasks by their ID, the tasks without subtasks are left out
+func GetSubtaskProgress(conn DBExecutable, parentIds []int) (map[int]SubtaskProgress, error) {
+	progress := make(map[int]SubtaskProgress, len(parentIds))
+	if len(parentIds) == 0 {
+		return progress, nil
+	}
+
+	rows := make([]SubtaskProgress, 0)
+	cons := &TaskConditions{
+		ParentId: &condition.Int{
+			IN: parentIds,
+		},
+	}
+
+	err := db.GormDriver(conn).Model(Task{}).
+		Select("parent_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE is_complete) AS completed").
+		Where(BuildWhereClause(cons)).
+		Group("parent_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		progress[row.ParentId] = row
+	}
+
+	return progress, nil
+}
+
+// RollUpTaskCompletion completes the task when all of its subtasks are complete and reopens it otherwise,
+// a task without subtasks is left as it is.
+func RollUpTaskCompletion(conn DBExecutable, parentId int) error {
+	progress, err := GetSubtaskProgress(conn, []int{parentId})
+	if err != nil {
+		return err
+	}
+
+	parentProgress, ok := progress[parentId]
+	if !ok {
+		return nil
+	}
+
+	values := &TaskFieldValues{
+		IsComplete: GiveColBool(parentProgress.Completed == parentProgress.Total),
+		UpdatedAt:  GiveColTime(time.Now().UTC()),
+	}
+
+	return db.GormDriver(conn).Where(Task{ID: parentId}).Updates(values).Error
+}
+
+// CompleteSubtasks completes the subtasks of the task that are still open
+func CompleteSubtasks(conn DBExecutable, parentId int) error {
+	isComplete := false
+	cons := &TaskConditions{
+		ParentId: &condition.Int{
+			EQ: &parentId,
+		},
+		IsComplete: &condition.Bool{
+			EQ: &isComplete,
+		},
+	}
+	values := &TaskFieldValues{
+		IsComplete: GiveColBool(true),
+		UpdatedAt:  GiveColTime(time.Now().UTC()),
+	}
+
+	return db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values).Error
+}
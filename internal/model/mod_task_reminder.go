@@ -0,0 +1,135 @@
+package model
+
+import (
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/db/field"
+	"time"
+)
+
+const (
+	tableNameTaskReminder string = "task_reminders"
+)
+
+// TaskReminder reminds the owner of a task the given minutes before its specify_datetime
+type TaskReminder struct {
+	ID            int        `json:"id"`
+	TaskId        int        `json:"task_id"`
+	OffsetMinutes int        `json:"offset_minutes"`
+	RemindAt      time.Time  `json:"remind_at"`
+	SentAt        *time.Time `json:"sent_at"`
+	CreatedAt     time.Time  `json:"-"`
+	UpdatedAt     time.Time  `json:"-"`
+}
+
+func (u TaskReminder) TableName() string {
+	return tableNameTaskReminder
+}
+
+type TaskReminderFieldValues struct {
+	ID            field.Int      `db_col:"id"`
+	TaskId        field.Int      `db_col:"task_id"`
+	OffsetMinutes field.Int      `db_col:"offset_minutes"`
+	RemindAt      field.Time     `db_col:"remind_at"`
+	SentAt        field.NullTime `db_col:"sent_at"`
+	CreatedAt     field.Time     `db_col:"created_at"`
+	UpdatedAt     field.Time     `db_col:"updated_at"`
+}
+
+func (val TaskReminderFieldValues) TableName() string {
+	return tableNameTaskReminder
+}
+
+type TaskReminderConditions struct {
+	ID       *condition.Int  `db_col:"id"`
+	TaskId   *condition.Int  `db_col:"task_id"`
+	RemindAt *condition.Time `db_col:"remind_at"`
+	SentAt   *condition.Time `db_col:"sent_at"`
+}
+
+func (val TaskReminderConditions) TableName() string {
+	return tableNameTaskReminder
+}
+
+func CreateTaskReminder(conn DBExecutable, values *TaskReminderFieldValues) (*TaskReminderFieldValues, error) {
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Create(values).Error; err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func GetTaskReminderByID(conn DBExecutable, id int) *TaskReminder {
+	taskReminder := &TaskReminder{}
+	cons := &TaskReminderConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Take(taskReminder).Error; err != nil {
+		return nil
+	}
+
+	return taskReminder
+}
+
+// ListTaskReminders returns the reminders of the tasks, the earliest offset first
+func ListTaskReminders(conn DBExecutable, taskIds []int) []TaskReminder {
+	taskReminders := make([]TaskReminder, 0)
+	if len(taskIds) == 0 {
+		return taskReminders
+	}
+
+	cons := &TaskReminderConditions{
+		TaskId: &condition.Int{
+			IN: taskIds,
+		},
+	}
+
+	if err := db.GormDriver(conn).Where(BuildWhereClause(cons)).Order("task_id, offset_minutes").Find(&taskReminders).Error; err != nil {
+		return taskReminders
+	}
+
+	return taskReminders
+}
+
+func DeleteTaskReminders(conn DBExecutable, taskId int) error {
+	cons := &TaskReminderConditions{
+		TaskId: &condition.Int{
+			EQ: &taskId,
+		},
+	}
+
+	return db.GormDriver(conn).Where(BuildWhereClause(cons)).Delete(&TaskReminder{}).Error
+}
+
+// MarkTaskReminderSent marks the reminder due at remindAt as sent and reports whether this call marked it,
+// it is false once the reminder has been sent or rescheduled.
+func MarkTaskReminderSent(conn DBExecutable, id int, remindAt time.Time, sentAt time.Time) (bool, error) {
+	isNull := true
+	cons := &TaskReminderConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		RemindAt: &condition.Time{
+			EQ: &remindAt,
+		},
+		SentAt: &condition.Time{
+			IsNull: &isNull,
+		},
+	}
+	values := &TaskReminderFieldValues{
+		SentAt:    GiveColNullTime(&sentAt),
+		UpdatedAt: GiveColTime(sentAt),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -84,9 +84,10 @@ func createTestUserForTask(email, username, password string) (*model.UserFieldVa
 	return model.CreateUser(sqlDBTask, userValues)
 }
 
-func createCategoryForTask(name string) (*model.CategoryFieldValues, error) {
+func createCategoryForTask(userId int, name string) (*model.CategoryFieldValues, error) {
 	now := time.Now().UTC()
 	categoryValues := &model.CategoryFieldValues{
+		UserId:    model.GiveColInt(userId),
 		Name:      model.GiveColString(name),
 		CreatedAt: model.GiveColTime(now),
 		UpdatedAt: model.GiveColTime(now),
@@ -103,7 +104,7 @@ func TestCreateTask(t *testing.T) {
 		user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, err)
 
-		category, err := createCategoryForTask(util.RandomString(6))
+		category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
 
 		task, err := createTask(user.ID.Val, category.ID.Val)
@@ -119,7 +120,7 @@ func TestCreateTask(t *testing.T) {
 	})
 }
 
-func TestGetTaskByTitle(t *testing.T) {
+func TestGetOpenTaskByTitle(t *testing.T) {
 	setUpModTask()
 	defer setDownModTask()
 
@@ -127,21 +128,69 @@ func TestGetTaskByTitle(t *testing.T) {
 		user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, err)
 
-		category, err := createCategoryForTask(util.RandomString(6))
+		category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
 
 		task, err := createTask(user.ID.Val, category.ID.Val)
 		assert.Nil(t, err)
 
-		getTask := model.GetTaskByTitle(sqlDBTask, task.Title.Val)
+		updateTaskErr := model.UpdateTask(sqlTxTask, task.ID.Val, &model.TaskFieldValues{
+			IsComplete: model.GiveColBool(false),
+		})
+		assert.Nil(t, updateTaskErr)
+
+		getTask := model.GetOpenTaskByTitle(sqlTxTask, user.ID.Val, category.ID.Val, task.Title.Val)
 		assert.NotNil(t, getTask)
+		assert.Equal(t, task.ID.Val, getTask.ID)
 		assert.Equal(t, task.Title.Val, getTask.Title)
 	})
 
+	t.Run("Failure_CompletedTask", func(t *testing.T) {
+		user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
+		assert.Nil(t, err)
+
+		category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
+		assert.Nil(t, err)
+
+		task, err := createTask(user.ID.Val, category.ID.Val)
+		assert.Nil(t, err)
+
+		// A completed task with the same title does not match
+		updateTaskErr := model.UpdateTask(sqlTxTask, task.ID.Val, &model.TaskFieldValues{
+			IsComplete: model.GiveColBool(true),
+		})
+		assert.Nil(t, updateTaskErr)
+
+		getTask := model.GetOpenTaskByTitle(sqlTxTask, user.ID.Val, category.ID.Val, task.Title.Val)
+		assert.Nil(t, getTask)
+	})
+
 	t.Run("Failure_NonExistentTitle", func(t *testing.T) {
-		task := model.GetTaskByTitle(sqlDBTask, "nonexistent-title")
+		task := model.GetOpenTaskByTitle(sqlDBTask, 999999, 999999, "nonexistent-title")
 		assert.Nil(t, task)
 	})
+
+	t.Run("Failure_OtherUser", func(t *testing.T) {
+		user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
+		assert.Nil(t, err)
+
+		category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
+		assert.Nil(t, err)
+
+		task, err := createTask(user.ID.Val, category.ID.Val)
+		assert.Nil(t, err)
+
+		updateTaskErr := model.UpdateTask(sqlTxTask, task.ID.Val, &model.TaskFieldValues{
+			IsComplete: model.GiveColBool(false),
+		})
+		assert.Nil(t, updateTaskErr)
+
+		otherUser, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
+		assert.Nil(t, err)
+
+		getTask := model.GetOpenTaskByTitle(sqlTxTask, otherUser.ID.Val, category.ID.Val, task.Title.Val)
+		assert.Nil(t, getTask)
+	})
 }
 
 func TestGetTaskByID(t *testing.T) {
@@ -152,7 +201,7 @@ func TestGetTaskByID(t *testing.T) {
 		user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, err)
 
-		category, err := createCategoryForTask(util.RandomString(6))
+		category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, err)
 
 		task, err := createTask(user.ID.Val, category.ID.Val)
@@ -169,6 +218,35 @@ func TestGetTaskByID(t *testing.T) {
 	})
 }
 
+func TestGetTaskByIDAndUserID(t *testing.T) {
+	setUpModTask()
+	defer setDownModTask()
+
+	user, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
+	assert.Nil(t, err)
+
+	category, err := createCategoryForTask(user.ID.Val, util.RandomString(6))
+	assert.Nil(t, err)
+
+	task, err := createTask(user.ID.Val, category.ID.Val)
+	assert.Nil(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		getTask := model.GetTaskByIDAndUserID(sqlDBTask, task.ID.Val, user.ID.Val)
+		assert.NotNil(t, getTask)
+		assert.Equal(t, task.ID.Val, getTask.ID)
+		assert.Equal(t, user.ID.Val, getTask.UserId)
+	})
+
+	t.Run("Failure_OtherUser", func(t *testing.T) {
+		otherUser, err := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
+		assert.Nil(t, err)
+
+		getTask := model.GetTaskByIDAndUserID(sqlDBTask, task.ID.Val, otherUser.ID.Val)
+		assert.Nil(t, getTask)
+	})
+}
+
 func TestListTask(t *testing.T) {
 	setUpModTask()
 	defer setDownModTask()
@@ -177,7 +255,7 @@ func TestListTask(t *testing.T) {
 		user, userErr := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, userErr)
 
-		category, categoryErr := createCategoryForTask(util.RandomString(6))
+		category, categoryErr := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, categoryErr)
 
 		_, err := createTask(user.ID.Val, category.ID.Val)
@@ -234,7 +312,7 @@ func TestGetTaskCount(t *testing.T) {
 		user, userErr := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, userErr)
 
-		category, categoryErr := createCategoryForTask(util.RandomString(6))
+		category, categoryErr := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, categoryErr)
 
 		initialCount, initialCountErr := model.GetTaskCount(sqlDBTask, &model.TaskConditions{
@@ -265,7 +343,7 @@ func TestUpdateTask(t *testing.T) {
 		user, userErr := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, userErr)
 
-		category, categoryErr := createCategoryForTask(util.RandomString(6))
+		category, categoryErr := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, categoryErr)
 
 		task, err := createTask(user.ID.Val, category.ID.Val)
@@ -298,7 +376,7 @@ func TestDeleteTask(t *testing.T) {
 		user, userErr := createTestUserForTask(util.RandomEmail(), util.RandomString(6), util.RandomString(8))
 		assert.Nil(t, userErr)
 
-		category, categoryErr := createCategoryForTask(util.RandomString(6))
+		category, categoryErr := createCategoryForTask(user.ID.Val, util.RandomString(6))
 		assert.Nil(t, categoryErr)
 
 		task, err := createTask(user.ID.Val, category.ID.Val)
@@ -3,9 +3,12 @@ package model
 import (
 	"database/sql"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/builder"
 	"go-todolist-grpc/internal/pkg/db/condition"
 	"go-todolist-grpc/internal/pkg/db/field"
 	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -13,15 +16,22 @@ const (
 )
 
 type User struct {
-	ID              int       `json:"id"`
-	Username        string    `json:"username"`
-	Email           string    `json:"email"`
-	Password        string    `json:"-"`
-	Status          bool      `json:"-"`
-	CreatedAt       time.Time `json:"-"`
-	UpdatedAt       time.Time `json:"-"`
-	IsEmailVerified bool      `json:"-"`
-	Token           string    `json:"token,omitempty" gorm:"-"`
+	ID              int        `json:"id"`
+	Username        string     `json:"username"`
+	Email           string     `json:"email"`
+	Password        string     `json:"-"`
+	Status          bool       `json:"-"`
+	CreatedAt       time.Time  `json:"-"`
+	UpdatedAt       time.Time  `json:"-"`
+	IsEmailVerified bool       `json:"-"`
+	Role            string     `json:"-"`
+	Language        string     `json:"language"`
+	TimeZone        string     `json:"time_zone"`
+	TotpSecret      *string    `json:"-"`
+	IsTotpEnabled   bool       `json:"-"`
+	TotpLastStep    int        `json:"-"`
+	DeactivatedAt   *time.Time `json:"-"`
+	Token           string     `json:"token,omitempty" gorm:"-"`
 }
 
 func (u User) TableName() string {
@@ -29,14 +39,21 @@ func (u User) TableName() string {
 }
 
 type UserFieldValues struct {
-	ID              field.Int    `db_col:"id"`
-	Username        field.String `db_col:"username"`
-	Email           field.String `db_col:"email"`
-	Password        field.String `db_col:"password"`
-	Status          field.Bool   `db_col:"status"`
-	CreatedAt       field.Time   `db_col:"created_at"`
-	UpdatedAt       field.Time   `db_col:"updated_at"`
-	IsEmailVerified field.Bool   `db_col:"is_email_verified"`
+	ID              field.Int        `db_col:"id"`
+	Username        field.String     `db_col:"username"`
+	Email           field.String     `db_col:"email"`
+	Password        field.String     `db_col:"password"`
+	Status          field.Bool       `db_col:"status"`
+	CreatedAt       field.Time       `db_col:"created_at"`
+	UpdatedAt       field.Time       `db_col:"updated_at"`
+	IsEmailVerified field.Bool       `db_col:"is_email_verified"`
+	Role            field.String     `db_col:"role"`
+	Language        field.String     `db_col:"language"`
+	TimeZone        field.String     `db_col:"time_zone"`
+	TotpSecret      field.NullString `db_col:"totp_secret"`
+	IsTotpEnabled   field.Bool       `db_col:"is_totp_enabled"`
+	TotpLastStep    field.Int        `db_col:"totp_last_step"`
+	DeactivatedAt   field.NullTime   `db_col:"deactivated_at"`
 }
 
 func (val UserFieldValues) TableName() string {
@@ -46,13 +63,30 @@ func (val UserFieldValues) TableName() string {
 type UserConditions struct {
 	ID              *condition.Int    `db_col:"id"`
 	Email           *condition.String `db_col:"email"`
+	Status          *condition.Bool   `db_col:"status"`
+	Role            *condition.String `db_col:"role"`
 	IsEmailVerified *condition.Bool   `db_col:"is_email_verified"`
+	TotpLastStep    *condition.Int    `db_col:"totp_last_step"`
+	DeactivatedAt   *condition.Time   `db_col:"deactivated_at"`
 }
 
 func (val UserConditions) TableName() string {
 	return tableNameUser
 }
 
+type UserOrderBy struct {
+	ID    *builder.OrderBy `db_col:"id"`
+	Email *builder.OrderBy `db_col:"email"`
+}
+
+func (ob UserOrderBy) TableName() string {
+	return tableNameUser
+}
+
+func (ob *UserOrderBy) Parse(params map[string]bool) {
+	ParseOrderByParams(params, ob)
+}
+
 func CreateUser(conn DBExecutable, values *UserFieldValues) (*UserFieldValues, error) {
 	gormConn := db.GormDriver(conn)
 
@@ -78,24 +112,76 @@ func getUser(conn DBExecutable, cons *UserConditions) *User {
 	return user
 }
 
-func GetUserByEmail(conn *sql.DB, email string) *User {
+// GetUserByEmail matches the email case-insensitively
+func GetUserByEmail(conn DBExecutable, email string) *User {
+	user := &User{}
+	gormConn := db.GormDriver(conn)
+
+	if err := gormConn.Where(`lower("users"."email") = lower(?)`, email).Take(user).Error; err != nil {
+		return nil
+	}
+
+	return user
+}
+
+func GetUserByID(conn DBExecutable, id int) *User {
 	cons := &UserConditions{
-		Email: &condition.String{
-			EQ: &email,
+		ID: &condition.Int{
+			EQ: &id,
 		},
 	}
 
 	return getUser(conn, cons)
 }
 
-func GetUserByID(conn DBExecutable, id int) *User {
+// LockUserByID reads the user and locks the row until the transaction ends,
+// so that the checks made on behalf of the user are serialised
+func LockUserByID(conn *sql.Tx, id int) *User {
+	user := &User{}
 	cons := &UserConditions{
 		ID: &condition.Int{
 			EQ: &id,
 		},
 	}
 
-	return getUser(conn, cons)
+	gormConn := db.GormDriver(conn).Clauses(clause.Locking{Strength: "UPDATE"})
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(user).Error; err != nil {
+		return nil
+	}
+
+	return user
+}
+
+func ListUser(conn DBExecutable, cons *UserConditions, orderBys *UserOrderBy, limit *int, offset *int) []User {
+	users := make([]User, 0)
+
+	stmt := db.GormDriver(conn).Model(User{})
+
+	// conditions
+	where := BuildWhereClause(cons)
+	if len(where.Exprs) > 0 {
+		stmt = stmt.Where(where)
+	}
+
+	// sorting
+	orderBy := BuildOrderByClause(orderBys)
+	if len(orderBy.Columns) > 0 {
+		stmt = stmt.Clauses(orderBy)
+	}
+
+	if limit != nil {
+		stmt = stmt.Limit(*limit)
+	}
+
+	if offset != nil {
+		stmt = stmt.Offset(*offset)
+	}
+
+	if err := stmt.Find(&users).Error; err != nil {
+		return users
+	}
+
+	return users
 }
 
 func GetUserCount(conn *sql.DB, cons *UserConditions) (int32, error) {
@@ -118,3 +204,77 @@ func GetUserCount(conn *sql.DB, cons *UserConditions) (int32, error) {
 func UpdateUser(conn *sql.Tx, id int, values *UserFieldValues) error {
 	return db.GormDriver(conn).Where(User{ID: id}).Updates(values).Error
 }
+
+// UseTotpStep records the period of an accepted TOTP code and reports whether it was newer than the last one,
+// so that a code cannot be redeemed twice even by concurrent requests.
+func UseTotpStep(conn DBExecutable, id int, step int) (bool, error) {
+	cons := &UserConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		TotpLastStep: &condition.Int{
+			LT: &step,
+		},
+	}
+	values := &UserFieldValues{
+		TotpLastStep: GiveColInt(step),
+		UpdatedAt:    GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// IsUserActive reports whether the user exists and the account is enabled
+func IsUserActive(conn DBExecutable, id int) bool {
+	var count int64
+	isActive := true
+	cons := &UserConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		Status: &condition.Bool{
+			EQ: &isActive,
+		},
+	}
+
+	if err := db.GormDriver(conn).Model(User{}).Where(BuildWhereClause(cons)).Count(&count).Error; err != nil {
+		return false
+	}
+
+	return count > 0
+}
+
+// ReactivateUser enables an account the user deactivated and reports whether this call enabled it,
+// an account disabled by an admin is left as it is.
+func ReactivateUser(conn DBExecutable, id int) (bool, error) {
+	userStatus := false
+	isNull := false
+	cons := &UserConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		Status: &condition.Bool{
+			EQ: &userStatus,
+		},
+		DeactivatedAt: &condition.Time{
+			IsNull: &isNull,
+		},
+	}
+	values := &UserFieldValues{
+		Status:        GiveColBool(true),
+		DeactivatedAt: GiveColNullTime(nil),
+		UpdatedAt:     GiveColTime(time.Now().UTC()),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
@@ -6,6 +6,8 @@ import (
 	"go-todolist-grpc/internal/pkg/db/condition"
 	"go-todolist-grpc/internal/pkg/db/field"
 	"time"
+
+	"gorm.io/gorm/clause"
 )
 
 const (
@@ -13,15 +15,15 @@ const (
 )
 
 type VerifyEmail struct {
-	ID         int       `json:"id"`
-	UserId     int       `json:"user_id"`
-	Username   string    `json:"username"`
-	Email      string    `json:"email"`
-	SecretCode string    `json:"-"`
-	IsUsed     bool      `json:"is_used"`
-	ExpiredAt  time.Time `json:"-"`
-	CreatedAt  time.Time `json:"-"`
-	UpdatedAt  time.Time `json:"-"`
+	ID             int       `json:"id"`
+	UserId         int       `json:"user_id"`
+	Username       string    `json:"username"`
+	Email          string    `json:"email"`
+	SecretCodeHash string    `json:"-"`
+	IsUsed         bool      `json:"is_used"`
+	ExpiredAt      time.Time `json:"-"`
+	CreatedAt      time.Time `json:"-"`
+	UpdatedAt      time.Time `json:"-"`
 }
 
 func (u VerifyEmail) TableName() string {
@@ -29,15 +31,15 @@ func (u VerifyEmail) TableName() string {
 }
 
 type VerifyEmailFieldValues struct {
-	ID         field.Int    `db_col:"id"`
-	UserId     field.Int    `db_col:"user_id"`
-	Username   field.String `db_col:"username"`
-	Email      field.String `db_col:"email"`
-	SecretCode field.String `db_col:"secret_code"`
-	IsUsed     field.Bool   `db_col:"is_used"`
-	ExpiredAt  field.Time   `db_col:"expired_at"`
-	CreatedAt  field.Time   `db_col:"created_at"`
-	UpdatedAt  field.Time   `db_col:"updated_at"`
+	ID             field.Int    `db_col:"id"`
+	UserId         field.Int    `db_col:"user_id"`
+	Username       field.String `db_col:"username"`
+	Email          field.String `db_col:"email"`
+	SecretCodeHash field.String `db_col:"secret_code_hash"`
+	IsUsed         field.Bool   `db_col:"is_used"`
+	ExpiredAt      field.Time   `db_col:"expired_at"`
+	CreatedAt      field.Time   `db_col:"created_at"`
+	UpdatedAt      field.Time   `db_col:"updated_at"`
 }
 
 func (val VerifyEmailFieldValues) TableName() string {
@@ -45,10 +47,13 @@ func (val VerifyEmailFieldValues) TableName() string {
 }
 
 type VerifyEmailConditions struct {
-	ID        *condition.Int    `db_col:"id"`
-	Email     *condition.String `db_col:"email"`
-	IsUsed    *condition.Bool   `db_col:"is_used"`
-	ExpiredAt *condition.Time   `db_col:"expired_at"`
+	ID             *condition.Int    `db_col:"id"`
+	UserId         *condition.Int    `db_col:"user_id"`
+	Email          *condition.String `db_col:"email"`
+	SecretCodeHash *condition.String `db_col:"secret_code_hash"`
+	IsUsed         *condition.Bool   `db_col:"is_used"`
+	ExpiredAt      *condition.Time   `db_col:"expired_at"`
+	CreatedAt      *condition.Time   `db_col:"created_at"`
 }
 
 func (val VerifyEmailConditions) TableName() string {
@@ -96,3 +101,88 @@ func GetVerifyEmailByID(conn DBExecutable, id int, is_used bool, expired_at *tim
 func UpdateVerifyEmail(conn *sql.Tx, id int, values *VerifyEmailFieldValues) error {
 	return db.GormDriver(conn).Where(VerifyEmail{ID: id}).Updates(values).Error
 }
+
+// LockVerifyEmailByID reads the verify email whatever its state and locks the row until the transaction ends
+func LockVerifyEmailByID(conn *sql.Tx, id int) *VerifyEmail {
+	verifyEmail := &VerifyEmail{}
+	cons := &VerifyEmailConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+	}
+
+	gormConn := db.GormDriver(conn).Clauses(clause.Locking{Strength: "UPDATE"})
+	if err := gormConn.Where(BuildWhereClause(cons)).Take(verifyEmail).Error; err != nil {
+		return nil
+	}
+
+	return verifyEmail
+}
+
+// UseVerifyEmail marks the code as used only if the hash matches and the code is still usable,
+// and reports whether this call did it so that a code can never be redeemed twice.
+func UseVerifyEmail(conn *sql.Tx, id int, secretCodeHash string, now time.Time) (bool, error) {
+	isUsed := false
+	cons := &VerifyEmailConditions{
+		ID: &condition.Int{
+			EQ: &id,
+		},
+		SecretCodeHash: &condition.String{
+			EQ: &secretCodeHash,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+		ExpiredAt: &condition.Time{
+			GTE: &now,
+		},
+	}
+	values := &VerifyEmailFieldValues{
+		IsUsed:    GiveColBool(true),
+		UpdatedAt: GiveColTime(now),
+	}
+
+	result := db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected > 0, nil
+}
+
+// GetVerifyEmailSince returns a verify email of the address created at or after the given time
+func GetVerifyEmailSince(conn DBExecutable, email string, since time.Time) *VerifyEmail {
+	cons := &VerifyEmailConditions{
+		Email: &condition.String{
+			EQ: &email,
+		},
+		CreatedAt: &condition.Time{
+			GTE: &since,
+		},
+	}
+
+	return getVerifyEmail(conn, cons)
+}
+
+// ExpireVerifyEmailsByUserID expires every unused code of the user so that only the newest link works
+func ExpireVerifyEmailsByUserID(conn DBExecutable, userId int) error {
+	now := time.Now().UTC()
+	isUsed := false
+	cons := &VerifyEmailConditions{
+		UserId: &condition.Int{
+			EQ: &userId,
+		},
+		IsUsed: &condition.Bool{
+			EQ: &isUsed,
+		},
+		ExpiredAt: &condition.Time{
+			GT: &now,
+		},
+	}
+	values := &VerifyEmailFieldValues{
+		ExpiredAt: GiveColTime(now),
+		UpdatedAt: GiveColTime(now),
+	}
+
+	return db.GormDriver(conn).Where(BuildWhereClause(cons)).Updates(values).Error
+}
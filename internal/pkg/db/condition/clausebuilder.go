@@ -25,6 +25,8 @@ func BuildExpression(name string, value any) []clause.Expression {
 		exps = parseBoolClause(name, *v)
 	case *JSON:
 		exps = parseJSONClause(name, *v)
+	case *TextSearch:
+		exps = parseTextSearchClause(name, *v)
 	default:
 		log.Warning.Printf("unknown where condition type %T", v)
 	}
@@ -74,6 +76,17 @@ func parseIntClause(name string, value Int) []clause.Expression {
 		exps = append(exps, clause.Expr{SQL: name + ` @> ARRAY [` + strings.Join(keys, ", ") + `]::INT[]`, Vars: values})
 	}
 
+	if value.OVERLAP != nil && len(value.OVERLAP) > 0 {
+		var keys = make([]string, 0)
+		var values = make([]interface{}, 0)
+		for _, val := range value.OVERLAP {
+			keys = append(keys, "?")
+			values = append(values, val)
+		}
+
+		exps = append(exps, clause.Expr{SQL: name + ` && ARRAY [` + strings.Join(keys, ", ") + `]::INT[]`, Vars: values})
+	}
+
 	if value.IsNull != nil {
 		var exp clause.Expression
 		exp = clause.Eq{Column: name, Value: nil}
@@ -284,3 +297,13 @@ func parseJSONClause(name string, value JSON) []clause.Expression {
 
 	return exps
 }
+
+func parseTextSearchClause(name string, value TextSearch) []clause.Expression {
+	var exps = make([]clause.Expression, 0)
+
+	if value.Match != nil {
+		exps = append(exps, clause.Expr{SQL: name + ` @@ ` + TSQuery, Vars: []interface{}{*value.Match}})
+	}
+
+	return exps
+}
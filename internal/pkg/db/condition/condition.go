@@ -10,6 +10,7 @@ type Int struct {
 	LTE     *int
 	IN      []int
 	CONTAIN []int
+	OVERLAP []int
 	IsNull  *bool
 }
 
@@ -49,6 +50,19 @@ type Bool struct {
 	Not    *bool
 }
 
+// TextSearchConfig is the text search configuration of the tsvector columns, it splits words without stemming
+// so that the titles in any language are matched alike
+const TextSearchConfig = "simple"
+
+// TSQuery parses its one var, a query in the web search syntax, into a tsquery
+const TSQuery = "websearch_to_tsquery('" + TextSearchConfig + "', ?)"
+
+// TextSearch matches a tsvector column against a query in the web search syntax,
+// e.g. `buy milk`, `"buy milk"`, `milk or bread`, `milk -bread`
+type TextSearch struct {
+	Match *string
+}
+
 type JSON struct {
 	EQ *string
 	IN []string
@@ -12,7 +12,7 @@ import (
 	"runtime"
 	"time"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -117,6 +117,12 @@ func ResetConn() {
 	gRPCDB = nil
 }
 
+// IsUniqueViolation reports whether the error was raised by a unique index
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
 func getRootCertPath() string {
 	// For CI to run the unit tests.
 	if certContent := os.Getenv("RDS_CA_CERT"); certContent != "" {
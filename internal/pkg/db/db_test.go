@@ -3,6 +3,8 @@ package db_test
 import (
 	"crypto/x509"
 	"encoding/pem"
+	"errors"
+	"fmt"
 	"go-todolist-grpc/internal/config"
 	mydb "go-todolist-grpc/internal/pkg/db"
 	"go-todolist-grpc/internal/pkg/log"
@@ -12,6 +14,7 @@ import (
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -302,3 +305,16 @@ func TestVerifyCertificate(t *testing.T) {
 		assert.True(t, now.After(cert.NotBefore) && now.Before(cert.NotAfter))
 	})
 }
+
+func TestIsUniqueViolation(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		err := fmt.Errorf("failed to update task: %w", &pq.Error{Code: "23505"})
+		assert.True(t, mydb.IsUniqueViolation(err))
+	})
+
+	t.Run("Failure_OtherCode", func(t *testing.T) {
+		assert.False(t, mydb.IsUniqueViolation(&pq.Error{Code: "23503"}))
+		assert.False(t, mydb.IsUniqueViolation(errors.New("unique")))
+		assert.False(t, mydb.IsUniqueViolation(nil))
+	})
+}
@@ -0,0 +1,188 @@
+package lockout
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+const (
+	SubjectEmail = "email"
+	SubjectIP    = "ip"
+)
+
+// Store keeps the counters and locks, RedisStore is shared by every instance of the service
+type Store interface {
+	// Incr increments the counter and starts its ttl on the first increment
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Del(ctx context.Context, keys ...string) error
+	// Lock marks the key as locked for the duration
+	Lock(ctx context.Context, key string, ttl time.Duration) error
+	// LockTTL returns how long the key stays locked, 0 if it is not locked
+	LockTTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+type Policy struct {
+	// Failed attempts allowed within the window before the subject is locked
+	MaxEmailAttempts int
+	MaxIPAttempts    int
+	Window           time.Duration
+
+	// The first lockout lasts BaseLockout, every further one within LockoutMemory doubles it up to MaxLockout
+	BaseLockout   time.Duration
+	MaxLockout    time.Duration
+	LockoutMemory time.Duration
+}
+
+func DefaultPolicy() Policy {
+	return Policy{
+		MaxEmailAttempts: 5,
+		MaxIPAttempts:    50,
+		Window:           15 * time.Minute,
+		BaseLockout:      time.Minute,
+		MaxLockout:       time.Hour,
+		LockoutMemory:    24 * time.Hour,
+	}
+}
+
+// withDefaults fills the zero values of the policy from DefaultPolicy
+func (p Policy) withDefaults() Policy {
+	def := DefaultPolicy()
+	if p.MaxEmailAttempts <= 0 {
+		p.MaxEmailAttempts = def.MaxEmailAttempts
+	}
+	if p.MaxIPAttempts <= 0 {
+		p.MaxIPAttempts = def.MaxIPAttempts
+	}
+	if p.Window <= 0 {
+		p.Window = def.Window
+	}
+	if p.BaseLockout <= 0 {
+		p.BaseLockout = def.BaseLockout
+	}
+	if p.MaxLockout <= 0 {
+		p.MaxLockout = def.MaxLockout
+	}
+	if p.LockoutMemory <= 0 {
+		p.LockoutMemory = def.LockoutMemory
+	}
+
+	return p
+}
+
+// Lockout describes a subject that has just been locked
+type Lockout struct {
+	Subject  string
+	Value    string
+	Attempts int64
+	Level    int64
+	Duration time.Duration
+}
+
+// Guard counts the failed logins per email and per IP and locks them progressively
+type Guard struct {
+	store  Store
+	policy Policy
+}
+
+func NewGuard(store Store, policy Policy) *Guard {
+	return &Guard{
+		store:  store,
+		policy: policy.withDefaults(),
+	}
+}
+
+type subject struct {
+	kind        string
+	value       string
+	maxAttempts int
+}
+
+func (g *Guard) subjects(email string, ip string) []subject {
+	subjects := []subject{{SubjectEmail, strings.ToLower(strings.TrimSpace(email)), g.policy.MaxEmailAttempts}}
+	if ip != "" {
+		subjects = append(subjects, subject{SubjectIP, ip, g.policy.MaxIPAttempts})
+	}
+
+	return subjects
+}
+
+func failKey(s subject) string {
+	return "login:fail:" + s.kind + ":" + s.value
+}
+
+func lockKey(s subject) string {
+	return "login:lock:" + s.kind + ":" + s.value
+}
+
+func levelKey(s subject) string {
+	return "login:lockouts:" + s.kind + ":" + s.value
+}
+
+// Check returns how long the email or the IP is still locked, 0 if neither is
+func (g *Guard) Check(ctx context.Context, email string, ip string) (time.Duration, error) {
+	var remaining time.Duration
+	for _, s := range g.subjects(email, ip) {
+		ttl, err := g.store.LockTTL(ctx, lockKey(s))
+		if err != nil {
+			return 0, err
+		}
+		if ttl > remaining {
+			remaining = ttl
+		}
+	}
+
+	return remaining, nil
+}
+
+// Fail records a failed login and returns the lockouts it triggered
+func (g *Guard) Fail(ctx context.Context, email string, ip string) ([]*Lockout, error) {
+	lockouts := []*Lockout{}
+	for _, s := range g.subjects(email, ip) {
+		attempts, err := g.store.Incr(ctx, failKey(s), g.policy.Window)
+		if err != nil {
+			return nil, err
+		}
+		if attempts < int64(s.maxAttempts) {
+			continue
+		}
+
+		// Each lockout remembered for the subject doubles the next one
+		level, err := g.store.Incr(ctx, levelKey(s), g.policy.LockoutMemory)
+		if err != nil {
+			return nil, err
+		}
+
+		duration := g.policy.BaseLockout
+		for i := int64(1); i < level && duration < g.policy.MaxLockout; i++ {
+			duration *= 2
+		}
+		if duration > g.policy.MaxLockout {
+			duration = g.policy.MaxLockout
+		}
+
+		if err := g.store.Lock(ctx, lockKey(s), duration); err != nil {
+			return nil, err
+		}
+		if err := g.store.Del(ctx, failKey(s)); err != nil {
+			return nil, err
+		}
+
+		lockouts = append(lockouts, &Lockout{
+			Subject:  s.kind,
+			Value:    s.value,
+			Attempts: attempts,
+			Level:    level,
+			Duration: duration,
+		})
+	}
+
+	return lockouts, nil
+}
+
+// Succeed clears the failed attempts of the email, the IP keeps its count
+func (g *Guard) Succeed(ctx context.Context, email string) error {
+	s := g.subjects(email, "")[0]
+
+	return g.store.Del(ctx, failKey(s), levelKey(s))
+}
@@ -0,0 +1,139 @@
+package lockout_test
+
+import (
+	"context"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpGuard(policy lockout.Policy) (*lockout.Guard, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := lockout.NewMemoryStore()
+	store.SetClock(func() time.Time { return now })
+
+	return lockout.NewGuard(store, policy), &now
+}
+
+func failTimes(t *testing.T, guard *lockout.Guard, email string, ip string, times int) []*lockout.Lockout {
+	var lockouts []*lockout.Lockout
+	for i := 0; i < times; i++ {
+		var err error
+		lockouts, err = guard.Fail(context.Background(), email, ip)
+		assert.NoError(t, err)
+	}
+
+	return lockouts
+}
+
+func TestGuard(t *testing.T) {
+	ctx := context.Background()
+	policy := lockout.Policy{
+		MaxEmailAttempts: 3,
+		MaxIPAttempts:    5,
+		Window:           10 * time.Minute,
+		BaseLockout:      time.Minute,
+		MaxLockout:       5 * time.Minute,
+		LockoutMemory:    time.Hour,
+	}
+
+	t.Run("Success_EmailLockout", func(t *testing.T) {
+		guard, _ := setUpGuard(policy)
+
+		lockouts := failTimes(t, guard, "Bob@Example.com", "", 2)
+		assert.Empty(t, lockouts)
+
+		lockouts = failTimes(t, guard, "bob@example.com", "", 1)
+		assert.Len(t, lockouts, 1)
+		assert.Equal(t, &lockout.Lockout{
+			Subject:  lockout.SubjectEmail,
+			Value:    "bob@example.com",
+			Attempts: 3,
+			Level:    1,
+			Duration: time.Minute,
+		}, lockouts[0])
+
+		lockedFor, err := guard.Check(ctx, "BOB@example.com", "192.0.2.1")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, lockedFor)
+	})
+
+	t.Run("Success_ProgressiveLockout", func(t *testing.T) {
+		guard, now := setUpGuard(policy)
+
+		expected := []time.Duration{time.Minute, 2 * time.Minute, 4 * time.Minute, 5 * time.Minute, 5 * time.Minute}
+		for level, duration := range expected {
+			lockouts := failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts)
+			assert.Len(t, lockouts, 1)
+			assert.Equal(t, int64(level+1), lockouts[0].Level)
+			assert.Equal(t, duration, lockouts[0].Duration)
+
+			*now = now.Add(duration)
+			lockedFor, err := guard.Check(ctx, "bob@example.com", "")
+			assert.NoError(t, err)
+			assert.Zero(t, lockedFor)
+		}
+
+		// The level is forgotten after the lockout memory
+		*now = now.Add(policy.LockoutMemory)
+		lockouts := failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts)
+		assert.Len(t, lockouts, 1)
+		assert.Equal(t, int64(1), lockouts[0].Level)
+		assert.Equal(t, time.Minute, lockouts[0].Duration)
+	})
+
+	t.Run("Success_WindowExpired", func(t *testing.T) {
+		guard, now := setUpGuard(policy)
+
+		lockouts := failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts-1)
+		assert.Empty(t, lockouts)
+
+		*now = now.Add(policy.Window)
+		lockouts = failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts-1)
+		assert.Empty(t, lockouts)
+	})
+
+	t.Run("Success_IPLockout", func(t *testing.T) {
+		guard, _ := setUpGuard(policy)
+
+		var lockouts []*lockout.Lockout
+		for i := 0; i < policy.MaxIPAttempts; i++ {
+			var err error
+			lockouts, err = guard.Fail(ctx, "user"+string(rune('a'+i))+"@example.com", "192.0.2.1")
+			assert.NoError(t, err)
+		}
+		assert.Len(t, lockouts, 1)
+		assert.Equal(t, lockout.SubjectIP, lockouts[0].Subject)
+		assert.Equal(t, "192.0.2.1", lockouts[0].Value)
+
+		lockedFor, err := guard.Check(ctx, "other@example.com", "192.0.2.1")
+		assert.NoError(t, err)
+		assert.Equal(t, time.Minute, lockedFor)
+
+		lockedFor, err = guard.Check(ctx, "other@example.com", "192.0.2.2")
+		assert.NoError(t, err)
+		assert.Zero(t, lockedFor)
+	})
+
+	t.Run("Success_SucceedResetsEmail", func(t *testing.T) {
+		guard, _ := setUpGuard(policy)
+
+		lockouts := failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts-1)
+		assert.Empty(t, lockouts)
+
+		assert.NoError(t, guard.Succeed(ctx, "bob@example.com"))
+
+		lockouts = failTimes(t, guard, "bob@example.com", "", policy.MaxEmailAttempts-1)
+		assert.Empty(t, lockouts)
+	})
+
+	t.Run("Success_DefaultPolicy", func(t *testing.T) {
+		guard, _ := setUpGuard(lockout.Policy{})
+
+		lockouts := failTimes(t, guard, "bob@example.com", "", lockout.DefaultPolicy().MaxEmailAttempts)
+		assert.Len(t, lockouts, 1)
+		assert.Equal(t, lockout.DefaultPolicy().BaseLockout, lockouts[0].Duration)
+	})
+}
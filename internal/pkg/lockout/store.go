@@ -0,0 +1,138 @@
+package lockout
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// incrScript starts the ttl on the first increment only, so the window stays fixed from the first failure
+var incrScript = redis.NewScript(`
+local value = redis.call("INCR", KEYS[1])
+if value == 1 then
+	redis.call("PEXPIRE", KEYS[1], ARGV[1])
+end
+return value
+`)
+
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+func (s *RedisStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	return incrScript.Run(ctx, s.client, []string{key}, ttl.Milliseconds()).Int64()
+}
+
+func (s *RedisStore) Del(ctx context.Context, keys ...string) error {
+	return s.client.Del(ctx, keys...).Err()
+}
+
+func (s *RedisStore) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	return s.client.Set(ctx, key, 1, ttl).Err()
+}
+
+func (s *RedisStore) LockTTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := s.client.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// -2 when the key does not exist, -1 when it has no expiry
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
+
+// MemoryStore keeps everything in the process, for tests and single instance development
+type MemoryStore struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	entries map[string]*memoryEntry
+}
+
+type memoryEntry struct {
+	value     int64
+	expiredAt time.Time
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		now:     time.Now,
+		entries: make(map[string]*memoryEntry),
+	}
+}
+
+// SetClock replaces the clock used for the expiry of the entries
+func (s *MemoryStore) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.now = now
+}
+
+// get returns the entry if it has not expired, the caller holds the lock
+func (s *MemoryStore) get(key string) *memoryEntry {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil
+	}
+	if !s.now().Before(entry.expiredAt) {
+		delete(s.entries, key)
+		return nil
+	}
+
+	return entry
+}
+
+func (s *MemoryStore) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.get(key)
+	if entry == nil {
+		entry = &memoryEntry{expiredAt: s.now().Add(ttl)}
+		s.entries[key] = entry
+	}
+	entry.value++
+
+	return entry.value, nil
+}
+
+func (s *MemoryStore) Del(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		delete(s.entries, key)
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) Lock(ctx context.Context, key string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.entries[key] = &memoryEntry{value: 1, expiredAt: s.now().Add(ttl)}
+
+	return nil
+}
+
+func (s *MemoryStore) LockTTL(ctx context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry := s.get(key)
+	if entry == nil {
+		return 0, nil
+	}
+
+	return entry.expiredAt.Sub(s.now()), nil
+}
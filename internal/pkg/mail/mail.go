@@ -1,14 +1,17 @@
 package mail
 
 import (
-	"go-todolist-grpc/internal/config"
-	"go-todolist-grpc/internal/pkg/log"
-
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/awserr"
-	"github.com/aws/aws-sdk-go/aws/credentials"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/ses"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
 )
 
 const (
@@ -16,85 +19,152 @@ const (
 	Region  = "ap-northeast-1"
 )
 
-func SendEmail(cnf *config.Config, sender string, recipient []string, bccs []string, subject string, htmlBody string, textBody string) error {
+const (
+	DriverSES    = "ses"
+	DriverSMTP   = "smtp"
+	DriverFile   = "file"
+	DriverMemory = "memory"
+)
+
+type Message struct {
+	Sender    string
+	Recipient []string
+	Bccs      []string
+	Subject   string
+	HtmlBody  string
+	TextBody  string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Option struct {
+	// ses, smtp, file or memory, defaults to ses
+	Driver string
+
+	// SES
+	Region             string
+	AWSAccessKeyId     string
+	AWSSecretAccessKey string
+	ConfigurationSet   string
+
+	// SMTP
+	SMTPHost     string
+	SMTPPort     string
+	SMTPUsername string
+	SMTPPassword string
+	SMTPStartTLS bool
 
-	sess, err := session.NewSession(&aws.Config{
-		Region:      aws.String(Region),
-		Credentials: credentials.NewStaticCredentials(cnf.AWSAccessKeyId, cnf.AWSSecretAccessKey, ""),
-	})
+	// File sink
+	FileDir string
+}
+
+func New(opt *Option) (Mailer, error) {
+	switch opt.Driver {
+	case "", DriverSES:
+		return NewSESMailer(opt.Region, opt.AWSAccessKeyId, opt.AWSSecretAccessKey, opt.ConfigurationSet)
+	case DriverSMTP:
+		return NewSMTPMailer(opt.SMTPHost, opt.SMTPPort, opt.SMTPUsername, opt.SMTPPassword, opt.SMTPStartTLS), nil
+	case DriverFile:
+		return NewFileMailer(opt.FileDir)
+	case DriverMemory:
+		return NewMemoryMailer(), nil
+	}
+
+	return nil, fmt.Errorf("unsupported mail driver: %s", opt.Driver)
+}
+
+// BuildMIMEMessage encodes the message as multipart/alternative with a text and an HTML part,
+// Bcc recipients are left out of the headers on purpose.
+func BuildMIMEMessage(msg *Message) ([]byte, error) {
+	from, err := mail.ParseAddress(msg.Sender)
 	if err != nil {
-		log.Error.Printf("Error creating AWS session: %v", err)
-		return err
+		return nil, fmt.Errorf("invalid sender: %w", err)
 	}
 
-	// Create an SES session.
-	svc := ses.New(sess)
-	bccAddress := []*string{}
-	recipientAddress := []*string{}
+	buf := bytes.Buffer{}
+	header := textproto.MIMEHeader{}
+	header.Set("From", from.String())
+	header.Set("To", strings.Join(msg.Recipient, ", "))
+	header.Set("Subject", mime.QEncoding.Encode(CharSet, msg.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%s@%s>", randomBoundary(), senderDomain(from.Address)))
+	header.Set("MIME-Version", "1.0")
 
-	for i := range bccs {
-		bccAddress = append(bccAddress, &bccs[i])
+	parts := make([]part, 0, 2)
+	if msg.TextBody != "" {
+		parts = append(parts, part{"text/plain", msg.TextBody})
 	}
-	for i := range recipient {
-		recipientAddress = append(recipientAddress, &recipient[i])
+	if msg.HtmlBody != "" {
+		parts = append(parts, part{"text/html", msg.HtmlBody})
 	}
 
-	// Assemble the email.
-	html := &ses.Content{}
-	if htmlBody != "" {
-		html = &ses.Content{
-			Charset: aws.String(CharSet),
-			Data:    aws.String(htmlBody),
+	if len(parts) == 1 {
+		header.Set("Content-Type", fmt.Sprintf("%s; charset=%s", parts[0].contentType, CharSet))
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, parts[0].body); err != nil {
+			return nil, err
 		}
-	}
 
-	text := &ses.Content{
-		Charset: aws.String(CharSet),
-		Data:    aws.String(textBody),
+		return buf.Bytes(), nil
 	}
 
-	input := &ses.SendEmailInput{
-		Destination: &ses.Destination{
-			CcAddresses:  []*string{},
-			BccAddresses: bccAddress,
-			ToAddresses:  recipientAddress,
-		},
-		Message: &ses.Message{
-			Body: &ses.Body{
-				Html: html,
-				Text: text,
-			},
-			Subject: &ses.Content{
-				Charset: aws.String(CharSet),
-				Data:    aws.String(subject),
-			},
-		},
-		Source: aws.String(sender),
+	boundary := randomBoundary()
+	header.Set("Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", boundary))
+	writeHeader(&buf, header)
+
+	for _, p := range parts {
+		fmt.Fprintf(&buf, "--%s\r\n", boundary)
+		fmt.Fprintf(&buf, "Content-Type: %s; charset=%s\r\n", p.contentType, CharSet)
+		buf.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+		if err := writeQuotedPrintable(&buf, p.body); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\r\n")
 	}
+	fmt.Fprintf(&buf, "--%s--\r\n", boundary)
 
-	// Attempt to send the email.
-	result, err := svc.SendEmail(input)
+	return buf.Bytes(), nil
+}
 
-	// Display error messages if they occur.
-	if err != nil {
-		if aerr, ok := err.(awserr.Error); ok {
-			switch aerr.Code() {
-			case ses.ErrCodeMessageRejected:
-				log.Error.Print(ses.ErrCodeMessageRejected, aerr.Error())
-			case ses.ErrCodeMailFromDomainNotVerifiedException:
-				log.Error.Print(ses.ErrCodeMailFromDomainNotVerifiedException, aerr.Error())
-			case ses.ErrCodeConfigurationSetDoesNotExistException:
-				log.Error.Print(ses.ErrCodeConfigurationSetDoesNotExistException, aerr.Error())
-			default:
-				log.Error.Print(aerr.Error())
-			}
-		} else {
-			log.Error.Print(err.Error())
+type part struct {
+	contentType string
+	body        string
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for _, key := range []string{"From", "To", "Subject", "Date", "Message-ID", "MIME-Version", "Content-Type", "Content-Transfer-Encoding"} {
+		if value := header.Get(key); value != "" {
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
 		}
+	}
+	buf.WriteString("\r\n")
+}
 
+func writeQuotedPrintable(buf *bytes.Buffer, body string) error {
+	writer := quotedprintable.NewWriter(buf)
+	if _, err := writer.Write([]byte(body)); err != nil {
 		return err
 	}
-	log.Debug.Printf("Email Sent to address: %s, result: %v", recipient, result)
 
-	return nil
+	return writer.Close()
+}
+
+func randomBoundary() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+
+	return hex.EncodeToString(b)
+}
+
+func senderDomain(address string) string {
+	if i := strings.LastIndex(address, "@"); i >= 0 {
+		return address[i+1:]
+	}
+
+	return "localhost"
 }
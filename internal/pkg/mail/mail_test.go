@@ -0,0 +1,225 @@
+package mail_test
+
+import (
+	"bufio"
+	"context"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	stdmail "net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestMessage() *mail.Message {
+	return &mail.Message{
+		Sender:    "Go-Todolist-gRPC <noreply@example.com>",
+		Recipient: []string{"user@example.com"},
+		Bccs:      []string{"audit@example.com"},
+		Subject:   "Welcome to Go-Todolist-gRPC",
+		HtmlBody:  "<p>Hello</p>",
+		TextBody:  "Hello",
+	}
+}
+
+func TestBuildMIMEMessage(t *testing.T) {
+	t.Run("Success_Multipart", func(t *testing.T) {
+		data, err := mail.BuildMIMEMessage(newTestMessage())
+		assert.NoError(t, err)
+
+		msg, err := stdmail.ReadMessage(strings.NewReader(string(data)))
+		assert.NoError(t, err)
+		assert.Equal(t, "user@example.com", msg.Header.Get("To"))
+		assert.Empty(t, msg.Header.Get("Bcc"))
+		assert.NotContains(t, string(data), "audit@example.com")
+
+		mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		assert.Equal(t, "multipart/alternative", mediaType)
+
+		reader := multipart.NewReader(msg.Body, params["boundary"])
+		contentTypes := []string{}
+		bodies := []string{}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+
+			body, err := io.ReadAll(part)
+			assert.NoError(t, err)
+			contentTypes = append(contentTypes, part.Header.Get("Content-Type"))
+			bodies = append(bodies, string(body))
+		}
+		assert.Equal(t, []string{"text/plain; charset=UTF-8", "text/html; charset=UTF-8"}, contentTypes)
+		assert.Equal(t, []string{"Hello", "<p>Hello</p>"}, bodies)
+	})
+
+	t.Run("Success_HtmlOnly", func(t *testing.T) {
+		msg := newTestMessage()
+		msg.TextBody = ""
+
+		data, err := mail.BuildMIMEMessage(msg)
+		assert.NoError(t, err)
+		assert.Contains(t, string(data), "Content-Type: text/html; charset=UTF-8")
+		assert.NotContains(t, string(data), "multipart/alternative")
+	})
+
+	t.Run("Failure_InvalidSender", func(t *testing.T) {
+		msg := newTestMessage()
+		msg.Sender = "invalid sender"
+
+		data, err := mail.BuildMIMEMessage(msg)
+		assert.Error(t, err)
+		assert.Nil(t, data)
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("Success_Memory", func(t *testing.T) {
+		mailer, err := mail.New(&mail.Option{Driver: mail.DriverMemory})
+		assert.NoError(t, err)
+		assert.IsType(t, &mail.MemoryMailer{}, mailer)
+	})
+
+	t.Run("Failure_UnsupportedDriver", func(t *testing.T) {
+		mailer, err := mail.New(&mail.Option{Driver: "carrier-pigeon"})
+		assert.EqualError(t, err, "unsupported mail driver: carrier-pigeon")
+		assert.Nil(t, mailer)
+	})
+}
+
+func TestMemoryMailer(t *testing.T) {
+	mailer := mail.NewMemoryMailer()
+
+	err := mailer.Send(context.Background(), newTestMessage())
+	assert.NoError(t, err)
+	assert.Len(t, mailer.Messages(), 1)
+	assert.Equal(t, "Welcome to Go-Todolist-gRPC", mailer.Messages()[0].Subject)
+}
+
+func TestFileMailer(t *testing.T) {
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	dir := t.TempDir()
+	mailer, err := mail.NewFileMailer(dir)
+	assert.NoError(t, err)
+
+	err = mailer.Send(context.Background(), newTestMessage())
+	assert.NoError(t, err)
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	assert.NoError(t, err)
+	assert.Len(t, files, 1)
+
+	data, err := os.ReadFile(files[0])
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Subject: Welcome to Go-Todolist-gRPC")
+}
+
+// runFakeSMTPServer accepts one session without TLS and returns the envelope and data it received
+func runFakeSMTPServer(t *testing.T, listener net.Listener) <-chan []string {
+	received := make(chan []string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		lines := []string{}
+		reader := bufio.NewReader(conn)
+		write := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		write("220 localhost ESMTP")
+		inData := false
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				break
+			}
+			line = strings.TrimRight(line, "\r\n")
+
+			if inData {
+				if line == "." {
+					inData = false
+					write("250 OK")
+					continue
+				}
+				lines = append(lines, line)
+				continue
+			}
+
+			lines = append(lines, line)
+			switch {
+			case strings.HasPrefix(line, "EHLO"):
+				write("250-localhost")
+				write("250 AUTH PLAIN")
+			case strings.HasPrefix(line, "AUTH"):
+				write("235 Authentication successful")
+			case strings.HasPrefix(line, "DATA"):
+				inData = true
+				write("354 Start mail input")
+			case strings.HasPrefix(line, "QUIT"):
+				write("221 Bye")
+				received <- lines
+				return
+			default:
+				write("250 OK")
+			}
+		}
+		received <- lines
+	}()
+
+	return received
+}
+
+func TestSMTPMailer(t *testing.T) {
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	t.Run("Success", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		received := runFakeSMTPServer(t, listener)
+
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		mailer := mail.NewSMTPMailer(host, port, "user", "secret", false)
+
+		err = mailer.Send(context.Background(), newTestMessage())
+		assert.NoError(t, err)
+
+		session := strings.Join(<-received, "\n")
+		assert.Contains(t, session, "AUTH PLAIN")
+		assert.Contains(t, session, "MAIL FROM:<noreply@example.com>")
+		assert.Contains(t, session, "RCPT TO:<user@example.com>")
+		assert.Contains(t, session, "RCPT TO:<audit@example.com>")
+		assert.Contains(t, session, "Subject: Welcome to Go-Todolist-gRPC")
+	})
+
+	t.Run("Failure_StartTLSUnsupported", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		defer listener.Close()
+
+		runFakeSMTPServer(t, listener)
+
+		host, port, _ := net.SplitHostPort(listener.Addr().String())
+		mailer := mail.NewSMTPMailer(host, port, "", "", true)
+
+		err = mailer.Send(context.Background(), newTestMessage())
+		assert.EqualError(t, err, "smtp server does not support STARTTLS")
+	})
+}
@@ -0,0 +1,115 @@
+package mail
+
+import (
+	"context"
+	"go-todolist-grpc/internal/pkg/log"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/ses"
+	"github.com/aws/aws-sdk-go/service/ses/sesiface"
+)
+
+type SESMailer struct {
+	svc              sesiface.SESAPI
+	configurationSet string
+}
+
+// NewSESMailer uses the static credentials when given, otherwise the default AWS credential chain (e.g. an IAM role).
+func NewSESMailer(region string, accessKeyId string, secretAccessKey string, configurationSet string) (*SESMailer, error) {
+	if region == "" {
+		region = Region
+	}
+
+	awsConfig := &aws.Config{
+		Region: aws.String(region),
+	}
+	if accessKeyId != "" {
+		awsConfig.Credentials = credentials.NewStaticCredentials(accessKeyId, secretAccessKey, "")
+	}
+
+	sess, err := session.NewSession(awsConfig)
+	if err != nil {
+		log.Error.Printf("Error creating AWS session: %v", err)
+		return nil, err
+	}
+
+	return &SESMailer{
+		svc:              ses.New(sess),
+		configurationSet: configurationSet,
+	}, nil
+}
+
+func (m *SESMailer) Send(ctx context.Context, msg *Message) error {
+	bccAddress := []*string{}
+	recipientAddress := []*string{}
+
+	for i := range msg.Bccs {
+		bccAddress = append(bccAddress, &msg.Bccs[i])
+	}
+	for i := range msg.Recipient {
+		recipientAddress = append(recipientAddress, &msg.Recipient[i])
+	}
+
+	// Assemble the email, SES sends it as multipart/alternative when both bodies are given.
+	body := &ses.Body{}
+	if msg.HtmlBody != "" {
+		body.Html = &ses.Content{
+			Charset: aws.String(CharSet),
+			Data:    aws.String(msg.HtmlBody),
+		}
+	}
+	if msg.TextBody != "" {
+		body.Text = &ses.Content{
+			Charset: aws.String(CharSet),
+			Data:    aws.String(msg.TextBody),
+		}
+	}
+
+	input := &ses.SendEmailInput{
+		Destination: &ses.Destination{
+			CcAddresses:  []*string{},
+			BccAddresses: bccAddress,
+			ToAddresses:  recipientAddress,
+		},
+		Message: &ses.Message{
+			Body: body,
+			Subject: &ses.Content{
+				Charset: aws.String(CharSet),
+				Data:    aws.String(msg.Subject),
+			},
+		},
+		Source: aws.String(msg.Sender),
+	}
+	if m.configurationSet != "" {
+		input.ConfigurationSetName = aws.String(m.configurationSet)
+	}
+
+	// Attempt to send the email.
+	result, err := m.svc.SendEmailWithContext(ctx, input)
+
+	// Display error messages if they occur.
+	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok {
+			switch aerr.Code() {
+			case ses.ErrCodeMessageRejected:
+				log.Error.Print(ses.ErrCodeMessageRejected, aerr.Error())
+			case ses.ErrCodeMailFromDomainNotVerifiedException:
+				log.Error.Print(ses.ErrCodeMailFromDomainNotVerifiedException, aerr.Error())
+			case ses.ErrCodeConfigurationSetDoesNotExistException:
+				log.Error.Print(ses.ErrCodeConfigurationSetDoesNotExistException, aerr.Error())
+			default:
+				log.Error.Print(aerr.Error())
+			}
+		} else {
+			log.Error.Print(err.Error())
+		}
+
+		return err
+	}
+	log.Debug.Printf("Email Sent to address: %s, result: %v", msg.Recipient, result)
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"go-todolist-grpc/internal/pkg/log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// FileMailer writes every message as an .eml file, for local development
+type FileMailer struct {
+	dir string
+}
+
+func NewFileMailer(dir string) (*FileMailer, error) {
+	if dir == "" {
+		dir = "./target/mail/"
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{
+		dir: dir,
+	}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := BuildMIMEMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	path := filepath.Join(m.dir, fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102150405"), randomBoundary()[:8]))
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return err
+	}
+	log.Debug.Printf("Email written to file: %s", path)
+
+	return nil
+}
+
+// MemoryMailer keeps every message in memory, for tests
+type MemoryMailer struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemoryMailer() *MemoryMailer {
+	return &MemoryMailer{}
+}
+
+func (m *MemoryMailer) Send(ctx context.Context, msg *Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.messages = append(m.messages, *msg)
+
+	return nil
+}
+
+func (m *MemoryMailer) Messages() []Message {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]Message{}, m.messages...)
+}
@@ -0,0 +1,101 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-todolist-grpc/internal/pkg/log"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+type SMTPMailer struct {
+	host     string
+	port     string
+	username string
+	password string
+	startTLS bool
+}
+
+func NewSMTPMailer(host string, port string, username string, password string, startTLS bool) *SMTPMailer {
+	if port == "" {
+		port = "587"
+	}
+
+	return &SMTPMailer{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		startTLS: startTLS,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := BuildMIMEMessage(msg)
+	if err != nil {
+		return err
+	}
+
+	from, err := mail.ParseAddress(msg.Sender)
+	if err != nil {
+		return fmt.Errorf("invalid sender: %w", err)
+	}
+
+	dialer := &net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(m.host, m.port))
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if m.startTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: m.host}); err != nil {
+			return err
+		}
+	}
+
+	if m.username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.username, m.password, m.host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return err
+	}
+	for _, rcpt := range append(append([]string{}, msg.Recipient...), msg.Bccs...) {
+		if err := client.Rcpt(rcpt); err != nil {
+			return err
+		}
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return err
+	}
+	if err := writer.Close(); err != nil {
+		return err
+	}
+	log.Debug.Printf("Email Sent to address: %s, via smtp %s", msg.Recipient, m.host)
+
+	return client.Quit()
+}
@@ -0,0 +1,245 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+const (
+	DefaultLocale = "en"
+
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+	TemplateTaskReminder  = "task_reminder"
+)
+
+//go:embed templates
+var embeddedTemplates embed.FS
+
+// Rendered is a mail ready to be put into a Message
+type Rendered struct {
+	Subject  string
+	HtmlBody string
+	TextBody string
+}
+
+// Registry holds the mail templates of every locale, laid out as layout.tmpl plus <locale>/<name>.tmpl,
+// where each template defines "subject", "title" and "content".
+type Registry struct {
+	templates map[string]map[string]*template.Template
+}
+
+// LoadRegistry loads the templates from the directory, or the embedded ones when the directory is empty.
+func LoadRegistry(dir string) (*Registry, error) {
+	if dir != "" {
+		return NewRegistry(os.DirFS(dir))
+	}
+
+	sub, err := fs.Sub(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+
+	return NewRegistry(sub)
+}
+
+func NewRegistry(fsys fs.FS) (*Registry, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	registry := &Registry{templates: make(map[string]map[string]*template.Template)}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		locale := entry.Name()
+		files, err := fs.Glob(fsys, path.Join(locale, "*.tmpl"))
+		if err != nil {
+			return nil, err
+		}
+
+		registry.templates[locale] = make(map[string]*template.Template)
+		for _, file := range files {
+			tmpl, err := template.ParseFS(fsys, "layout.tmpl", file)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse mail template %s: %w", file, err)
+			}
+
+			name := strings.TrimSuffix(path.Base(file), ".tmpl")
+			registry.templates[locale][name] = tmpl
+		}
+	}
+
+	if _, ok := registry.templates[DefaultLocale]; !ok {
+		return nil, fmt.Errorf("mail templates of the default locale %s not found", DefaultLocale)
+	}
+
+	return registry, nil
+}
+
+func (r *Registry) Locales() []string {
+	locales := make([]string, 0, len(r.templates))
+	for locale := range r.templates {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	return locales
+}
+
+// lookup picks the template of the exact locale, then of its base language, then of the default locale.
+func (r *Registry) lookup(name string, locale string) (*template.Template, string) {
+	candidates := []string{locale}
+	if base, _, ok := strings.Cut(locale, "-"); ok {
+		candidates = append(candidates, base)
+	}
+	candidates = append(candidates, DefaultLocale)
+
+	for _, candidate := range candidates {
+		for key, templates := range r.templates {
+			if !strings.EqualFold(key, candidate) {
+				continue
+			}
+			if tmpl, ok := templates[name]; ok {
+				return tmpl, key
+			}
+		}
+	}
+
+	return nil, ""
+}
+
+func (r *Registry) Render(name string, locale string, data interface{}) (*Rendered, error) {
+	tmpl, matched := r.lookup(name, locale)
+	if tmpl == nil {
+		return nil, fmt.Errorf("mail template %s not found", name)
+	}
+
+	view := struct {
+		Locale string
+		Data   interface{}
+	}{
+		Locale: matched,
+		Data:   data,
+	}
+
+	subject := bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(&subject, "subject", view); err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
+	}
+
+	htmlStr := bytes.Buffer{}
+	if err := tmpl.ExecuteTemplate(&htmlStr, "layout", view); err != nil {
+		return nil, fmt.Errorf("error executing template: %v", err)
+	}
+
+	return &Rendered{
+		Subject:  html.UnescapeString(strings.TrimSpace(subject.String())),
+		HtmlBody: htmlStr.String(),
+		TextBody: HtmlToText(htmlStr.String()),
+	}, nil
+}
+
+// HtmlToText generates the plain-text alternative of a mail, links keep their URL next to the text.
+func HtmlToText(htmlStr string) string {
+	tokenizer := nethtml.NewTokenizer(strings.NewReader(htmlStr))
+	buf := strings.Builder{}
+	skip := 0
+	href := ""
+	linkText := strings.Builder{}
+
+	write := func(s string) {
+		if href != "" {
+			linkText.WriteString(s)
+			return
+		}
+		buf.WriteString(s)
+	}
+
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == nethtml.ErrorToken {
+			break
+		}
+
+		token := tokenizer.Token()
+		switch tokenType {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			switch token.Data {
+			case "head", "style", "script", "title":
+				skip++
+			case "br":
+				write("\n")
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "li", "tr":
+				write("\n")
+			case "a":
+				for _, attr := range token.Attr {
+					if attr.Key == "href" {
+						href = attr.Val
+					}
+				}
+			}
+		case nethtml.EndTagToken:
+			switch token.Data {
+			case "head", "style", "script", "title":
+				skip--
+			case "p", "div", "h1", "h2", "h3", "h4", "h5", "h6", "li", "tr":
+				write("\n")
+			case "a":
+				text := strings.TrimSpace(linkText.String())
+				url := href
+				href = ""
+				linkText.Reset()
+				if text == "" || text == url {
+					write(url)
+				} else {
+					write(fmt.Sprintf("%s: %s", text, url))
+				}
+			}
+		case nethtml.TextToken:
+			if skip > 0 {
+				continue
+			}
+			// Collapse whitespace but keep a separator around inline tags
+			text := strings.Join(strings.Fields(token.Data), " ")
+			if text != "" && strings.TrimLeft(token.Data, " \t\r\n") != token.Data {
+				text = " " + text
+			}
+			if text != "" && strings.TrimRight(token.Data, " \t\r\n") != token.Data {
+				text = text + " "
+			}
+			write(text)
+		}
+	}
+
+	// Trim every line and keep at most one blank line in between
+	lines := []string{}
+	blank := true
+	for _, line := range strings.Split(buf.String(), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			if !blank {
+				lines = append(lines, "")
+			}
+			blank = true
+			continue
+		}
+		lines = append(lines, line)
+		blank = false
+	}
+
+	return strings.TrimSpace(strings.Join(lines, "\n")) + "\n"
+}
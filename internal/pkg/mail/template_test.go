@@ -0,0 +1,138 @@
+package mail_test
+
+import (
+	"go-todolist-grpc/internal/pkg/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTemplateData() map[string]interface{} {
+	return map[string]interface{}{
+		"Username":      "bob & co",
+		"VerifyURL":     "https://todo.example.com/v1/user/verify_email?token=abc.def",
+		"ResetURL":      "https://todo.example.com/reset_password?token=abc.def",
+		"ExpireMinutes": 15,
+	}
+}
+
+func TestRegistryRender(t *testing.T) {
+	registry, err := mail.LoadRegistry("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"en", "zh-TW"}, registry.Locales())
+
+	urls := map[string]string{
+		mail.TemplateVerifyEmail:   "https://todo.example.com/v1/user/verify_email?token=abc.def",
+		mail.TemplateResetPassword: "https://todo.example.com/reset_password?token=abc.def",
+	}
+
+	for _, locale := range registry.Locales() {
+		for name, url := range urls {
+			t.Run("Success_"+locale+"_"+name, func(t *testing.T) {
+				rendered, err := registry.Render(name, locale, newTemplateData())
+				assert.NoError(t, err)
+				assert.NotEmpty(t, rendered.Subject)
+				assert.Contains(t, rendered.HtmlBody, `<html lang="`+locale+`">`)
+				assert.Contains(t, rendered.HtmlBody, strings.ReplaceAll(url, "&", "&amp;"))
+				assert.Contains(t, rendered.HtmlBody, "bob &amp; co")
+				assert.Contains(t, rendered.TextBody, url)
+				assert.Contains(t, rendered.TextBody, "bob & co")
+				assert.NotContains(t, rendered.TextBody, "<")
+			})
+		}
+	}
+
+	for _, locale := range registry.Locales() {
+		t.Run("Success_"+locale+"_"+mail.TemplateTaskReminder, func(t *testing.T) {
+			rendered, err := registry.Render(mail.TemplateTaskReminder, locale, map[string]interface{}{
+				"Username":      "bob & co",
+				"Title":         "Pay <rent>",
+				"Note":          "",
+				"DueAt":         "2024-01-31 09:00 CST",
+				"OffsetMinutes": 15,
+			})
+			assert.NoError(t, err)
+			assert.Contains(t, rendered.Subject, "Pay <rent>")
+			assert.Contains(t, rendered.HtmlBody, "Pay &lt;rent&gt;")
+			assert.Contains(t, rendered.TextBody, "2024-01-31 09:00 CST")
+			assert.Contains(t, rendered.TextBody, "15")
+		})
+	}
+
+	t.Run("Success_BaseLanguageFallback", func(t *testing.T) {
+		registry, err := mail.NewRegistry(fstest.MapFS{
+			"layout.tmpl":          {Data: []byte(`{{define "layout"}}{{template "content" .}}{{end}}`)},
+			"en/verify_email.tmpl": {Data: []byte(`{{define "subject"}}Hello{{end}}{{define "content"}}<p>Hello</p>{{end}}`)},
+			"zh/verify_email.tmpl": {Data: []byte(`{{define "subject"}}你好{{end}}{{define "content"}}<p>你好</p>{{end}}`)},
+		})
+		assert.NoError(t, err)
+
+		rendered, err := registry.Render(mail.TemplateVerifyEmail, "zh-CN", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, "你好", rendered.Subject)
+	})
+
+	t.Run("Success_DefaultLocaleFallback", func(t *testing.T) {
+		rendered, err := registry.Render(mail.TemplateVerifyEmail, "fr", newTemplateData())
+		assert.NoError(t, err)
+		assert.Equal(t, "Welcome to Go-Todolist-gRPC", rendered.Subject)
+		assert.Contains(t, rendered.HtmlBody, `<html lang="en">`)
+	})
+
+	t.Run("Success_CaseInsensitiveLocale", func(t *testing.T) {
+		rendered, err := registry.Render(mail.TemplateVerifyEmail, "zh-tw", newTemplateData())
+		assert.NoError(t, err)
+		assert.Contains(t, rendered.HtmlBody, `<html lang="zh-TW">`)
+	})
+
+	t.Run("Failure_TemplateNotFound", func(t *testing.T) {
+		rendered, err := registry.Render("unknown", "en", newTemplateData())
+		assert.EqualError(t, err, "mail template unknown not found")
+		assert.Nil(t, rendered)
+	})
+}
+
+func TestLoadRegistry(t *testing.T) {
+	t.Run("Success_CustomDir", func(t *testing.T) {
+		dir := t.TempDir()
+		assert.NoError(t, os.MkdirAll(filepath.Join(dir, "en"), 0o755))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "layout.tmpl"), []byte(`{{define "layout"}}<body>{{template "content" .}}</body>{{end}}`), 0o644))
+		assert.NoError(t, os.WriteFile(filepath.Join(dir, "en", "verify_email.tmpl"), []byte(`{{define "subject"}}Custom{{end}}{{define "content"}}<p>{{.Data.Username}}</p>{{end}}`), 0o644))
+
+		registry, err := mail.LoadRegistry(dir)
+		assert.NoError(t, err)
+
+		rendered, err := registry.Render(mail.TemplateVerifyEmail, "en", newTemplateData())
+		assert.NoError(t, err)
+		assert.Equal(t, "Custom", rendered.Subject)
+		assert.Equal(t, "<body><p>bob &amp; co</p></body>", rendered.HtmlBody)
+		assert.Equal(t, "bob & co\n", rendered.TextBody)
+	})
+
+	t.Run("Failure_DefaultLocaleMissing", func(t *testing.T) {
+		registry, err := mail.NewRegistry(fstest.MapFS{
+			"layout.tmpl":             {Data: []byte(`{{define "layout"}}{{end}}`)},
+			"zh-TW/verify_email.tmpl": {Data: []byte(`{{define "subject"}}{{end}}`)},
+		})
+		assert.EqualError(t, err, "mail templates of the default locale en not found")
+		assert.Nil(t, registry)
+	})
+}
+
+func TestHtmlToText(t *testing.T) {
+	htmlStr := `<html><head><title>Title</title><style>body { color: red; }</style></head>
+<body>
+    <h2>Hello Bob,</h2>
+    <p>Please <b>verify</b> your email:</p>
+    <p><a href="https://example.com/verify">Verify Email</a></p>
+    <p><a href="https://example.com/raw">https://example.com/raw</a></p>
+    <p>Best regards,<br>Your Team</p>
+</body></html>`
+
+	expected := "Hello Bob,\n\nPlease verify your email:\n\nVerify Email: https://example.com/verify\n\nhttps://example.com/raw\n\nBest regards,\nYour Team\n"
+	assert.Equal(t, expected, mail.HtmlToText(htmlStr))
+}
@@ -0,0 +1,174 @@
+package oauth
+
+import (
+	crand "crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"sort"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	flowCookieName = "oauth_flow"
+	flowCookiePath = "/v1/oauth/"
+	flowTTL        = 10 * time.Minute
+)
+
+// flowState is kept in an encrypted cookie between the redirect to the provider and the callback,
+// so that any instance of the gateway can finish the flow another one started.
+type flowState struct {
+	Provider  string `json:"p"`
+	State     string `json:"s"`
+	Nonce     string `json:"n"`
+	Verifier  string `json:"v"`
+	ExpiredAt int64  `json:"exp"`
+}
+
+// Flow runs the authorization code flow with state, nonce and PKCE for the configured providers
+type Flow struct {
+	providers map[string]Provider
+	key       string
+	secure    bool
+	now       func() time.Time
+}
+
+// NewFlow returns a flow encrypting its cookie with the key, secure marks the cookie HTTPS only
+func NewFlow(key string, secure bool, providers ...Provider) (*Flow, error) {
+	if key == "" {
+		return nil, errors.New("oauth state key is empty")
+	}
+
+	f := &Flow{
+		providers: make(map[string]Provider, len(providers)),
+		key:       key,
+		secure:    secure,
+		now:       time.Now,
+	}
+	for _, p := range providers {
+		f.providers[p.Name()] = p
+	}
+
+	return f, nil
+}
+
+// Providers returns the names of the configured providers
+func (f *Flow) Providers() []string {
+	names := make([]string, 0, len(f.providers))
+	for name := range f.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Start redirects to the consent page of the provider
+func (f *Flow) Start(w http.ResponseWriter, r *http.Request, providerName string) error {
+	provider, ok := f.providers[providerName]
+	if !ok {
+		return ErrUnknownProvider
+	}
+
+	state, err := randomToken()
+	if err != nil {
+		return err
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return err
+	}
+
+	fs := &flowState{
+		Provider:  providerName,
+		State:     state,
+		Nonce:     nonce,
+		Verifier:  oauth2.GenerateVerifier(),
+		ExpiredAt: f.now().Add(flowTTL).Unix(),
+	}
+	payload, err := json.Marshal(fs)
+	if err != nil {
+		return err
+	}
+	value, err := util.EncryptString(f.key, string(payload))
+	if err != nil {
+		return err
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     flowCookieName,
+		Value:    value,
+		Path:     flowCookiePath,
+		MaxAge:   int(flowTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   f.secure,
+		// Lax lets the cookie come back with the top level redirect from the provider
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, provider.AuthCodeURL(fs.State, fs.Nonce, fs.Verifier), http.StatusFound)
+
+	return nil
+}
+
+// Finish checks the state of the callback against the cookie, clears it and exchanges the code
+func (f *Flow) Finish(w http.ResponseWriter, r *http.Request, providerName string) (*Identity, error) {
+	provider, ok := f.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	cookie, err := r.Cookie(flowCookieName)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+
+	// The cookie is single use whatever the outcome
+	http.SetCookie(w, &http.Cookie{
+		Name:     flowCookieName,
+		Path:     flowCookiePath,
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   f.secure,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	payload, err := util.DecryptString(f.key, cookie.Value)
+	if err != nil {
+		return nil, ErrInvalidState
+	}
+	fs := &flowState{}
+	if err := json.Unmarshal([]byte(payload), fs); err != nil {
+		return nil, ErrInvalidState
+	}
+
+	query := r.URL.Query()
+	if fs.Provider != providerName || f.now().Unix() > fs.ExpiredAt ||
+		subtle.ConstantTimeCompare([]byte(fs.State), []byte(query.Get("state"))) != 1 {
+		return nil, ErrInvalidState
+	}
+
+	if errCode := query.Get("error"); errCode != "" {
+		return nil, errors.New("the provider refused the authorization: " + errCode)
+	}
+
+	code := query.Get("code")
+	if code == "" {
+		return nil, errors.New("missing authorization code")
+	}
+
+	return provider.Exchange(r.Context(), code, fs.Verifier, fs.Nonce)
+}
+
+func randomToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
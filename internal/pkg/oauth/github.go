@@ -0,0 +1,122 @@
+package oauth
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	gitHubAuthURL  = "https://github.com/login/oauth/authorize"
+	gitHubTokenURL = "https://github.com/login/oauth/access_token"
+	gitHubAPIURL   = "https://api.github.com"
+)
+
+type gitHubUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Name  string `json:"name"`
+}
+
+type gitHubEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+// GitHubProvider reads the identity from the API of GitHub, which issues no ID token,
+// so the nonce is not used and the state and PKCE protect the flow.
+type GitHubProvider struct {
+	name   string
+	config *oauth2.Config
+	apiURL string
+	client *http.Client
+}
+
+func NewGitHubProvider(cnf ProviderConfig) *GitHubProvider {
+	authURL, tokenURL, apiURL := cnf.AuthURL, cnf.TokenURL, cnf.APIURL
+	if authURL == "" {
+		authURL = gitHubAuthURL
+	}
+	if tokenURL == "" {
+		tokenURL = gitHubTokenURL
+	}
+	if apiURL == "" {
+		apiURL = gitHubAPIURL
+	}
+
+	scopes := cnf.Scopes
+	if len(scopes) == 0 {
+		scopes = []string{"read:user", "user:email"}
+	}
+
+	return &GitHubProvider{
+		name: cnf.Name,
+		config: &oauth2.Config{
+			ClientID:     cnf.ClientId,
+			ClientSecret: cnf.ClientSecret,
+			RedirectURL:  cnf.RedirectURL,
+			Scopes:       scopes,
+			Endpoint: oauth2.Endpoint{
+				AuthURL:   authURL,
+				TokenURL:  tokenURL,
+				AuthStyle: oauth2.AuthStyleInParams,
+			},
+		},
+		apiURL: strings.TrimRight(apiURL, "/"),
+		client: cnf.httpClient(),
+	}
+}
+
+func (p *GitHubProvider) Name() string {
+	return p.name
+}
+
+func (p *GitHubProvider) AuthCodeURL(state string, nonce string, verifier string) string {
+	return p.config.AuthCodeURL(state, oauth2.S256ChallengeOption(verifier))
+}
+
+func (p *GitHubProvider) Exchange(ctx context.Context, code string, verifier string, nonce string) (*Identity, error) {
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, p.client)
+	token, err := p.config.Exchange(ctx, code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange code: %w", err)
+	}
+
+	user := &gitHubUser{}
+	if err := getJSON(ctx, p.client, p.apiURL+"/user", token.AccessToken, user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("missing github user ID")
+	}
+
+	emails := []gitHubEmail{}
+	if err := getJSON(ctx, p.client, p.apiURL+"/user/emails", token.AccessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Provider: p.name,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+	}
+	if identity.Name == "" {
+		identity.Name = user.Login
+	}
+
+	// Only the primary address is used, the public one of the profile may not be verified
+	for _, email := range emails {
+		if email.Primary {
+			identity.Email = strings.ToLower(email.Email)
+			identity.EmailVerified = email.Verified
+			break
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,479 @@
+package oauth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"go-todolist-grpc/internal/pkg/oauth"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	mockClientId     = "todolist-client"
+	mockClientSecret = "todolist-secret"
+	mockRedirectURL  = "http://localhost:8642/v1/oauth/mock/callback"
+	mockStateKey     = "goToDoListgRPCOAuth"
+)
+
+type authRequest struct {
+	challenge string
+	nonce     string
+}
+
+// mockOIDCProvider is a local OIDC provider with an authorize, a token, a keys and a user API endpoint,
+// the tests tweak the ID token it signs to check every verification.
+type mockOIDCProvider struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	mu       sync.Mutex
+	requests map[string]authRequest
+	// Applied to the claims of the ID token before signing
+	tweakClaims func(claims jwt.MapClaims)
+	// Signs the ID token with another key than the published one
+	rogueKey *rsa.PrivateKey
+}
+
+func newMockOIDCProvider(t *testing.T) *mockOIDCProvider {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	m := &mockOIDCProvider{
+		key:      key,
+		kid:      "mock-key",
+		requests: map[string]authRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 m.server.URL,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("GET /jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": m.kid,
+				"use": "sig",
+				"alg": "RS256",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("GET /authorize", m.authorize)
+	mux.HandleFunc("GET /login/oauth/authorize", m.authorize)
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		req, ok := m.redeem(w, r)
+		if !ok {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": "mock-access-token",
+			"token_type":   "Bearer",
+			"expires_in":   3600,
+			"id_token":     m.signIDToken(t, req.nonce),
+		})
+	})
+	mux.HandleFunc("POST /login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := m.redeem(w, r); !ok {
+			return
+		}
+
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"access_token": "mock-access-token",
+			"token_type":   "bearer",
+			"scope":        "read:user,user:email",
+		})
+	})
+	mux.HandleFunc("GET /api/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mock-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"id":    583231,
+			"login": "octocat",
+			"name":  "",
+			"email": "public@example.com",
+		})
+	})
+	mux.HandleFunc("GET /api/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer mock-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		writeJSON(w, http.StatusOK, []map[string]interface{}{
+			{"email": "public@example.com", "primary": false, "verified": false},
+			{"email": "Octocat@Example.com", "primary": true, "verified": true},
+		})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+// authorize plays the user consenting at once and redirects back with a code
+func (m *mockOIDCProvider) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if query.Get("client_id") != mockClientId || query.Get("response_type") != "code" || query.Get("code_challenge_method") != "S256" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	code := "code-" + base64.RawURLEncoding.EncodeToString([]byte(query.Get("state")))[:16]
+	m.mu.Lock()
+	m.requests[code] = authRequest{challenge: query.Get("code_challenge"), nonce: query.Get("nonce")}
+	m.mu.Unlock()
+
+	redirect, _ := url.Parse(query.Get("redirect_uri"))
+	params := redirect.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirect.RawQuery = params.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+// redeem checks the client, the code and the PKCE verifier, a code is single use
+func (m *mockOIDCProvider) redeem(w http.ResponseWriter, r *http.Request) (authRequest, bool) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return authRequest{}, false
+	}
+
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok {
+		clientId, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientId != mockClientId || clientSecret != mockClientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return authRequest{}, false
+	}
+
+	m.mu.Lock()
+	req, ok := m.requests[r.PostForm.Get("code")]
+	delete(m.requests, r.PostForm.Get("code"))
+	m.mu.Unlock()
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" || base64.RawURLEncoding.EncodeToString(sum[:]) != req.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return authRequest{}, false
+	}
+
+	return req, true
+}
+
+func (m *mockOIDCProvider) signIDToken(t *testing.T, nonce string) string {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "110169484474386276334",
+		"aud":            mockClientId,
+		"exp":            now.Add(time.Hour).Unix(),
+		"iat":            now.Unix(),
+		"nonce":          nonce,
+		"email":          "Bob@Example.com",
+		"email_verified": true,
+		"name":           "Bob",
+	}
+	if m.tweakClaims != nil {
+		m.tweakClaims(claims)
+	}
+
+	key := m.key
+	if m.rogueKey != nil {
+		key = m.rogueKey
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, err := token.SignedString(key)
+	assert.NoError(t, err)
+
+	return signed
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func newOIDCFlow(t *testing.T, m *mockOIDCProvider) *oauth.Flow {
+	provider, err := oauth.NewProvider(context.Background(), oauth.ProviderConfig{
+		Name:         "mock",
+		Kind:         oauth.KindOIDC,
+		ClientId:     mockClientId,
+		ClientSecret: mockClientSecret,
+		RedirectURL:  mockRedirectURL,
+		Issuer:       m.server.URL,
+	})
+	assert.NoError(t, err)
+
+	flow, err := oauth.NewFlow(mockStateKey, false, provider)
+	assert.NoError(t, err)
+
+	return flow
+}
+
+// runFlow starts the flow, lets the mock provider authorize and returns the request of the callback
+func runFlow(t *testing.T, flow *oauth.Flow, providerName string, tweakAuthorizeURL func(query url.Values)) *http.Request {
+	rec := httptest.NewRecorder()
+	err := flow.Start(rec, httptest.NewRequest(http.MethodGet, "/v1/oauth/"+providerName+"/login", nil), providerName)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusFound, rec.Code)
+
+	authorizeURL, err := url.Parse(rec.Header().Get("Location"))
+	assert.NoError(t, err)
+	query := authorizeURL.Query()
+	assert.NotEmpty(t, query.Get("state"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	if tweakAuthorizeURL != nil {
+		tweakAuthorizeURL(query)
+	}
+	authorizeURL.RawQuery = query.Encode()
+
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	res, err := client.Get(authorizeURL.String())
+	assert.NoError(t, err)
+	defer res.Body.Close()
+	assert.Equal(t, http.StatusFound, res.StatusCode)
+
+	callback := httptest.NewRequest(http.MethodGet, res.Header.Get("Location"), nil)
+	for _, cookie := range rec.Result().Cookies() {
+		callback.AddCookie(cookie)
+	}
+
+	return callback
+}
+
+func TestOIDCFlow(t *testing.T) {
+	m := newMockOIDCProvider(t)
+	flow := newOIDCFlow(t, m)
+
+	t.Run("Success", func(t *testing.T) {
+		m.tweakClaims = nil
+
+		rec := httptest.NewRecorder()
+		identity, err := flow.Finish(rec, runFlow(t, flow, "mock", nil), "mock")
+		assert.NoError(t, err)
+		assert.Equal(t, &oauth.Identity{
+			Provider:      "mock",
+			Subject:       "110169484474386276334",
+			Email:         "bob@example.com",
+			EmailVerified: true,
+			Name:          "Bob",
+		}, identity)
+
+		// The cookie is cleared by the callback
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+		assert.Equal(t, -1, cookies[0].MaxAge)
+	})
+
+	t.Run("Success_EmailVerifiedString", func(t *testing.T) {
+		m.tweakClaims = func(claims jwt.MapClaims) {
+			claims["email_verified"] = "false"
+		}
+		defer func() { m.tweakClaims = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.NoError(t, err)
+		assert.False(t, identity.EmailVerified)
+	})
+
+	t.Run("Failure_StateMismatch", func(t *testing.T) {
+		callback := runFlow(t, flow, "mock", func(query url.Values) {
+			query.Set("state", "forged-state")
+		})
+
+		identity, err := flow.Finish(httptest.NewRecorder(), callback, "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidState)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_MissingCookie", func(t *testing.T) {
+		callback := runFlow(t, flow, "mock", nil)
+		callback.Header.Del("Cookie")
+
+		identity, err := flow.Finish(httptest.NewRecorder(), callback, "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidState)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_TamperedCookie", func(t *testing.T) {
+		callback := runFlow(t, flow, "mock", nil)
+		cookie, _ := callback.Cookie("oauth_flow")
+		callback.Header.Del("Cookie")
+		callback.AddCookie(&http.Cookie{Name: cookie.Name, Value: strings.ToUpper(cookie.Value)})
+
+		identity, err := flow.Finish(httptest.NewRecorder(), callback, "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidState)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_PKCE", func(t *testing.T) {
+		callback := runFlow(t, flow, "mock", func(query url.Values) {
+			query.Set("code_challenge", "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM")
+		})
+
+		identity, err := flow.Finish(httptest.NewRecorder(), callback, "mock")
+		assert.ErrorContains(t, err, "invalid_grant")
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_NonceMismatch", func(t *testing.T) {
+		m.tweakClaims = func(claims jwt.MapClaims) {
+			claims["nonce"] = "replayed-nonce"
+		}
+		defer func() { m.tweakClaims = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+		assert.ErrorContains(t, err, "nonce mismatch")
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_WrongAudience", func(t *testing.T) {
+		m.tweakClaims = func(claims jwt.MapClaims) {
+			claims["aud"] = "another-client"
+		}
+		defer func() { m.tweakClaims = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_WrongIssuer", func(t *testing.T) {
+		m.tweakClaims = func(claims jwt.MapClaims) {
+			claims["iss"] = "https://accounts.example.com"
+		}
+		defer func() { m.tweakClaims = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_Expired", func(t *testing.T) {
+		m.tweakClaims = func(claims jwt.MapClaims) {
+			claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		}
+		defer func() { m.tweakClaims = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_InvalidSignature", func(t *testing.T) {
+		rogueKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		assert.NoError(t, err)
+		m.rogueKey = rogueKey
+		defer func() { m.rogueKey = nil }()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "mock", nil), "mock")
+		assert.ErrorIs(t, err, oauth.ErrInvalidIDToken)
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_AccessDenied", func(t *testing.T) {
+		callback := runFlow(t, flow, "mock", nil)
+		query := callback.URL.Query()
+		query.Del("code")
+		query.Set("error", "access_denied")
+		callback.URL.RawQuery = query.Encode()
+
+		identity, err := flow.Finish(httptest.NewRecorder(), callback, "mock")
+		assert.EqualError(t, err, "the provider refused the authorization: access_denied")
+		assert.Nil(t, identity)
+	})
+
+	t.Run("Failure_UnknownProvider", func(t *testing.T) {
+		err := flow.Start(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/v1/oauth/unknown/login", nil), "unknown")
+		assert.ErrorIs(t, err, oauth.ErrUnknownProvider)
+	})
+}
+
+func TestNewOIDCProvider(t *testing.T) {
+	m := newMockOIDCProvider(t)
+
+	t.Run("Failure_IssuerMismatch", func(t *testing.T) {
+		provider, err := oauth.NewOIDCProvider(context.Background(), oauth.ProviderConfig{
+			Name:   "mock",
+			Kind:   oauth.KindOIDC,
+			Issuer: m.server.URL + "/tenant",
+		})
+		assert.Error(t, err)
+		assert.Nil(t, provider)
+	})
+
+	t.Run("Failure_UnknownKind", func(t *testing.T) {
+		provider, err := oauth.NewProvider(context.Background(), oauth.ProviderConfig{
+			Name: "mock",
+			Kind: "saml",
+		})
+		assert.ErrorIs(t, err, oauth.ErrUnknownProvider)
+		assert.Nil(t, provider)
+	})
+}
+
+func TestGitHubFlow(t *testing.T) {
+	m := newMockOIDCProvider(t)
+
+	provider, err := oauth.NewProvider(context.Background(), oauth.ProviderConfig{
+		Name:         "github",
+		Kind:         oauth.KindGitHub,
+		ClientId:     mockClientId,
+		ClientSecret: mockClientSecret,
+		RedirectURL:  "http://localhost:8642/v1/oauth/github/callback",
+		AuthURL:      m.server.URL + "/login/oauth/authorize",
+		TokenURL:     m.server.URL + "/login/oauth/access_token",
+		APIURL:       m.server.URL + "/api",
+	})
+	assert.NoError(t, err)
+
+	flow, err := oauth.NewFlow(mockStateKey, true, provider)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"github"}, flow.Providers())
+
+	t.Run("Success", func(t *testing.T) {
+		identity, err := flow.Finish(httptest.NewRecorder(), runFlow(t, flow, "github", nil), "github")
+		assert.NoError(t, err)
+		assert.Equal(t, &oauth.Identity{
+			Provider:      "github",
+			Subject:       "583231",
+			Email:         "octocat@example.com",
+			EmailVerified: true,
+			Name:          "octocat",
+		}, identity)
+	})
+}
@@ -0,0 +1,78 @@
+package oauth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+)
+
+// Kinds of provider, an OIDC provider proves the identity with a signed ID token,
+// GitHub only speaks OAuth2 so the identity is read from its API.
+const (
+	KindOIDC   = "oidc"
+	KindGitHub = "github"
+)
+
+var (
+	ErrUnknownProvider = errors.New("the oauth provider is not supported")
+	ErrInvalidState    = errors.New("the oauth state is invalid or has expired")
+	ErrInvalidIDToken  = errors.New("the id token is invalid")
+)
+
+// Identity is the account of the user at the provider
+type Identity struct {
+	Provider      string
+	Subject       string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+type Provider interface {
+	Name() string
+	// AuthCodeURL returns the URL of the consent page, the verifier is sent as its S256 challenge
+	AuthCodeURL(state string, nonce string, verifier string) string
+	// Exchange redeems the code with the verifier and returns the identity, the nonce is checked against the ID token
+	Exchange(ctx context.Context, code string, verifier string, nonce string) (*Identity, error)
+}
+
+type ProviderConfig struct {
+	Name         string
+	Kind         string
+	ClientId     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Issuer of an OIDC provider, the endpoints are discovered from it
+	Issuer string
+
+	// Endpoints of a GitHub provider, default to github.com
+	AuthURL  string
+	TokenURL string
+	APIURL   string
+
+	// Defaults to a client with a 10 seconds timeout
+	HTTPClient *http.Client
+}
+
+func (c ProviderConfig) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+
+	return &http.Client{Timeout: 10 * time.Second}
+}
+
+// NewProvider builds the provider of the kind, OIDC providers fetch their discovery document here
+func NewProvider(ctx context.Context, cnf ProviderConfig) (Provider, error) {
+	switch cnf.Kind {
+	case KindOIDC:
+		return NewOIDCProvider(ctx, cnf)
+	case KindGitHub:
+		return NewGitHubProvider(cnf), nil
+	default:
+		return nil, ErrUnknownProvider
+	}
+}
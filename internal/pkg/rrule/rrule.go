@@ -0,0 +1,352 @@
+// Package rrule implements the subset of the recurrence rules of RFC 5545 the tasks support:
+// FREQ of DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// The periods searched for the next occurrence before giving up, enough for any rule that matches at all
+const maxPeriods = 1000
+
+var ErrInvalidRule = errors.New("invalid recurrence rule")
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// WeekdayNum is a BYDAY value, N is the n-th weekday of the month counted from the end when negative,
+// 0 for every such weekday. Only a MONTHLY rule may have an N.
+type WeekdayNum struct {
+	Weekday time.Weekday
+	N       int
+}
+
+func (w WeekdayNum) String() string {
+	day := strings.ToUpper(w.Weekday.String()[:2])
+	if w.N == 0 {
+		return day
+	}
+
+	return strconv.Itoa(w.N) + day
+}
+
+type untilKind int
+
+const (
+	untilNone untilKind = iota
+	// A time in UTC, e.g. 20240131T090000Z
+	untilUTC
+	// A local time in the time zone of the occurrences, e.g. 20240131T090000
+	untilLocal
+	// A date in the time zone of the occurrences, the whole day included, e.g. 20240131
+	untilDate
+)
+
+const (
+	untilUTCLayout   = "20060102T150405Z"
+	untilLocalLayout = "20060102T150405"
+	untilDateLayout  = "20060102"
+)
+
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []WeekdayNum
+	// The number of occurrences, 0 for no limit
+	Count int
+
+	until     time.Time
+	untilKind untilKind
+}
+
+// Parse parses a rule such as FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10, the RRULE: prefix is optional
+func Parse(s string) (*Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalidRule)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(name)
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalidRule, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: duplicate %s", ErrInvalidRule, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(strings.ToUpper(value))
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				err = fmt.Errorf("unsupported FREQ %q", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(value)
+		case "COUNT":
+			rule.Count, err = parsePositive(value)
+		case "UNTIL":
+			err = rule.parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+		default:
+			err = fmt.Errorf("unsupported part %s", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidRule, err)
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalidRule)
+	}
+	if rule.Count > 0 && rule.untilKind != untilNone {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be used together", ErrInvalidRule)
+	}
+	for _, day := range rule.ByDay {
+		if day.N != 0 && rule.Freq != Monthly {
+			return nil, fmt.Errorf("%w: BYDAY %s is only allowed with FREQ=MONTHLY", ErrInvalidRule, day)
+		}
+	}
+
+	return rule, nil
+}
+
+func parsePositive(value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%q is not a positive number", value)
+	}
+
+	return n, nil
+}
+
+func parseByDay(value string) ([]WeekdayNum, error) {
+	days := make([]WeekdayNum, 0)
+	for _, item := range strings.Split(strings.ToUpper(value), ",") {
+		if len(item) < 2 {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+
+		weekday, ok := weekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("invalid BYDAY %q", item)
+		}
+
+		n := 0
+		if ordinal := item[:len(item)-2]; ordinal != "" {
+			var err error
+			n, err = strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("invalid BYDAY %q", item)
+			}
+		}
+
+		days = append(days, WeekdayNum{Weekday: weekday, N: n})
+	}
+
+	return days, nil
+}
+
+func (r *Rule) parseUntil(value string) error {
+	layouts := []struct {
+		layout string
+		kind   untilKind
+	}{
+		{untilUTCLayout, untilUTC},
+		{untilLocalLayout, untilLocal},
+		{untilDateLayout, untilDate},
+	}
+	for _, l := range layouts {
+		if t, err := time.Parse(l.layout, value); err == nil {
+			r.until = t
+			r.untilKind = l.kind
+			return nil
+		}
+	}
+
+	return fmt.Errorf("invalid UNTIL %q", value)
+}
+
+// String returns the rule in its canonical form
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, day := range r.ByDay {
+			days = append(days, day.String())
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	switch r.untilKind {
+	case untilUTC:
+		parts = append(parts, "UNTIL="+r.until.Format(untilUTCLayout))
+	case untilLocal:
+		parts = append(parts, "UNTIL="+r.until.Format(untilLocalLayout))
+	case untilDate:
+		parts = append(parts, "UNTIL="+r.until.Format(untilDateLayout))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// Next returns the occurrence that follows the index-th occurrence at the given time, ok is false once the rule ends.
+// The calendar is that of the location, so an occurrence keeps its wall clock time across daylight saving changes.
+func (r *Rule) Next(occurrence time.Time, index int, loc *time.Location) (next time.Time, ok bool) {
+	if r.Count > 0 && index >= r.Count {
+		return time.Time{}, false
+	}
+
+	start := occurrence.In(loc)
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+
+	for period := 0; period <= maxPeriods*interval; period += interval {
+		for _, candidate := range r.candidates(start, period, loc) {
+			if !candidate.After(start) {
+				continue
+			}
+			if !r.beforeUntil(candidate, loc) {
+				return time.Time{}, false
+			}
+
+			return candidate, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// candidates returns the occurrences of the period-th period from the one of start, in ascending order
+func (r *Rule) candidates(start time.Time, period int, loc *time.Location) []time.Time {
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), 0, loc)
+	}
+
+	switch r.Freq {
+	case Daily:
+		day := at(start.Year(), start.Month(), start.Day()+period)
+		if len(r.ByDay) > 0 && !r.hasWeekday(day.Weekday()) {
+			return nil
+		}
+		return []time.Time{day}
+
+	case Weekly:
+		// Weeks start on Monday
+		offset := (int(start.Weekday()) + 6) % 7
+		monday := start.Day() - offset + 7*period
+
+		days := r.ByDay
+		if len(days) == 0 {
+			days = []WeekdayNum{{Weekday: start.Weekday()}}
+		}
+		candidates := make([]time.Time, 0, len(days))
+		for _, day := range days {
+			candidates = append(candidates, at(start.Year(), start.Month(), monday+(int(day.Weekday)+6)%7))
+		}
+		sortTimes(candidates)
+		return candidates
+
+	case Monthly:
+		first := at(start.Year(), start.Month()+time.Month(period), 1)
+		year, month := first.Year(), first.Month()
+		daysInMonth := time.Date(year, month+1, 0, 0, 0, 0, 0, loc).Day()
+
+		// A month without the day of the month is skipped, as RFC 5545 requires
+		if len(r.ByDay) == 0 {
+			if start.Day() > daysInMonth {
+				return nil
+			}
+			return []time.Time{at(year, month, start.Day())}
+		}
+
+		candidates := make([]time.Time, 0)
+		for _, day := range r.ByDay {
+			matches := make([]int, 0, 5)
+			for d := 1; d <= daysInMonth; d++ {
+				if time.Date(year, month, d, 0, 0, 0, 0, loc).Weekday() == day.Weekday {
+					matches = append(matches, d)
+				}
+			}
+
+			switch {
+			case day.N == 0:
+				for _, d := range matches {
+					candidates = append(candidates, at(year, month, d))
+				}
+			case day.N > 0 && day.N <= len(matches):
+				candidates = append(candidates, at(year, month, matches[day.N-1]))
+			case day.N < 0 && -day.N <= len(matches):
+				candidates = append(candidates, at(year, month, matches[len(matches)+day.N]))
+			}
+		}
+		sortTimes(candidates)
+		return candidates
+	}
+
+	return nil
+}
+
+func (r *Rule) hasWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r *Rule) beforeUntil(t time.Time, loc *time.Location) bool {
+	switch r.untilKind {
+	case untilUTC:
+		return !t.After(r.until)
+	case untilLocal:
+		until := r.until
+		return !t.After(time.Date(until.Year(), until.Month(), until.Day(), until.Hour(), until.Minute(), until.Second(), 0, loc))
+	case untilDate:
+		until := r.until
+		return t.Before(time.Date(until.Year(), until.Month(), until.Day()+1, 0, 0, 0, 0, loc))
+	default:
+		return true
+	}
+}
+
+func sortTimes(times []time.Time) {
+	sort.Slice(times, func(i, j int) bool {
+		return times[i].Before(times[j])
+	})
+}
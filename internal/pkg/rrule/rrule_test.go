@@ -0,0 +1,154 @@
+package rrule_test
+
+import (
+	"go-todolist-grpc/internal/pkg/rrule"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		cases := map[string]string{
+			"FREQ=DAILY":                            "FREQ=DAILY",
+			"RRULE:freq=weekly;byday=mo,we":         "FREQ=WEEKLY;BYDAY=MO,WE",
+			"FREQ=MONTHLY;BYDAY=-1FR;COUNT=3":       "FREQ=MONTHLY;BYDAY=-1FR;COUNT=3",
+			"FREQ=WEEKLY;INTERVAL=2;UNTIL=20240131": "FREQ=WEEKLY;INTERVAL=2;UNTIL=20240131",
+			"FREQ=DAILY;UNTIL=20240131T090000Z":     "FREQ=DAILY;UNTIL=20240131T090000Z",
+			"FREQ=DAILY;INTERVAL=1":                 "FREQ=DAILY",
+		}
+		for input, expected := range cases {
+			rule, err := rrule.Parse(input)
+			assert.NoError(t, err, input)
+			assert.Equal(t, expected, rule.String())
+		}
+	})
+
+	t.Run("Failure", func(t *testing.T) {
+		inputs := []string{
+			"",
+			"INTERVAL=2",
+			"FREQ=YEARLY",
+			"FREQ=DAILY;INTERVAL=0",
+			"FREQ=DAILY;COUNT=abc",
+			"FREQ=DAILY;FREQ=WEEKLY",
+			"FREQ=DAILY;BYMONTH=1",
+			"FREQ=DAILY;COUNT=3;UNTIL=20240131",
+			"FREQ=DAILY;UNTIL=2024-01-31",
+			"FREQ=WEEKLY;BYDAY=XX",
+			"FREQ=WEEKLY;BYDAY=1MO",
+			"FREQ=MONTHLY;BYDAY=6MO",
+			"FREQ=DAILY;",
+		}
+		for _, input := range inputs {
+			rule, err := rrule.Parse(input)
+			assert.ErrorIs(t, err, rrule.ErrInvalidRule, input)
+			assert.Nil(t, rule)
+		}
+	})
+}
+
+func TestNext(t *testing.T) {
+	taipei, _ := time.LoadLocation("Asia/Taipei")
+	newYork, _ := time.LoadLocation("America/New_York")
+
+	// occurrences returns the n occurrences that follow start
+	occurrences := func(t *testing.T, s string, start time.Time, loc *time.Location, n int) []time.Time {
+		rule, err := rrule.Parse(s)
+		assert.NoError(t, err)
+
+		result := make([]time.Time, 0, n)
+		current := start
+		for i := 1; i <= n; i++ {
+			next, ok := rule.Next(current, i, loc)
+			if !ok {
+				break
+			}
+			result = append(result, next)
+			current = next
+		}
+		return result
+	}
+
+	t.Run("Success_Daily", func(t *testing.T) {
+		start := time.Date(2024, 1, 30, 9, 0, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 2, 1, 9, 0, 0, 0, taipei),
+			time.Date(2024, 2, 3, 9, 0, 0, 0, taipei),
+		}, occurrences(t, "FREQ=DAILY;INTERVAL=2", start, taipei, 2))
+	})
+
+	t.Run("Success_DailyByDay", func(t *testing.T) {
+		// Friday
+		start := time.Date(2024, 1, 5, 9, 0, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 1, 8, 9, 0, 0, 0, taipei),
+			time.Date(2024, 1, 9, 9, 0, 0, 0, taipei),
+		}, occurrences(t, "FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", start, taipei, 2))
+	})
+
+	t.Run("Success_Weekly", func(t *testing.T) {
+		// Wednesday
+		start := time.Date(2024, 1, 3, 18, 30, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 1, 10, 18, 30, 0, 0, taipei),
+			time.Date(2024, 1, 17, 18, 30, 0, 0, taipei),
+		}, occurrences(t, "FREQ=WEEKLY", start, taipei, 2))
+	})
+
+	t.Run("Success_WeeklyByDay", func(t *testing.T) {
+		// Wednesday
+		start := time.Date(2024, 1, 3, 9, 0, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 1, 5, 9, 0, 0, 0, taipei),
+			time.Date(2024, 1, 15, 9, 0, 0, 0, taipei),
+			time.Date(2024, 1, 19, 9, 0, 0, 0, taipei),
+		}, occurrences(t, "FREQ=WEEKLY;INTERVAL=2;BYDAY=FR,MO", start, taipei, 3))
+	})
+
+	t.Run("Success_MonthlySkipsShortMonths", func(t *testing.T) {
+		start := time.Date(2024, 1, 31, 9, 0, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 3, 31, 9, 0, 0, 0, taipei),
+			time.Date(2024, 5, 31, 9, 0, 0, 0, taipei),
+		}, occurrences(t, "FREQ=MONTHLY", start, taipei, 2))
+	})
+
+	t.Run("Success_MonthlyByDay", func(t *testing.T) {
+		start := time.Date(2024, 1, 26, 9, 0, 0, 0, taipei)
+		assert.Equal(t, []time.Time{
+			time.Date(2024, 2, 13, 9, 0, 0, 0, taipei),
+			time.Date(2024, 2, 23, 9, 0, 0, 0, taipei),
+			time.Date(2024, 3, 12, 9, 0, 0, 0, taipei),
+		}, occurrences(t, "FREQ=MONTHLY;BYDAY=2TU,-1FR", start, taipei, 3))
+	})
+
+	t.Run("Success_KeepsWallClockAcrossDST", func(t *testing.T) {
+		// Daylight saving time starts on 2024-03-10 in New York
+		start := time.Date(2024, 3, 9, 9, 0, 0, 0, newYork)
+		next := occurrences(t, "FREQ=DAILY", start, newYork, 1)
+		assert.Equal(t, []time.Time{time.Date(2024, 3, 10, 9, 0, 0, 0, newYork)}, next)
+		assert.Equal(t, 23*time.Hour, next[0].Sub(start))
+	})
+
+	t.Run("Success_UsesLocationCalendar", func(t *testing.T) {
+		// 2024-01-31 23:00 in Taipei is still 2024-01-31 in UTC but the rule follows Taipei
+		start := time.Date(2024, 1, 31, 23, 0, 0, 0, taipei)
+		next := occurrences(t, "FREQ=MONTHLY", start.UTC(), taipei, 1)
+		assert.Equal(t, []time.Time{time.Date(2024, 3, 31, 23, 0, 0, 0, taipei)}, next)
+	})
+
+	t.Run("Success_Count", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 9, 0, 0, 0, taipei)
+		// The start is the first of the three occurrences
+		assert.Len(t, occurrences(t, "FREQ=DAILY;COUNT=3", start, taipei, 10), 2)
+	})
+
+	t.Run("Success_Until", func(t *testing.T) {
+		start := time.Date(2024, 1, 1, 9, 0, 0, 0, taipei)
+		assert.Len(t, occurrences(t, "FREQ=DAILY;UNTIL=20240103", start, taipei, 10), 2)
+		assert.Len(t, occurrences(t, "FREQ=DAILY;UNTIL=20240103T085959", start, taipei, 10), 1)
+		assert.Len(t, occurrences(t, "FREQ=DAILY;UNTIL=20240103T010000Z", start, taipei, 10), 2)
+	})
+}
@@ -0,0 +1,57 @@
+package util
+
+import (
+	crand "crypto/rand"
+	"encoding/base64"
+	"strings"
+)
+
+// Scopes of a personal API token
+const (
+	ScopeTasksRead  = "tasks:read"
+	ScopeTasksWrite = "tasks:write"
+)
+
+const (
+	// ApiTokenPrefix tells an API token apart from a JWT in the Authorization header
+	ApiTokenPrefix = "tdl_"
+	// Characters kept in the clear after the prefix, so that the owner can recognise a token
+	apiTokenHintLength = 8
+)
+
+// ApiScopes lists the scopes a token can be granted
+var ApiScopes = []string{ScopeTasksRead, ScopeTasksWrite}
+
+// GenerateApiToken returns a new token with 256 bits of randomness
+func GenerateApiToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return ApiTokenPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func IsApiToken(token string) bool {
+	return strings.HasPrefix(token, ApiTokenPrefix)
+}
+
+// ApiTokenHint returns the beginning of the token that is stored in the clear
+func ApiTokenHint(token string) string {
+	if len(token) <= len(ApiTokenPrefix)+apiTokenHintLength {
+		return token
+	}
+
+	return token[:len(ApiTokenPrefix)+apiTokenHintLength]
+}
+
+// HasScope reports whether the scopes grant the scope, writing tasks implies reading them
+func HasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope || (s == ScopeTasksWrite && scope == ScopeTasksRead) {
+			return true
+		}
+	}
+
+	return false
+}
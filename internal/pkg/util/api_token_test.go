@@ -0,0 +1,38 @@
+package util_test
+
+import (
+	"go-todolist-grpc/internal/pkg/util"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateApiToken(t *testing.T) {
+	token, err := util.GenerateApiToken()
+	assert.NoError(t, err)
+	assert.True(t, util.IsApiToken(token))
+	assert.Len(t, token, len(util.ApiTokenPrefix)+43)
+
+	other, err := util.GenerateApiToken()
+	assert.NoError(t, err)
+	assert.NotEqual(t, token, other)
+
+	hint := util.ApiTokenHint(token)
+	assert.Len(t, hint, len(util.ApiTokenPrefix)+8)
+	assert.True(t, strings.HasPrefix(token, hint))
+}
+
+func TestIsApiToken(t *testing.T) {
+	assert.True(t, util.IsApiToken("tdl_abc"))
+	assert.False(t, util.IsApiToken("eyJhbGciOiJIUzI1NiJ9.e30.sig"))
+	assert.False(t, util.IsApiToken(""))
+}
+
+func TestHasScope(t *testing.T) {
+	assert.True(t, util.HasScope([]string{util.ScopeTasksRead}, util.ScopeTasksRead))
+	assert.False(t, util.HasScope([]string{util.ScopeTasksRead}, util.ScopeTasksWrite))
+	assert.True(t, util.HasScope([]string{util.ScopeTasksWrite}, util.ScopeTasksRead))
+	assert.True(t, util.HasScope([]string{util.ScopeTasksWrite}, util.ScopeTasksWrite))
+	assert.False(t, util.HasScope(nil, util.ScopeTasksRead))
+}
@@ -0,0 +1,52 @@
+package util_test
+
+import (
+	"go-todolist-grpc/internal/pkg/util"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptString(t *testing.T) {
+	key := "goToDoListgRPCTotp"
+
+	t.Run("Success", func(t *testing.T) {
+		encrypted, err := util.EncryptString(key, "JBSWY3DPEHPK3PXP")
+		assert.NoError(t, err)
+		assert.NotContains(t, encrypted, "JBSWY3DPEHPK3PXP")
+
+		// Every encryption uses a fresh nonce
+		again, err := util.EncryptString(key, "JBSWY3DPEHPK3PXP")
+		assert.NoError(t, err)
+		assert.NotEqual(t, encrypted, again)
+
+		decrypted, err := util.DecryptString(key, encrypted)
+		assert.NoError(t, err)
+		assert.Equal(t, "JBSWY3DPEHPK3PXP", decrypted)
+	})
+
+	t.Run("Failure_WrongKey", func(t *testing.T) {
+		encrypted, err := util.EncryptString(key, "JBSWY3DPEHPK3PXP")
+		assert.NoError(t, err)
+
+		decrypted, err := util.DecryptString("another-key", encrypted)
+		assert.ErrorIs(t, err, util.ErrDecryptFailed)
+		assert.Empty(t, decrypted)
+	})
+
+	t.Run("Failure_Tampered", func(t *testing.T) {
+		decrypted, err := util.DecryptString(key, "bm90IGEgdmFsaWQgdmFsdWU=")
+		assert.ErrorIs(t, err, util.ErrDecryptFailed)
+		assert.Empty(t, decrypted)
+
+		decrypted, err = util.DecryptString(key, "%%%")
+		assert.ErrorIs(t, err, util.ErrDecryptFailed)
+		assert.Empty(t, decrypted)
+	})
+
+	t.Run("Failure_EmptyKey", func(t *testing.T) {
+		encrypted, err := util.EncryptString("", "JBSWY3DPEHPK3PXP")
+		assert.EqualError(t, err, "encryption key is empty")
+		assert.Empty(t, encrypted)
+	})
+}
@@ -1,6 +1,10 @@
 package util
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -15,3 +19,16 @@ func CheckPasswordHash(password string, hashPassword string) bool {
 
 	return err == nil
 }
+
+// HashSecretCode returns the hex encoded HMAC-SHA256 of a secret code, only the hash is stored
+func HashSecretCode(key string, code string) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(code))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// CheckSecretCodeHash compares the code with a stored hash in constant time
+func CheckSecretCodeHash(key string, code string, hash string) bool {
+	return hmac.Equal([]byte(HashSecretCode(key, code)), []byte(hash))
+}
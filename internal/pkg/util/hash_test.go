@@ -51,3 +51,32 @@ func TestCheckPasswordHash(t *testing.T) {
 		assert.False(t, isValid)
 	})
 }
+
+func TestHashSecretCode(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		hash := util.HashSecretCode("key", "secretcode")
+		assert.Len(t, hash, 64)
+		assert.NotContains(t, hash, "secretcode")
+		assert.Equal(t, hash, util.HashSecretCode("key", "secretcode"))
+	})
+
+	t.Run("Success_KeyMatters", func(t *testing.T) {
+		assert.NotEqual(t, util.HashSecretCode("key", "secretcode"), util.HashSecretCode("otherkey", "secretcode"))
+	})
+}
+
+func TestCheckSecretCodeHash(t *testing.T) {
+	hash := util.HashSecretCode("key", "secretcode")
+
+	t.Run("Success", func(t *testing.T) {
+		assert.True(t, util.CheckSecretCodeHash("key", "secretcode", hash))
+	})
+
+	t.Run("Failure_WrongCode", func(t *testing.T) {
+		assert.False(t, util.CheckSecretCodeHash("key", "othercode", hash))
+	})
+
+	t.Run("Failure_WrongKey", func(t *testing.T) {
+		assert.False(t, util.CheckSecretCodeHash("otherkey", "secretcode", hash))
+	})
+}
@@ -0,0 +1,299 @@
+package util
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	SigningMethodHS256 = "HS256"
+	SigningMethodRS256 = "RS256"
+	SigningMethodEdDSA = "EdDSA"
+)
+
+var keySetIns *KeySet
+
+// KeySet holds the key used to sign new tokens and every key that is still accepted for verification,
+// so that a key can be rotated out of signing while the tokens it issued remain valid until they expire.
+type KeySet struct {
+	method     jwt.SigningMethod
+	signingKid string
+	signingKey interface{}
+	verifyKeys map[string]crypto.PublicKey
+	secretKey  []byte
+}
+
+type KeySetOption struct {
+	// HS256, RS256 or EdDSA, defaults to HS256
+	SigningMethod string
+	// Shared secret, only used by HS256
+	SecretKey string
+	// Key ID of the private key used for signing
+	SigningKeyId string
+	// Directory of PEM files named <kid>.pem, private keys sign and verify, public keys only verify
+	KeyDir string
+	// Additional PEM files in the form of kid=path separated by commas
+	PublicKeyPaths string
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+func InitKeySet(opt *KeySetOption) error {
+	keySet, err := LoadKeySet(opt)
+	if err != nil {
+		return err
+	}
+
+	keySetIns = keySet
+
+	return nil
+}
+
+func GetKeySet() *KeySet {
+	return keySetIns
+}
+
+func NewHMACKeySet(secretKey string, kid string) *KeySet {
+	return &KeySet{
+		method:     jwt.SigningMethodHS256,
+		signingKid: kid,
+		secretKey:  []byte(secretKey),
+	}
+}
+
+func LoadKeySet(opt *KeySetOption) (*KeySet, error) {
+	switch opt.SigningMethod {
+	case "", SigningMethodHS256:
+		if opt.SecretKey == "" {
+			return nil, errors.New("jwt secret key is required for HS256")
+		}
+		return NewHMACKeySet(opt.SecretKey, opt.SigningKeyId), nil
+	case SigningMethodRS256, SigningMethodEdDSA:
+	default:
+		return nil, fmt.Errorf("unsupported jwt signing method: %s", opt.SigningMethod)
+	}
+
+	keySet := &KeySet{
+		method:     jwt.GetSigningMethod(opt.SigningMethod),
+		signingKid: opt.SigningKeyId,
+		verifyKeys: make(map[string]crypto.PublicKey),
+	}
+	privateKeys := make(map[string]interface{})
+
+	addKey := func(kid string, path string) error {
+		if _, ok := keySet.verifyKeys[kid]; ok {
+			return fmt.Errorf("duplicate jwt key ID: %s", kid)
+		}
+
+		privateKey, publicKey, err := readPemKey(path)
+		if err != nil {
+			return fmt.Errorf("failed to load jwt key %s: %w", path, err)
+		}
+
+		if privateKey != nil {
+			privateKeys[kid] = privateKey
+		}
+		keySet.verifyKeys[kid] = publicKey
+
+		return nil
+	}
+
+	if opt.KeyDir != "" {
+		paths, err := filepath.Glob(filepath.Join(opt.KeyDir, "*.pem"))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, path := range paths {
+			kid := strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".pem"), ".pub")
+			if err := addKey(kid, path); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for _, item := range strings.Split(opt.PublicKeyPaths, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		kid, path, ok := strings.Cut(item, "=")
+		if !ok || kid == "" || path == "" {
+			return nil, fmt.Errorf("invalid jwt public key entry: %s", item)
+		}
+
+		if err := addKey(kid, path); err != nil {
+			return nil, err
+		}
+	}
+
+	// Pick the signing key
+	signingKey, ok := privateKeys[opt.SigningKeyId]
+	if !ok {
+		return nil, fmt.Errorf("jwt signing key not found: %s", opt.SigningKeyId)
+	}
+	if !isKeyForMethod(keySet.method, keySet.verifyKeys[opt.SigningKeyId]) {
+		return nil, fmt.Errorf("jwt signing key %s does not match method %s", opt.SigningKeyId, opt.SigningMethod)
+	}
+	keySet.signingKey = signingKey
+
+	return keySet, nil
+}
+
+// readPemKey reads a PKCS#8, PKCS#1 or PKIX encoded key, a private key also returns its public key.
+func readPemKey(path string) (interface{}, crypto.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, nil, errors.New("no PEM data found")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			return k, &k.PublicKey, nil
+		case ed25519.PrivateKey:
+			return k, k.Public(), nil
+		}
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return key, &key.PublicKey, nil
+	case "PUBLIC KEY":
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch k := key.(type) {
+		case *rsa.PublicKey, ed25519.PublicKey:
+			return nil, k, nil
+		}
+	case "RSA PUBLIC KEY":
+		key, err := x509.ParsePKCS1PublicKey(block.Bytes)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, key, nil
+	}
+
+	return nil, nil, fmt.Errorf("unsupported key type: %s", block.Type)
+}
+
+func isKeyForMethod(method jwt.SigningMethod, key crypto.PublicKey) bool {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return method == jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		return method == jwt.SigningMethodEdDSA
+	}
+
+	return false
+}
+
+func (ks *KeySet) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(ks.method, claims)
+	if ks.signingKid != "" {
+		token.Header["kid"] = ks.signingKid
+	}
+
+	if ks.secretKey != nil {
+		return token.SignedString(ks.secretKey)
+	}
+
+	return token.SignedString(ks.signingKey)
+}
+
+func (ks *KeySet) keyFunc(token *jwt.Token) (interface{}, error) {
+	if ks.secretKey != nil {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("invalid signing method: %v", token.Header["alg"])
+		}
+
+		return ks.secretKey, nil
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := ks.verifyKeys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key ID: %s", kid)
+	}
+
+	// The algorithm must match the key, never trust the alg header alone
+	if !isKeyForMethod(token.Method, key) {
+		return nil, fmt.Errorf("invalid signing method: %v", token.Header["alg"])
+	}
+
+	return key, nil
+}
+
+// JWKS returns the public verification keys, a shared secret is never published.
+func (ks *KeySet) JWKS() JWKSet {
+	set := JWKSet{Keys: make([]JWK, 0, len(ks.verifyKeys))}
+
+	for kid, key := range ks.verifyKeys {
+		switch k := key.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: SigningMethodRS256,
+				N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: SigningMethodEdDSA,
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(k),
+			})
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].Kid < set.Keys[j].Kid
+	})
+
+	return set
+}
@@ -0,0 +1,227 @@
+package util_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"go-todolist-grpc/internal/pkg/util"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func writePrivateKey(t *testing.T, dir string, name string, key interface{}) {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	assert.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+}
+
+func writePublicKey(t *testing.T, dir string, name string, key interface{}) {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	assert.NoError(t, err)
+
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0644))
+}
+
+func TestLoadKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	t.Run("Success_RS256", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "rsa-1.pem", rsaKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "rsa-1",
+			KeyDir:        dir,
+		})
+		assert.NoError(t, err)
+
+		token, _, err := util.GenerateToken(15, keySet, util.TokenTypeAccess, 123, util.RoleUser)
+		assert.NoError(t, err)
+
+		parsed, _, err := jwt.NewParser().ParseUnverified(token, &util.CustomClaims{})
+		assert.NoError(t, err)
+		assert.Equal(t, "RS256", parsed.Header["alg"])
+		assert.Equal(t, "rsa-1", parsed.Header["kid"])
+
+		claims, err := util.ParseToken(keySet, token)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, claims.UserID)
+	})
+
+	t.Run("Success_EdDSA", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "ed-1.pem", edKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodEdDSA,
+			SigningKeyId:  "ed-1",
+			KeyDir:        dir,
+		})
+		assert.NoError(t, err)
+
+		token, _, err := util.GenerateToken(15, keySet, util.TokenTypeAccess, 123, util.RoleUser)
+		assert.NoError(t, err)
+
+		claims, err := util.ParseToken(keySet, token)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, claims.UserID)
+	})
+
+	t.Run("Success_KeyRotation", func(t *testing.T) {
+		oldDir := t.TempDir()
+		writePrivateKey(t, oldDir, "rsa-1.pem", rsaKey)
+
+		oldKeySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "rsa-1",
+			KeyDir:        oldDir,
+		})
+		assert.NoError(t, err)
+
+		oldToken, _, err := util.GenerateToken(15, oldKeySet, util.TokenTypeAccess, 123, util.RoleUser)
+		assert.NoError(t, err)
+
+		// Sign with the new key while the retired public key still verifies
+		newDir := t.TempDir()
+		writePrivateKey(t, newDir, "ed-1.pem", edKey)
+		writePublicKey(t, newDir, "rsa-1.pub.pem", &rsaKey.PublicKey)
+
+		newKeySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodEdDSA,
+			SigningKeyId:  "ed-1",
+			KeyDir:        newDir,
+		})
+		assert.NoError(t, err)
+
+		claims, err := util.ParseToken(newKeySet, oldToken)
+		assert.NoError(t, err)
+		assert.Equal(t, 123, claims.UserID)
+
+		jwks := newKeySet.JWKS()
+		assert.Len(t, jwks.Keys, 2)
+		assert.Equal(t, "ed-1", jwks.Keys[0].Kid)
+		assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+		assert.Equal(t, "Ed25519", jwks.Keys[0].Crv)
+		assert.Equal(t, "rsa-1", jwks.Keys[1].Kid)
+		assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+		assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	})
+
+	t.Run("Success_PublicKeyPaths", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "ed-1.pem", edKey)
+		extraDir := t.TempDir()
+		writePublicKey(t, extraDir, "legacy.pem", &rsaKey.PublicKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod:  util.SigningMethodEdDSA,
+			SigningKeyId:   "ed-1",
+			KeyDir:         dir,
+			PublicKeyPaths: "rsa-legacy=" + filepath.Join(extraDir, "legacy.pem"),
+		})
+		assert.NoError(t, err)
+		assert.Len(t, keySet.JWKS().Keys, 2)
+	})
+
+	t.Run("Success_HS256NotPublished", func(t *testing.T) {
+		keySet, err := util.LoadKeySet(&util.KeySetOption{SecretKey: "mysecretkey"})
+		assert.NoError(t, err)
+		assert.Empty(t, keySet.JWKS().Keys)
+	})
+
+	t.Run("Failure_UnknownKeyID", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "rsa-1.pem", rsaKey)
+		otherDir := t.TempDir()
+		writePrivateKey(t, otherDir, "ed-1.pem", edKey)
+
+		signer, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodEdDSA,
+			SigningKeyId:  "ed-1",
+			KeyDir:        otherDir,
+		})
+		assert.NoError(t, err)
+		verifier, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "rsa-1",
+			KeyDir:        dir,
+		})
+		assert.NoError(t, err)
+
+		token, _, err := util.GenerateToken(15, signer, util.TokenTypeAccess, 123, util.RoleUser)
+		assert.NoError(t, err)
+
+		claims, err := util.ParseToken(verifier, token)
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Failure_HS256WithPublicKey", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "rsa-1.pem", rsaKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "rsa-1",
+			KeyDir:        dir,
+		})
+		assert.NoError(t, err)
+
+		// A token signed with HMAC using the public key as secret must be rejected
+		publicDer, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+		assert.NoError(t, err)
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, &util.CustomClaims{UserID: 1, Role: util.RoleAdmin})
+		forged.Header["kid"] = "rsa-1"
+		forgedStr, err := forged.SignedString(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDer}))
+		assert.NoError(t, err)
+
+		claims, err := util.ParseToken(keySet, forgedStr)
+		assert.Error(t, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Failure_MissingSigningKey", func(t *testing.T) {
+		dir := t.TempDir()
+		writePublicKey(t, dir, "rsa-1.pub.pem", &rsaKey.PublicKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "rsa-1",
+			KeyDir:        dir,
+		})
+		assert.EqualError(t, err, "jwt signing key not found: rsa-1")
+		assert.Nil(t, keySet)
+	})
+
+	t.Run("Failure_MethodMismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		writePrivateKey(t, dir, "ed-1.pem", edKey)
+
+		keySet, err := util.LoadKeySet(&util.KeySetOption{
+			SigningMethod: util.SigningMethodRS256,
+			SigningKeyId:  "ed-1",
+			KeyDir:        dir,
+		})
+		assert.EqualError(t, err, "jwt signing key ed-1 does not match method RS256")
+		assert.Nil(t, keySet)
+	})
+
+	t.Run("Failure_UnsupportedMethod", func(t *testing.T) {
+		keySet, err := util.LoadKeySet(&util.KeySetOption{SigningMethod: "none"})
+		assert.EqualError(t, err, "unsupported jwt signing method: none")
+		assert.Nil(t, keySet)
+	})
+}
@@ -2,42 +2,87 @@ package util
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Permissions granted to the roles in the role_permissions table, access tokens carry those of the role
+const (
+	PermissionAccount    = "account"
+	PermissionTasksRead  = "tasks:read"
+	PermissionTasksWrite = "tasks:write"
+	PermissionUsersRead  = "users:read"
+	PermissionUsersWrite = "users:write"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// Returned by Login when 2FA is enabled, only accepted by LoginTOTP
+	TokenTypeTotpChallenge = "totp_challenge"
+	// Claims of a personal API token, built by the middleware from the database rather than signed
+	TokenTypeApi = "api"
 )
 
 type CustomClaims struct {
-	UserID int `json:"user_id"`
+	UserID      int      `json:"user_id"`
+	Role        string   `json:"role"`
+	TokenType   string   `json:"token_type"`
+	Permissions []string `json:"permissions,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(jwtTTL int, jwtSecretKey string, userID int) (string, error) {
+func (c *CustomClaims) IsAdmin() bool {
+	return c.Role == RoleAdmin
+}
+
+func (c *CustomClaims) HasPermission(permission string) bool {
+	for _, p := range c.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GenerateToken signs a token of the given type, every token gets a unique ID (jti) for revocation.
+// The permissions of the role are embedded so that the middleware can authorize without a query.
+func GenerateToken(jwtTTL int, keySet *KeySet, tokenType string, userID int, role string, permissions ...string) (string, *CustomClaims, error) {
 	now := time.Now()
 	ttl := time.Minute * time.Duration(jwtTTL)
 
 	claims := &CustomClaims{
-		userID,
-		jwt.RegisteredClaims{
+		UserID:      userID,
+		Role:        role,
+		TokenType:   tokenType,
+		Permissions: permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
 			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			Issuer:    "go-todolist-grpc",
 		},
 	}
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
-	return token.SignedString([]byte(jwtSecretKey))
-}
+	tokenStr, err := keySet.sign(claims)
+	if err != nil {
+		return "", nil, err
+	}
 
-func ParseToken(jwtSecretKey string, tokenStr string) (*CustomClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("invalid signing method: %v", token.Header["alg"])
-		}
+	return tokenStr, claims, nil
+}
 
-		return []byte(jwtSecretKey), nil
-	})
+func ParseToken(keySet *KeySet, tokenStr string) (*CustomClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenStr, &CustomClaims{}, keySet.keyFunc)
 	if err != nil {
 		return nil, err
 	}
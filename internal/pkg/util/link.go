@@ -0,0 +1,138 @@
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Purposes of a signed link, a token is only accepted by the endpoint it was built for
+const (
+	LinkPurposeVerifyEmail   = "verify_email"
+	LinkPurposeResetPassword = "reset_password"
+)
+
+var (
+	ErrInvalidLink = errors.New("the link is invalid")
+	ErrLinkExpired = errors.New("the link has expired")
+)
+
+// LinkClaims is the payload carried by the token query parameter of a mail link
+type LinkClaims struct {
+	Purpose    string `json:"p"`
+	ID         int    `json:"id"`
+	SecretCode string `json:"sc"`
+	ExpiredAt  int64  `json:"exp"`
+}
+
+// LinkBuilder builds the links put into outgoing mail from the public base URL of the service
+type LinkBuilder struct {
+	baseURL *url.URL
+	key     []byte
+}
+
+func NewLinkBuilder(baseURL string, key string) (*LinkBuilder, error) {
+	u, err := url.Parse(strings.TrimRight(baseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid public base url: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid public base url: %q", baseURL)
+	}
+	if key == "" {
+		return nil, errors.New("link signing key is empty")
+	}
+
+	return &LinkBuilder{baseURL: u, key: []byte(key)}, nil
+}
+
+// Build returns <base url><path>?token=<signed claims>
+func (b *LinkBuilder) Build(path string, claims *LinkClaims) (string, error) {
+	token, err := SignLink(string(b.key), claims)
+	if err != nil {
+		return "", err
+	}
+
+	u := *b.baseURL
+	u.Path = u.Path + "/" + strings.TrimLeft(path, "/")
+	u.RawQuery = url.Values{"token": []string{token}}.Encode()
+
+	return u.String(), nil
+}
+
+// BuildAt returns <page url>?token=<signed claims> for a page that is not served by this service, e.g. of the frontend
+func (b *LinkBuilder) BuildAt(pageURL string, claims *LinkClaims) (string, error) {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return "", fmt.Errorf("invalid page url: %q", pageURL)
+	}
+
+	token, err := SignLink(string(b.key), claims)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	query.Set("token", token)
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
+
+// SignLink encodes the claims as base64url(json).base64url(HMAC-SHA256)
+func SignLink(key string, claims *LinkClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(linkSignature(key, encoded)), nil
+}
+
+// ParseLink verifies the signature, purpose and expiry of a token built by SignLink
+func ParseLink(key string, purpose string, token string) (*LinkClaims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidLink
+	}
+
+	decodedSig, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+	if !hmac.Equal(decodedSig, linkSignature(key, encoded)) {
+		return nil, ErrInvalidLink
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidLink
+	}
+
+	claims := &LinkClaims{}
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrInvalidLink
+	}
+	if claims.Purpose != purpose {
+		return nil, ErrInvalidLink
+	}
+	if time.Now().Unix() > claims.ExpiredAt {
+		return nil, ErrLinkExpired
+	}
+
+	return claims, nil
+}
+
+func linkSignature(key string, encoded string) []byte {
+	mac := hmac.New(sha256.New, []byte(key))
+	mac.Write([]byte(encoded))
+
+	return mac.Sum(nil)
+}
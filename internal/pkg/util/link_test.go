@@ -0,0 +1,148 @@
+package util_test
+
+import (
+	"go-todolist-grpc/internal/pkg/util"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const linkTestKey = "linkTestKey"
+
+func newLinkClaims(expiredAt time.Time) *util.LinkClaims {
+	return &util.LinkClaims{
+		Purpose:    util.LinkPurposeVerifyEmail,
+		ID:         1,
+		SecretCode: "abcdefgh",
+		ExpiredAt:  expiredAt.Unix(),
+	}
+}
+
+func TestNewLinkBuilder(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		builder, err := util.NewLinkBuilder("https://api.example.com/", linkTestKey)
+		assert.NoError(t, err)
+		assert.NotNil(t, builder)
+	})
+
+	t.Run("Failure_MissingScheme", func(t *testing.T) {
+		builder, err := util.NewLinkBuilder("api.example.com", linkTestKey)
+		assert.EqualError(t, err, `invalid public base url: "api.example.com"`)
+		assert.Nil(t, builder)
+	})
+
+	t.Run("Failure_EmptyKey", func(t *testing.T) {
+		builder, err := util.NewLinkBuilder("https://api.example.com", "")
+		assert.EqualError(t, err, "link signing key is empty")
+		assert.Nil(t, builder)
+	})
+}
+
+func TestLinkBuilderBuild(t *testing.T) {
+	builder, err := util.NewLinkBuilder("https://api.example.com/todo/", linkTestKey)
+	assert.NoError(t, err)
+
+	claims := newLinkClaims(time.Now().Add(time.Hour))
+	link, err := builder.Build("/v1/user/verify_email", claims)
+	assert.NoError(t, err)
+
+	u, err := url.Parse(link)
+	assert.NoError(t, err)
+	assert.Equal(t, "https", u.Scheme)
+	assert.Equal(t, "api.example.com", u.Host)
+	assert.Equal(t, "/todo/v1/user/verify_email", u.Path)
+	assert.Empty(t, u.Query().Get("id"))
+	assert.Empty(t, u.Query().Get("secret_code"))
+
+	parsed, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, u.Query().Get("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, claims, parsed)
+}
+
+func TestLinkBuilderBuildAt(t *testing.T) {
+	builder, err := util.NewLinkBuilder("https://api.example.com", linkTestKey)
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		claims := newLinkClaims(time.Now().Add(time.Hour))
+		link, err := builder.BuildAt("https://app.example.com/reset_password?lang=en", claims)
+		assert.NoError(t, err)
+
+		u, err := url.Parse(link)
+		assert.NoError(t, err)
+		assert.Equal(t, "app.example.com", u.Host)
+		assert.Equal(t, "/reset_password", u.Path)
+		assert.Equal(t, "en", u.Query().Get("lang"))
+
+		parsed, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, u.Query().Get("token"))
+		assert.NoError(t, err)
+		assert.Equal(t, claims, parsed)
+	})
+
+	t.Run("Failure_RelativeURL", func(t *testing.T) {
+		link, err := builder.BuildAt("/reset_password", newLinkClaims(time.Now().Add(time.Hour)))
+		assert.EqualError(t, err, `invalid page url: "/reset_password"`)
+		assert.Empty(t, link)
+	})
+}
+
+func TestParseLink(t *testing.T) {
+	token, err := util.SignLink(linkTestKey, newLinkClaims(time.Now().Add(time.Hour)))
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		claims, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, token)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, claims.ID)
+		assert.Equal(t, "abcdefgh", claims.SecretCode)
+	})
+
+	t.Run("Failure_TamperedPayload", func(t *testing.T) {
+		other, err := util.SignLink(linkTestKey, &util.LinkClaims{
+			Purpose:    util.LinkPurposeVerifyEmail,
+			ID:         2,
+			SecretCode: "abcdefgh",
+			ExpiredAt:  time.Now().Add(time.Hour).Unix(),
+		})
+		assert.NoError(t, err)
+
+		_, signature, _ := strings.Cut(token, ".")
+		payload, _, _ := strings.Cut(other, ".")
+
+		claims, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, payload+"."+signature)
+		assert.ErrorIs(t, err, util.ErrInvalidLink)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Failure_WrongKey", func(t *testing.T) {
+		claims, err := util.ParseLink("otherKey", util.LinkPurposeVerifyEmail, token)
+		assert.ErrorIs(t, err, util.ErrInvalidLink)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Failure_WrongPurpose", func(t *testing.T) {
+		claims, err := util.ParseLink(linkTestKey, util.LinkPurposeResetPassword, token)
+		assert.ErrorIs(t, err, util.ErrInvalidLink)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("Failure_Malformed", func(t *testing.T) {
+		for _, malformed := range []string{"", "abc", "abc.!!!", "!!!.abc"} {
+			claims, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, malformed)
+			assert.ErrorIs(t, err, util.ErrInvalidLink)
+			assert.Nil(t, claims)
+		}
+	})
+
+	t.Run("Failure_Expired", func(t *testing.T) {
+		expired, err := util.SignLink(linkTestKey, newLinkClaims(time.Now().Add(-time.Second)))
+		assert.NoError(t, err)
+
+		claims, err := util.ParseLink(linkTestKey, util.LinkPurposeVerifyEmail, expired)
+		assert.ErrorIs(t, err, util.ErrLinkExpired)
+		assert.Nil(t, claims)
+	})
+}
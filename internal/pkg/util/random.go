@@ -1,6 +1,7 @@
 package util
 
 import (
+	crand "crypto/rand"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -11,13 +12,28 @@ import (
 
 const alphabet = "abcdefghijklmnopqrstuvwxyz"
 
+// RandomString draws from crypto/rand since it backs secret codes sent by mail
 func RandomString(n int) string {
 	sb := strings.Builder{}
+	sb.Grow(n)
 	k := len(alphabet)
 
-	for i := 0; i < n; i++ {
-		c := alphabet[rand.Intn(k)]
-		sb.WriteByte(c)
+	// Reject the bytes above the largest multiple of the alphabet size to avoid modulo bias
+	limit := byte(256 - 256%k)
+	buf := make([]byte, 64)
+	for sb.Len() < n {
+		if _, err := crand.Read(buf); err != nil {
+			panic(fmt.Sprintf("crypto/rand is unavailable: %v", err))
+		}
+		for _, b := range buf {
+			if b >= limit {
+				continue
+			}
+			sb.WriteByte(alphabet[int(b)%k])
+			if sb.Len() == n {
+				break
+			}
+		}
 	}
 
 	return sb.String()
@@ -28,6 +28,13 @@ func TestRandomString(t *testing.T) {
 		assert.NotEqual(t, result1, result2)
 	})
 
+	t.Run("CoversAlphabet", func(t *testing.T) {
+		result := util.RandomString(10000)
+		for _, c := range "abcdefghijklmnopqrstuvwxyz" {
+			assert.Contains(t, result, string(c))
+		}
+	})
+
 	t.Run("ZeroLength", func(t *testing.T) {
 		result := util.RandomString(0)
 		assert.Empty(t, result)
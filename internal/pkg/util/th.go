@@ -1,6 +1,7 @@
 package util
 
 import (
+	"errors"
 	"strconv"
 	"time"
 )
@@ -22,6 +23,15 @@ func GetServiceTimeLoc() *time.Location {
 	return serviceTimeLoc
 }
 
+// LoadTimeZone loads an IANA time zone such as Asia/Taipei, Local is refused as it depends on the host
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("unknown time zone " + name)
+	}
+
+	return time.LoadLocation(name)
+}
+
 func GetFullDateStr(t time.Time) string {
 	return t.In(GetServiceTimeLoc()).Format(FullDateLayout)
 }
@@ -13,6 +13,22 @@ func TestGetServiceTimeLoc(t *testing.T) {
 	assert.Equal(t, "GMT+8", loc.String())
 }
 
+func TestLoadTimeZone(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		loc, err := util.LoadTimeZone("America/New_York")
+		assert.NoError(t, err)
+		assert.Equal(t, "America/New_York", loc.String())
+	})
+
+	t.Run("Failure_Unknown", func(t *testing.T) {
+		for _, name := range []string{"", "Local", "Mars/Olympus"} {
+			loc, err := util.LoadTimeZone(name)
+			assert.Error(t, err)
+			assert.Nil(t, loc)
+		}
+	})
+}
+
 func TestGetFullDateStr(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		now := time.Now()
@@ -0,0 +1,124 @@
+package util
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// TOTP parameters of RFC 6238, the defaults every authenticator app understands
+const (
+	TotpDigits = 6
+	TotpPeriod = 30
+	// Codes of the neighbouring periods are accepted to tolerate clock drift
+	TotpSkew = 1
+)
+
+const (
+	recoveryCodeLength = 10
+	RecoveryCodeCount  = 10
+)
+
+var ErrInvalidTotpSecret = errors.New("the totp secret is invalid")
+
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateTotpSecret returns a random 160 bits secret encoded in base32
+func GenerateTotpSecret() (string, error) {
+	buf := make([]byte, 20)
+	if _, err := crand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return totpEncoding.EncodeToString(buf), nil
+}
+
+// TotpKeyURI returns the otpauth URI rendered as a QR code by authenticator apps
+func TotpKeyURI(issuer string, account string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(TotpDigits))
+	query.Set("period", fmt.Sprint(TotpPeriod))
+
+	label := url.PathEscape(issuer + ":" + account)
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// TotpStep returns the number of the period the time falls into
+func TotpStep(t time.Time) int {
+	return int(t.Unix() / TotpPeriod)
+}
+
+// TotpCode returns the code of the secret for the given period
+func TotpCode(secret string, step int) (string, error) {
+	key, err := totpEncoding.DecodeString(strings.ToUpper(strings.TrimRight(secret, "=")))
+	if err != nil || len(key) == 0 {
+		return "", ErrInvalidTotpSecret
+	}
+
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation of RFC 4226
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < TotpDigits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", TotpDigits, value%mod), nil
+}
+
+// ValidateTotp checks the code against the periods around the time and returns the matched one,
+// callers keep the last matched period to refuse a code being replayed.
+func ValidateTotp(secret string, code string, t time.Time) (int, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != TotpDigits {
+		return 0, false
+	}
+
+	current := TotpStep(t)
+	for step := current - TotpSkew; step <= current+TotpSkew; step++ {
+		expected, err := TotpCode(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+// GenerateRecoveryCodes returns single use codes formatted as xxxxx-xxxxx
+func GenerateRecoveryCodes(n int) []string {
+	codes := make([]string, n)
+	for i := range codes {
+		code := RandomString(recoveryCodeLength)
+		codes[i] = code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+	}
+
+	return codes
+}
+
+// NormalizeRecoveryCode drops the separator and the case so that the code can be typed loosely
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
@@ -0,0 +1,111 @@
+package util_test
+
+import (
+	"encoding/base32"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The SHA1 seed and vectors of RFC 6238 appendix B, truncated to 6 digits
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTotpCode(t *testing.T) {
+	vectors := map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	}
+
+	for unix, expected := range vectors {
+		code, err := util.TotpCode(rfcSecret, util.TotpStep(time.Unix(unix, 0)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, code, "time %d", unix)
+	}
+
+	t.Run("Failure_InvalidSecret", func(t *testing.T) {
+		code, err := util.TotpCode("not base32!", 1)
+		assert.ErrorIs(t, err, util.ErrInvalidTotpSecret)
+		assert.Empty(t, code)
+	})
+}
+
+func TestValidateTotp(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	step := util.TotpStep(now)
+
+	t.Run("Success", func(t *testing.T) {
+		matched, ok := util.ValidateTotp(rfcSecret, "050471", now)
+		assert.True(t, ok)
+		assert.Equal(t, step, matched)
+	})
+
+	t.Run("Success_ClockDrift", func(t *testing.T) {
+		code, err := util.TotpCode(rfcSecret, step-1)
+		assert.NoError(t, err)
+
+		matched, ok := util.ValidateTotp(rfcSecret, code, now)
+		assert.True(t, ok)
+		assert.Equal(t, step-1, matched)
+	})
+
+	t.Run("Failure_OutsideSkew", func(t *testing.T) {
+		code, err := util.TotpCode(rfcSecret, step-2)
+		assert.NoError(t, err)
+
+		_, ok := util.ValidateTotp(rfcSecret, code, now)
+		assert.False(t, ok)
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		_, ok := util.ValidateTotp(rfcSecret, "000000", now)
+		assert.False(t, ok)
+
+		_, ok = util.ValidateTotp(rfcSecret, "5047", now)
+		assert.False(t, ok)
+	})
+}
+
+func TestGenerateTotpSecret(t *testing.T) {
+	secret, err := util.GenerateTotpSecret()
+	assert.NoError(t, err)
+	assert.Len(t, secret, 32)
+
+	code, err := util.TotpCode(secret, util.TotpStep(time.Now()))
+	assert.NoError(t, err)
+	assert.Len(t, code, util.TotpDigits)
+}
+
+func TestTotpKeyURI(t *testing.T) {
+	uri := util.TotpKeyURI("Go-Todolist-gRPC", "bob@example.com", "JBSWY3DPEHPK3PXP")
+
+	u, err := url.Parse(uri)
+	assert.NoError(t, err)
+	assert.Equal(t, "otpauth", u.Scheme)
+	assert.Equal(t, "totp", u.Host)
+	assert.Equal(t, "/Go-Todolist-gRPC:bob@example.com", u.Path)
+	assert.Equal(t, "JBSWY3DPEHPK3PXP", u.Query().Get("secret"))
+	assert.Equal(t, "Go-Todolist-gRPC", u.Query().Get("issuer"))
+	assert.Equal(t, "6", u.Query().Get("digits"))
+}
+
+func TestGenerateRecoveryCodes(t *testing.T) {
+	codes := util.GenerateRecoveryCodes(util.RecoveryCodeCount)
+	assert.Len(t, codes, util.RecoveryCodeCount)
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		assert.Regexp(t, `^[a-z]{5}-[a-z]{5}$`, code)
+		assert.False(t, seen[code])
+		seen[code] = true
+
+		assert.Equal(t, strings.ReplaceAll(code, "-", ""), util.NormalizeRecoveryCode(" "+strings.ToUpper(code)))
+	}
+}
@@ -1,5 +1,12 @@
 package util
 
+import "strings"
+
 func Pointer[T any](value T) *T {
 	return &value
 }
+
+// NormalizeEmail returns the form an email is stored and matched in
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
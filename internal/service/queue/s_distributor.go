@@ -8,16 +8,22 @@ import (
 
 type TaskDistributor interface {
 	DistributeTaskSendVerifyEmail(ctx context.Context, payload *PayloadSendVerifyEmail, opts ...asynq.Option) error
+	DistributeTaskSendResetPassword(ctx context.Context, payload *PayloadSendResetPassword, opts ...asynq.Option) error
+	DistributeTaskSendTaskReminder(ctx context.Context, payload *PayloadSendTaskReminder, opts ...asynq.Option) error
+	CancelTaskSendTaskReminder(ctx context.Context, payload *PayloadSendTaskReminder) error
 }
 
 type RedisTaskDistributor struct {
-	client *asynq.Client
+	client    *asynq.Client
+	inspector *asynq.Inspector
 }
 
 func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
 	client := asynq.NewClient(redisOpt)
+	inspector := asynq.NewInspector(redisOpt)
 
 	return &RedisTaskDistributor{
-		client: client,
+		client:    client,
+		inspector: inspector,
 	}
 }
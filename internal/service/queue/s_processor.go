@@ -3,6 +3,8 @@ package queue
 import (
 	"context"
 	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"go-todolist-grpc/internal/pkg/util"
 
 	"github.com/hibiken/asynq"
 	"github.com/redis/go-redis/v9"
@@ -17,13 +19,18 @@ type TaskProcessor interface {
 	Start() error
 	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendResetPassword(ctx context.Context, task *asynq.Task) error
+	ProcessTaskSendTaskReminder(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
+	server    *asynq.Server
+	mailer    mail.Mailer
+	templates *mail.Registry
+	links     *util.LinkBuilder
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt) TaskProcessor {
+func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, mailer mail.Mailer, templates *mail.Registry, links *util.LinkBuilder) TaskProcessor {
 	logger := NewLogger()
 	redis.SetLogger(logger)
 
@@ -42,13 +49,18 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt) TaskProcessor {
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
+		server:    server,
+		mailer:    mailer,
+		templates: templates,
+		links:     links,
 	}
 }
 
 func (p *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
 	mux.HandleFunc(TaskSendVerifyEmail, p.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskSendResetPassword, p.ProcessTaskSendResetPassword)
+	mux.HandleFunc(TaskSendTaskReminder, p.ProcessTaskSendTaskReminder)
 
 	return p.server.Start(mux)
 }
@@ -0,0 +1,130 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"go-todolist-grpc/internal/pkg/util"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskSendResetPassword = "send_reset_password"
+
+// Lifetime of a password reset code
+const resetPasswordExpireMinutes = 15
+
+type PayloadSendResetPassword struct {
+	UserId int `json:"user_id"`
+}
+
+func (rtd *RedisTaskDistributor) DistributeTaskSendResetPassword(ctx context.Context, payload *PayloadSendResetPassword, opts ...asynq.Option) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	task := asynq.NewTask(TaskSendResetPassword, jsonPayload, opts...)
+	info, err := rtd.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	log.Info.Printf("enqueued task - type: %s, payload (userID): %s, queue: %s, max_retry: %d", task.Type(), string(task.Payload()), info.Queue, info.MaxRetry)
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendResetPassword(ctx context.Context, task *asynq.Task) error {
+	type mailContent struct {
+		Username      string
+		ResetURL      string
+		ExpireMinutes int
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+	payload := PayloadSendResetPassword{}
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	// Get the user info
+	getUser := model.GetUserByID(conn, payload.UserId)
+	if getUser == nil {
+		return errors.New("[send reset password] - user ID not found")
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return fmt.Errorf("failed to open db transaction: %w", txErr)
+	}
+	defer tx.Rollback()
+
+	// Only the link of the new email stays valid
+	if err := model.ExpirePasswordResetsByUserID(tx, getUser.ID); err != nil {
+		return fmt.Errorf("failed to expire password resets: %w", err)
+	}
+
+	// Only the hash of the code is stored, the code itself goes into the signed link
+	now := time.Now().UTC()
+	secretCode := util.RandomString(32)
+	createPasswordReset, createPasswordResetErr := model.CreatePasswordReset(tx, &model.PasswordResetFieldValues{
+		UserId:         model.GiveColInt(getUser.ID),
+		Email:          model.GiveColString(getUser.Email),
+		SecretCodeHash: model.GiveColString(util.HashSecretCode(cnf.SecretCodeHashKey, secretCode)),
+		IsUsed:         model.GiveColBool(false),
+		ExpiredAt:      model.GiveColTime(now.Add(resetPasswordExpireMinutes * time.Minute)),
+		CreatedAt:      model.GiveColTime(now),
+		UpdatedAt:      model.GiveColTime(now),
+	})
+	if createPasswordResetErr != nil {
+		return fmt.Errorf("failed to create password reset: %w", createPasswordResetErr)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to create password reset from db tx: %w", err)
+	}
+
+	// Define email content, the page of the frontend behind the link posts the new password to /v1/user/reset_password
+	resetUrl, resetUrlErr := p.links.BuildAt(cnf.ResetPasswordUrl, &util.LinkClaims{
+		Purpose:    util.LinkPurposeResetPassword,
+		ID:         createPasswordReset.ID.Val,
+		SecretCode: secretCode,
+		ExpiredAt:  createPasswordReset.ExpiredAt.Val.Unix(),
+	})
+	if resetUrlErr != nil {
+		return fmt.Errorf("failed to build reset password link: %w", resetUrlErr)
+	}
+	data := mailContent{
+		Username:      getUser.Username,
+		ResetURL:      resetUrl,
+		ExpireMinutes: resetPasswordExpireMinutes,
+	}
+
+	rendered, renderErr := p.templates.Render(mail.TemplateResetPassword, getUser.Language, data)
+	if renderErr != nil {
+		return fmt.Errorf("failed to render reset password email: %w", renderErr)
+	}
+
+	if err := p.mailer.Send(ctx, &mail.Message{
+		Sender:    fmt.Sprintf("%s <%s>", cnf.EmailSenderName, cnf.EmailSenderAddress),
+		Recipient: []string{createPasswordReset.Email.Val},
+		Bccs:      []string{},
+		Subject:   rendered.Subject,
+		HtmlBody:  rendered.HtmlBody,
+		TextBody:  rendered.TextBody,
+	}); err != nil {
+		log.Error.Printf("sent reset password email error: %v", err)
+		return err
+	}
+	log.Info.Printf("processed task - type: %s, payload (userID): %s, email: %s", task.Type(), string(task.Payload()), getUser.Email)
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"go-todolist-grpc/internal/pkg/util"
+	"time"
+
+	"github.com/hibiken/asynq"
+)
+
+const TaskSendTaskReminder = "send_task_reminder"
+
+// PayloadSendTaskReminder identifies a reminder along with the time it was scheduled at,
+// a reminder rescheduled since then no longer matches and is not sent.
+type PayloadSendTaskReminder struct {
+	ReminderId int   `json:"reminder_id"`
+	RemindAt   int64 `json:"remind_at"`
+}
+
+// taskId is the ID of the asynq task, unique for every schedule of the reminder so it can be cancelled
+func (payload *PayloadSendTaskReminder) taskId() string {
+	return fmt.Sprintf("%s:%d:%d", TaskSendTaskReminder, payload.ReminderId, payload.RemindAt)
+}
+
+// DistributeTaskSendTaskReminder schedules the reminder to be processed at its time on the mail queue
+func (rtd *RedisTaskDistributor) DistributeTaskSendTaskReminder(ctx context.Context, payload *PayloadSendTaskReminder, opts ...asynq.Option) error {
+	jsonPayload, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal task payload: %w", err)
+	}
+
+	opts = append(opts,
+		asynq.Queue(QueueSendMail),
+		asynq.TaskID(payload.taskId()),
+		asynq.ProcessAt(time.Unix(payload.RemindAt, 0)),
+	)
+	task := asynq.NewTask(TaskSendTaskReminder, jsonPayload, opts...)
+	info, err := rtd.client.EnqueueContext(ctx, task)
+	if err != nil {
+		return err
+	}
+
+	log.Info.Printf("enqueued task - type: %s, payload (reminderID): %s, queue: %s, process_at: %s", task.Type(), string(task.Payload()), info.Queue, util.GetFullDateStr(info.NextProcessAt))
+	return nil
+}
+
+// CancelTaskSendTaskReminder removes the scheduled reminder from the queue, a reminder already processed is ignored
+func (rtd *RedisTaskDistributor) CancelTaskSendTaskReminder(ctx context.Context, payload *PayloadSendTaskReminder) error {
+	err := rtd.inspector.DeleteTask(QueueSendMail, payload.taskId())
+	if err != nil && !errors.Is(err, asynq.ErrTaskNotFound) && !errors.Is(err, asynq.ErrQueueNotFound) {
+		return err
+	}
+
+	log.Info.Printf("cancelled task - type: %s, id: %s", TaskSendTaskReminder, payload.taskId())
+	return nil
+}
+
+func (p *RedisTaskProcessor) ProcessTaskSendTaskReminder(ctx context.Context, task *asynq.Task) error {
+	type mailContent struct {
+		Username      string
+		Title         string
+		Note          string
+		DueAt         string
+		OffsetMinutes int
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+	payload := PayloadSendTaskReminder{}
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	// The reminder may have been sent, cancelled or rescheduled since it was enqueued
+	getReminder := model.GetTaskReminderByID(conn, payload.ReminderId)
+	if getReminder == nil || getReminder.SentAt != nil || getReminder.RemindAt.Unix() != payload.RemindAt {
+		log.Info.Printf("skipped task - type: %s, payload (reminderID): %s, the reminder is no longer scheduled", task.Type(), string(task.Payload()))
+		return nil
+	}
+
+	// A completed task needs no reminder
+	getTask := model.GetTaskByID(conn, getReminder.TaskId)
+	if getTask == nil || getTask.IsComplete || !getTask.IsSpecifyTime {
+		log.Info.Printf("skipped task - type: %s, payload (reminderID): %s, the task is complete", task.Type(), string(task.Payload()))
+		return nil
+	}
+
+	// Get the user info
+	getUser := model.GetUserByID(conn, getTask.UserId)
+	if getUser == nil {
+		return errors.New("[send task reminder] - user ID not found")
+	}
+
+	// The due time is shown in the time zone of the user
+	loc, locErr := util.LoadTimeZone(getUser.TimeZone)
+	if locErr != nil {
+		loc = util.GetServiceTimeLoc()
+	}
+	data := mailContent{
+		Username:      getUser.Username,
+		Title:         getTask.Title,
+		Note:          getTask.Note,
+		DueAt:         getTask.SpecifyDatetime.In(loc).Format("2006-01-02 15:04 MST"),
+		OffsetMinutes: getReminder.OffsetMinutes,
+	}
+
+	rendered, renderErr := p.templates.Render(mail.TemplateTaskReminder, getUser.Language, data)
+	if renderErr != nil {
+		return fmt.Errorf("failed to render task reminder email: %w", renderErr)
+	}
+
+	if err := p.mailer.Send(ctx, &mail.Message{
+		Sender:    fmt.Sprintf("%s <%s>", cnf.EmailSenderName, cnf.EmailSenderAddress),
+		Recipient: []string{getUser.Email},
+		Bccs:      []string{},
+		Subject:   rendered.Subject,
+		HtmlBody:  rendered.HtmlBody,
+		TextBody:  rendered.TextBody,
+	}); err != nil {
+		log.Error.Printf("sent task reminder email error: %v", err)
+		return err
+	}
+
+	if _, err := model.MarkTaskReminderSent(conn, getReminder.ID, getReminder.RemindAt, time.Now().UTC()); err != nil {
+		log.Error.Printf("failed to mark task reminder %d sent: %v", getReminder.ID, err)
+	}
+	log.Info.Printf("processed task - type: %s, payload (reminderID): %s, email: %s", task.Type(), string(task.Payload()), getUser.Email)
+
+	return nil
+}
@@ -1,7 +1,6 @@
 package queue
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -12,56 +11,12 @@ import (
 	"go-todolist-grpc/internal/pkg/log"
 	"go-todolist-grpc/internal/pkg/mail"
 	"go-todolist-grpc/internal/pkg/util"
-	"html/template"
 	"time"
 
 	"github.com/hibiken/asynq"
 )
 
 const TaskSendVerifyEmail = "send_verify_email"
-const htmlTemplate = `
-<!DOCTYPE html>
-<html lang="en">
-<head>
-    <meta charset="UTF-8">
-    <meta name="viewport" content="width=device-width, initial-scale=1.0">
-    <title>Email Verification</title>
-    <style>
-        body {
-            font-family: Arial, sans-serif;
-            line-height: 1.6;
-            color: #333;
-        }
-        .container {
-            max-width: 600px;
-            margin: 0 auto;
-            padding: 20px;
-        }
-        .button {
-            display: inline-block;
-            padding: 10px 20px;
-            background-color: #007bff;
-            color: #ffffff;
-            text-decoration: none;
-            border-radius: 5px;
-        }
-    </style>
-</head>
-<body>
-    <div class="container">
-        <h2>Hello {{.Username}},</h2>
-        <p>Thank you for registering with us!</p>
-        <p>Please click the button below to verify your email address:</p>
-        <p>
-            <a href="{{.VerifyURL}}" class="button">Verify Email</a>
-        </p>
-        <p>If the button doesn't work, you can copy and paste this link into your browser:</p>
-        <p>{{.VerifyURL}}</p>
-        <p>Best regards,<br>Your Team</p>
-    </div>
-</body>
-</html>
-`
 
 type PayloadSendVerifyEmail struct {
 	UserId int `json:"user_id"`
@@ -102,52 +57,51 @@ func (p *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, tas
 		return errors.New("[send verify email] - user ID not found")
 	}
 
+	// Only the hash of the code is stored, the code itself goes into the signed link
 	now := time.Now().UTC()
+	secretCode := util.RandomString(32)
 	createVerifyEmail, createVerifyEmailErr := model.CreateVerifyEmail(conn, &model.VerifyEmailFieldValues{
-		UserId:     model.GiveColInt(getUser.ID),
-		Username:   model.GiveColString(getUser.Username),
-		Email:      model.GiveColString(getUser.Email),
-		SecretCode: model.GiveColString(util.RandomString(32)),
-		IsUsed:     model.GiveColBool(false),
-		ExpiredAt:  model.GiveColTime(now.Add(1 * time.Hour)),
-		CreatedAt:  model.GiveColTime(now),
-		UpdatedAt:  model.GiveColTime(now),
+		UserId:         model.GiveColInt(getUser.ID),
+		Username:       model.GiveColString(getUser.Username),
+		Email:          model.GiveColString(getUser.Email),
+		SecretCodeHash: model.GiveColString(util.HashSecretCode(cnf.SecretCodeHashKey, secretCode)),
+		IsUsed:         model.GiveColBool(false),
+		ExpiredAt:      model.GiveColTime(now.Add(1 * time.Hour)),
+		CreatedAt:      model.GiveColTime(now),
+		UpdatedAt:      model.GiveColTime(now),
 	})
 	if createVerifyEmailErr != nil {
 		return fmt.Errorf("failed to create verify email: %w", createVerifyEmailErr)
 	}
 
 	// Define email content
-	verifyUrl := fmt.Sprintf(
-		"http://localhost:%s/v1/user/verify_email?id=%d&secret_code=%s",
-		cnf.HttpServerPort,
-		createVerifyEmail.ID.Val,
-		createVerifyEmail.SecretCode.Val,
-	)
+	verifyUrl, verifyUrlErr := p.links.Build("/v1/user/verify_email", &util.LinkClaims{
+		Purpose:    util.LinkPurposeVerifyEmail,
+		ID:         createVerifyEmail.ID.Val,
+		SecretCode: secretCode,
+		ExpiredAt:  createVerifyEmail.ExpiredAt.Val.Unix(),
+	})
+	if verifyUrlErr != nil {
+		return fmt.Errorf("failed to build verify email link: %w", verifyUrlErr)
+	}
 	data := mailContent{
 		Username:  createVerifyEmail.Username.Val,
 		VerifyURL: verifyUrl,
 	}
 
-	tmpl, tmplErr := template.New("emailTemplate").Parse(htmlTemplate)
-	if tmplErr != nil {
-		return fmt.Errorf("error parsing template: %v", tmplErr)
-	}
-
-	htmlStr := bytes.Buffer{}
-	if err := tmpl.Execute(&htmlStr, data); err != nil {
-		return fmt.Errorf("error executing template: %v", err)
+	rendered, renderErr := p.templates.Render(mail.TemplateVerifyEmail, getUser.Language, data)
+	if renderErr != nil {
+		return fmt.Errorf("failed to render verify email: %w", renderErr)
 	}
 
-	if err := mail.SendEmail(
-		cnf,
-		fmt.Sprintf("%s <%s>", cnf.EmailSenderName, cnf.EmailSenderAddress), // sender
-		[]string{createVerifyEmail.Email.Val},                               // recipient
-		[]string{},                                                          // bccs
-		"Welcome to Go-Todolist-gRPC",                                       // subject
-		htmlStr.String(),                                                    // htmlBody
-		"",                                                                  // textBody
-	); err != nil {
+	if err := p.mailer.Send(ctx, &mail.Message{
+		Sender:    fmt.Sprintf("%s <%s>", cnf.EmailSenderName, cnf.EmailSenderAddress),
+		Recipient: []string{createVerifyEmail.Email.Val},
+		Bccs:      []string{},
+		Subject:   rendered.Subject,
+		HtmlBody:  rendered.HtmlBody,
+		TextBody:  rendered.TextBody,
+	}); err != nil {
 		log.Error.Printf("sent verify email error: %v", err)
 		return err
 	}
@@ -1,23 +1,31 @@
 package service
 
 import (
+	"errors"
 	"fmt"
 	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service/queue"
 	"reflect"
 	"strings"
 
 	"github.com/go-playground/validator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 type Server struct {
 	pb.UnimplementedToDoListServer
 	taskDistributor queue.TaskDistributor
+	loginGuard      *lockout.Guard
 }
 
-func NewServer(taskDistributor queue.TaskDistributor) *Server {
+func NewServer(taskDistributor queue.TaskDistributor, loginGuard *lockout.Guard) *Server {
 	return &Server{
 		taskDistributor: taskDistributor,
+		loginGuard:      loginGuard,
 	}
 }
 
@@ -71,6 +79,11 @@ func bindRequest(req interface{}, reqStruct interface{}) error {
 			fieldVal.SetFloat(reqField.Float())
 		case reflect.Bool:
 			fieldVal.SetBool(reqField.Bool())
+		case reflect.Slice:
+			if !reqField.Type().AssignableTo(fieldVal.Type()) {
+				return fmt.Errorf("unsupported field type: %v", reqField.Type())
+			}
+			fieldVal.Set(reqField)
 		default:
 			return fmt.Errorf("unsupported field type: %v", fieldVal.Kind())
 		}
@@ -107,3 +120,16 @@ func ParseSortBy(str string) map[string]bool {
 
 	return rtn
 }
+
+// parseLinkToken checks the token of a link sent by mail and maps the failure to a gRPC status
+func parseLinkToken(purpose string, token string) (*util.LinkClaims, error) {
+	claims, err := util.ParseLink(config.Get().LinkSigningKey, purpose, token)
+	if err != nil {
+		if errors.Is(err, util.ErrLinkExpired) {
+			return nil, status.Errorf(codes.InvalidArgument, "%v", err)
+		}
+		return nil, status.Errorf(codes.InvalidArgument, "%v", util.ErrInvalidLink)
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,235 @@
+package service
+
+import (
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// requirePermission returns the claims when they hold the permission, the middleware has checked it already
+// but the handlers are also reachable without it in tests and from other services.
+func requirePermission(ctx context.Context, permission string) (*util.CustomClaims, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	if !claims.HasPermission(permission) {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
+
+	return claims, nil
+}
+
+// adminUserToPb returns the user with the fields only admins see
+func adminUserToPb(user *model.User) *pb.User {
+	return &pb.User{
+		Id:              int32(user.ID),
+		Username:        user.Username,
+		Email:           user.Email,
+		CreatedAt:       util.GetFullDateStr(user.CreatedAt),
+		UpdatedAt:       util.GetFullDateStr(user.UpdatedAt),
+		Language:        user.Language,
+		TimeZone:        user.TimeZone,
+		Role:            &user.Role,
+		Status:          &user.Status,
+		IsEmailVerified: &user.IsEmailVerified,
+	}
+}
+
+type ReqListUsers struct {
+	Page     int32   `json:"page" validate:"required,min=1,max=100000"`
+	PageSize int32   `json:"page_size" validate:"required,min=5,max=1000"`
+	SortBy   *string `json:"sort_by" validate:"omitempty,max=10"`
+	Email    *string `json:"email" validate:"omitempty,min=1,max=64"`
+	Role     *string `json:"role" validate:"omitempty,min=1,max=16"`
+	Status   *bool   `json:"status"`
+}
+
+func (ins ReqListUsers) toConditions() *model.UserConditions {
+	cons := &model.UserConditions{}
+
+	if ins.Email != nil {
+		email := *ins.Email
+		cons.Email = &condition.String{Like: &email}
+	}
+
+	if ins.Role != nil {
+		role := *ins.Role
+		cons.Role = &condition.String{EQ: &role}
+	}
+
+	if ins.Status != nil {
+		userStatus := *ins.Status
+		cons.Status = &condition.Bool{EQ: &userStatus}
+	}
+
+	return cons
+}
+
+func (s *Server) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListResponse, error) {
+	if _, err := requirePermission(ctx, util.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	conn := db.GetConn()
+
+	reqList := &ReqListUsers{}
+	if err := bindRequest(req, reqList); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	limit := int(reqList.PageSize)
+	offset := int((reqList.Page - 1) * reqList.PageSize)
+	cons := reqList.toConditions()
+	reqOrderBy := &model.UserOrderBy{}
+	if reqList.SortBy != nil {
+		reqOrderBy.Parse(ParseSortBy(*reqList.SortBy))
+	}
+
+	listUser := model.ListUser(conn, cons, reqOrderBy, &limit, &offset)
+	count, err := model.GetUserCount(conn, cons)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get user count: %v", err)
+	}
+
+	pbUsers := []*pb.User{}
+	for _, user := range listUser {
+		pbUsers = append(pbUsers, adminUserToPb(&user))
+	}
+
+	return &pb.ListResponse{
+		Data: &pb.ListResponse_Users{
+			Users: &pb.Users{
+				Data: pbUsers,
+			},
+		},
+		TotalCount: count,
+		Page:       reqList.Page,
+		PageSize:   reqList.PageSize,
+		Status:     http.StatusOK,
+		Message:    "ok",
+	}, nil
+}
+
+type ReqAdminUserId struct {
+	UserId int32 `json:"user_id" validate:"required,min=1"`
+}
+
+// updateUserByAdmin applies the values to the user of the request and returns the user after the update,
+// revoke also ends every session of the user.
+func updateUserByAdmin(userId int, values *model.UserFieldValues, revoke bool) (*pb.Response, error) {
+	conn := db.GetConn()
+
+	// Check if the user ID not found
+	if getUser := model.GetUserByID(conn, userId); getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	values.UpdatedAt = model.GiveColTime(time.Now().UTC())
+	if err := model.UpdateUser(tx, userId, values); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	if revoke {
+		if err := model.RevokeRefreshTokensByUserID(tx, userId); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+		}
+	}
+
+	getUser := model.GetUserByID(tx, userId)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to update user from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to update user from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_User{
+			User: adminUserToPb(getUser),
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+func (s *Server) DisableUser(ctx context.Context, req *pb.DisableUserRequest) (*pb.Response, error) {
+	claims, err := requirePermission(ctx, util.PermissionUsersWrite)
+	if err != nil {
+		return nil, err
+	}
+
+	reqDisable := &ReqAdminUserId{}
+	if err := bindRequest(req, reqDisable); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// An admin locking themselves out would need another admin to undo it
+	if int(reqDisable.UserId) == claims.UserID {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot disable your own account")
+	}
+
+	// Disabled by an admin, the user can no longer reactivate the account
+	fv := &model.UserFieldValues{
+		Status:        model.GiveColBool(false),
+		DeactivatedAt: model.GiveColNullTime(nil),
+	}
+
+	return updateUserByAdmin(int(reqDisable.UserId), fv, true)
+}
+
+func (s *Server) EnableUser(ctx context.Context, req *pb.EnableUserRequest) (*pb.Response, error) {
+	if _, err := requirePermission(ctx, util.PermissionUsersWrite); err != nil {
+		return nil, err
+	}
+
+	reqEnable := &ReqAdminUserId{}
+	if err := bindRequest(req, reqEnable); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	fv := &model.UserFieldValues{
+		Status:        model.GiveColBool(true),
+		DeactivatedAt: model.GiveColNullTime(nil),
+	}
+
+	return updateUserByAdmin(int(reqEnable.UserId), fv, false)
+}
+
+func (s *Server) VerifyUserEmail(ctx context.Context, req *pb.VerifyUserEmailRequest) (*pb.Response, error) {
+	if _, err := requirePermission(ctx, util.PermissionUsersWrite); err != nil {
+		return nil, err
+	}
+
+	reqVerify := &ReqAdminUserId{}
+	if err := bindRequest(req, reqVerify); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	fv := &model.UserFieldValues{
+		IsEmailVerified: model.GiveColBool(true),
+	}
+
+	return updateUserByAdmin(int(reqVerify.UserId), fv, false)
+}
@@ -0,0 +1,283 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpAdmin() (*service.Server, error) {
+	var mockConfigContent bytes.Buffer
+	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
+	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
+	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
+	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
+	mockConfigContent.WriteString("DB_PASS=" + config.SourcePassword + "\n")
+	mockConfigContent.WriteString("DB_NAME=" + config.SourceDataBase + "\n")
+	mockConfigContent.WriteString("SSL_MODE=" + config.SourceSSLMode + "\n")
+	mockConfigContent.WriteString("DB_CONN_MAX_LT_SEC=" + strconv.Itoa(config.SourceDBConnMaxLTSec) + "\n")
+	mockConfigContent.WriteString("DB_MAX_CONN=" + strconv.Itoa(config.SourceMaxConn) + "\n")
+	mockConfigContent.WriteString("DB_MAX_IDLE=" + strconv.Itoa(config.SourceMaxIdle) + "\n")
+	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
+	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
+	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
+	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
+	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
+	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
+	mockConfigContent.WriteString("ENABLE_FILE_OUTPUT=" + strconv.FormatBool(config.EnableFileOutput) + "\n")
+
+	// Create app.env file
+	appFolderPath, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	mockConfigFile := filepath.Join(appFolderPath, "app.env")
+	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
+	defer os.Remove(mockConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Init config
+	loadErr := config.Load()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	// Init log
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, err
+	}
+
+	// Init sql
+	opt := &db.Option{
+		Host:     config.SourceHost,
+		Port:     config.SourcePort,
+		Username: config.SourceUser,
+		Password: config.SourcePassword,
+		DBName:   config.SourceDataBase,
+		SSLMode:  config.SourceSSLMode,
+	}
+
+	err = db.Init(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+
+	return s, nil
+}
+
+type adminTestUser struct {
+	id       int32
+	email    string
+	password string
+}
+
+// registerAdminTestUser registers a user, verifying the email when verified is true
+func registerAdminTestUser(t *testing.T, s *service.Server, verified bool) *adminTestUser {
+	user := &adminTestUser{
+		email:    util.RandomEmail(),
+		password: util.RandomString(8),
+	}
+
+	rRes, err := s.RegisterUser(context.Background(), &pb.RegisterUserRequest{
+		Email:    user.email,
+		Username: util.RandomString(6),
+		Password: user.password,
+	})
+	assert.Nil(t, err)
+	user.id = rRes.GetUser().Id
+
+	if verified {
+		_, err = s.VerifyUserEmail(createAdminContext(0), &pb.VerifyUserEmailRequest{UserId: user.id})
+		assert.Nil(t, err)
+	}
+
+	return user
+}
+
+func TestLoginPermissions(t *testing.T) {
+	s, err := setUpAdmin()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, true)
+
+		res, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    user.email,
+			Password: user.password,
+		})
+		assert.Nil(t, err)
+
+		claims, err := util.ParseToken(util.GetKeySet(), res.GetUser().GetToken())
+		assert.NoError(t, err)
+		assert.Equal(t, util.RoleUser, claims.Role)
+		assert.ElementsMatch(t, []string{util.PermissionAccount, util.PermissionTasksRead, util.PermissionTasksWrite}, claims.Permissions)
+		assert.False(t, claims.HasPermission(util.PermissionUsersRead))
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	s, err := setUpAdmin()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, false)
+		email := user.email
+
+		res, err := s.ListUsers(createAdminContext(0), &pb.ListUsersRequest{
+			Page:     1,
+			PageSize: 5,
+			Email:    &email,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, int32(1), res.TotalCount)
+		assert.Len(t, res.GetUsers().Data, 1)
+		assert.Equal(t, user.id, res.GetUsers().Data[0].Id)
+		assert.Equal(t, util.RoleUser, res.GetUsers().Data[0].GetRole())
+		assert.True(t, res.GetUsers().Data[0].GetStatus())
+		assert.False(t, res.GetUsers().Data[0].GetIsEmailVerified())
+	})
+
+	t.Run("Success_FilterStatus", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, false)
+		_, err := s.DisableUser(createAdminContext(0), &pb.DisableUserRequest{UserId: user.id})
+		assert.Nil(t, err)
+
+		userStatus := false
+		res, err := s.ListUsers(createAdminContext(0), &pb.ListUsersRequest{
+			Page:     1,
+			PageSize: 1000,
+			Status:   &userStatus,
+		})
+		assert.Nil(t, err)
+		ids := []int32{}
+		for _, u := range res.GetUsers().Data {
+			assert.False(t, u.GetStatus())
+			ids = append(ids, u.Id)
+		}
+		assert.Contains(t, ids, user.id)
+	})
+
+	t.Run("Failure_PermissionDenied", func(t *testing.T) {
+		res, err := s.ListUsers(createAuthenticatedContext(1), &pb.ListUsersRequest{
+			Page:     1,
+			PageSize: 5,
+		})
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		res, err := s.ListUsers(context.Background(), &pb.ListUsersRequest{
+			Page:     1,
+			PageSize: 5,
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+	})
+}
+
+func TestDisableUser(t *testing.T) {
+	s, err := setUpAdmin()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, true)
+		lRes, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    user.email,
+			Password: user.password,
+		})
+		assert.Nil(t, err)
+
+		res, err := s.DisableUser(createAdminContext(0), &pb.DisableUserRequest{UserId: user.id})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.False(t, res.GetUser().GetStatus())
+
+		// Every session of the user ends
+		claims, err := util.ParseToken(util.GetKeySet(), lRes.GetUser().GetToken())
+		assert.NoError(t, err)
+		isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), claims.ID)
+		assert.NoError(t, revokedErr)
+		assert.True(t, isRevoked)
+
+		eRes, err := s.EnableUser(createAdminContext(0), &pb.EnableUserRequest{UserId: user.id})
+		assert.Nil(t, err)
+		assert.True(t, eRes.GetUser().GetStatus())
+	})
+
+	t.Run("Failure_Self", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, true)
+
+		res, err := s.DisableUser(createAdminContext(int(user.id)), &pb.DisableUserRequest{UserId: user.id})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = cannot disable your own account")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		res, err := s.DisableUser(createAdminContext(0), &pb.DisableUserRequest{UserId: 2147483647})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = user ID not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_PermissionDenied", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, true)
+
+		res, err := s.DisableUser(createAuthenticatedContext(1), &pb.DisableUserRequest{UserId: user.id})
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied")
+		assert.Nil(t, res)
+	})
+}
+
+func TestVerifyUserEmail(t *testing.T) {
+	s, err := setUpAdmin()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, false)
+
+		res, err := s.VerifyUserEmail(createAdminContext(0), &pb.VerifyUserEmailRequest{UserId: user.id})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.True(t, res.GetUser().GetIsEmailVerified())
+	})
+
+	t.Run("Failure_InvalidUserId", func(t *testing.T) {
+		res, err := s.VerifyUserEmail(createAdminContext(0), &pb.VerifyUserEmailRequest{})
+		assert.ErrorContains(t, err, "rpc error: code = InvalidArgument desc = failed to validate")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_PermissionDenied", func(t *testing.T) {
+		user := registerAdminTestUser(t, s, false)
+
+		res, err := s.VerifyUserEmail(createAuthenticatedContext(int(user.id)), &pb.VerifyUserEmailRequest{UserId: user.id})
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied")
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// The active tokens a user can hold at a time
+const maxApiTokensPerUser = 20
+
+func apiTokenToPb(apiToken *model.ApiToken) *pb.ApiToken {
+	lastUsedAt := util.GetFullDateStrFromPtr(apiToken.LastUsedAt)
+	expiredAt := util.GetFullDateStrFromPtr(apiToken.ExpiredAt)
+
+	return &pb.ApiToken{
+		Id:          int32(apiToken.ID),
+		Name:        apiToken.Name,
+		TokenPrefix: apiToken.TokenPrefix,
+		Scopes:      apiToken.Scopes,
+		IsRevoked:   apiToken.IsRevoked,
+		LastUsedAt:  lastUsedAt,
+		ExpiredAt:   expiredAt,
+		CreatedAt:   util.GetFullDateStr(apiToken.CreatedAt),
+		UpdatedAt:   util.GetFullDateStr(apiToken.UpdatedAt),
+	}
+}
+
+type ReqCreateApiToken struct {
+	Name          string   `json:"name" validate:"required,min=1,max=64"`
+	Scopes        []string `json:"scopes" validate:"required,min=1,max=2,unique,dive,oneof=tasks:read tasks:write"`
+	ExpiresInDays *int32   `json:"expires_in_days" validate:"omitempty,min=1,max=365"`
+}
+
+func (s *Server) CreateApiToken(ctx context.Context, req *pb.CreateApiTokenRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqCreate := &ReqCreateApiToken{}
+	if err := bindRequest(req, reqCreate); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	// The user row stays locked until the token is created, so that concurrent requests cannot exceed the limit
+	if getUser := model.LockUserByID(tx, claims.UserID); getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	now := time.Now().UTC()
+	activeCount := 0
+	for _, apiToken := range model.ListApiTokensByUserID(tx, claims.UserID) {
+		if apiToken.IsActive(now) {
+			activeCount++
+		}
+	}
+	if activeCount >= maxApiTokensPerUser {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many api tokens, revoke one first")
+	}
+
+	token, tokenErr := util.GenerateApiToken()
+	if tokenErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate api token: %v", tokenErr)
+	}
+
+	var expiredAt *time.Time
+	if reqCreate.ExpiresInDays != nil {
+		t := now.AddDate(0, 0, int(*reqCreate.ExpiresInDays))
+		expiredAt = &t
+	}
+
+	// Only the hash is stored, the token is shown this once
+	fv := &model.ApiTokenFieldValues{
+		UserId:      model.GiveColInt(claims.UserID),
+		Name:        model.GiveColString(reqCreate.Name),
+		TokenHash:   model.GiveColString(util.HashSecretCode(cnf.SecretCodeHashKey, token)),
+		TokenPrefix: model.GiveColString(util.ApiTokenHint(token)),
+		Scopes:      model.GiveColStringArray(reqCreate.Scopes),
+		IsRevoked:   model.GiveColBool(false),
+		ExpiredAt:   model.GiveColNullTime(expiredAt),
+		CreatedAt:   model.GiveColTime(now),
+		UpdatedAt:   model.GiveColTime(now),
+	}
+	created, createErr := model.CreateApiToken(tx, fv)
+	if createErr != nil {
+		log.Error.Printf("failed to create api token: %v", createErr)
+		return nil, status.Errorf(codes.Internal, "failed to create api token: %v", createErr)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to create api token from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to create api token from db tx: %v", comErr)
+	}
+
+	pbApiToken := apiTokenToPb(&model.ApiToken{
+		ID:          created.ID.Val,
+		Name:        reqCreate.Name,
+		TokenPrefix: created.TokenPrefix.Val,
+		Scopes:      reqCreate.Scopes,
+		ExpiredAt:   expiredAt,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	})
+	pbApiToken.Token = &token
+
+	return &pb.Response{
+		Data: &pb.Response_ApiToken{
+			ApiToken: pbApiToken,
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+func (s *Server) ListApiTokens(ctx context.Context, req *pb.ListApiTokensRequest) (*pb.ListResponse, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	pbApiTokens := []*pb.ApiToken{}
+	for _, apiToken := range model.ListApiTokensByUserID(conn, claims.UserID) {
+		pbApiTokens = append(pbApiTokens, apiTokenToPb(&apiToken))
+	}
+
+	return &pb.ListResponse{
+		Data: &pb.ListResponse_ApiTokens{
+			ApiTokens: &pb.ApiTokens{
+				Data: pbApiTokens,
+			},
+		},
+		TotalCount: int32(len(pbApiTokens)),
+		Status:     http.StatusOK,
+		Message:    "ok",
+	}, nil
+}
+
+func (s *Server) RevokeApiToken(ctx context.Context, req *pb.RevokeApiTokenRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqRevoke := &ReqId{}
+	if err := bindRequest(req, reqRevoke); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Tokens of other users are reported as not found
+	revoked, revokeErr := model.RevokeApiToken(conn, int(reqRevoke.Id), claims.UserID)
+	if revokeErr != nil {
+		log.Error.Printf("failed to revoke api token: %v", revokeErr)
+		return nil, status.Errorf(codes.Internal, "failed to revoke api token: %v", revokeErr)
+	}
+	if !revoked {
+		return nil, status.Errorf(codes.NotFound, "api token not found")
+	}
+
+	return &pb.Response{
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
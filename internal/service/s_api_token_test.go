@@ -0,0 +1,314 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func setUpApiToken() (*service.Server, error) {
+	var mockConfigContent bytes.Buffer
+	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
+	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
+	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
+	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
+	mockConfigContent.WriteString("DB_PASS=" + config.SourcePassword + "\n")
+	mockConfigContent.WriteString("DB_NAME=" + config.SourceDataBase + "\n")
+	mockConfigContent.WriteString("SSL_MODE=" + config.SourceSSLMode + "\n")
+	mockConfigContent.WriteString("DB_CONN_MAX_LT_SEC=" + strconv.Itoa(config.SourceDBConnMaxLTSec) + "\n")
+	mockConfigContent.WriteString("DB_MAX_CONN=" + strconv.Itoa(config.SourceMaxConn) + "\n")
+	mockConfigContent.WriteString("DB_MAX_IDLE=" + strconv.Itoa(config.SourceMaxIdle) + "\n")
+	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
+	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
+	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
+	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
+	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
+	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
+	mockConfigContent.WriteString("ENABLE_FILE_OUTPUT=" + strconv.FormatBool(config.EnableFileOutput) + "\n")
+
+	// Create app.env file
+	appFolderPath, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	mockConfigFile := filepath.Join(appFolderPath, "app.env")
+	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
+	defer os.Remove(mockConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Init config
+	loadErr := config.Load()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	// Init log
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, err
+	}
+
+	// Init sql
+	opt := &db.Option{
+		Host:     config.SourceHost,
+		Port:     config.SourcePort,
+		Username: config.SourceUser,
+		Password: config.SourcePassword,
+		DBName:   config.SourceDataBase,
+		SSLMode:  config.SourceSSLMode,
+	}
+
+	err = db.Init(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+
+	return s, nil
+}
+
+// callGateway sends the token through the gateway middleware and returns the status code
+func callGateway(token string, path string) int {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	req := httptest.NewRequest(http.MethodPost, path, nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	middleware.VerifyTokenByGateway(config.Get())(next).ServeHTTP(rec, req)
+
+	return rec.Code
+}
+
+// callGrpc sends the token through the gRPC interceptor and returns the error
+func callGrpc(token string, method string) error {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+token))
+	_, err := middleware.VerifyTokenByGrpc(config.Get())(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, nil
+	})
+
+	return err
+}
+
+func TestCreateApiToken(t *testing.T) {
+	s, err := setUpApiToken()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+		expiresInDays := int32(30)
+
+		res, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+			Name:          "ci",
+			Scopes:        []string{util.ScopeTasksRead},
+			ExpiresInDays: &expiresInDays,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, "ci", res.GetApiToken().Name)
+		assert.Equal(t, []string{util.ScopeTasksRead}, res.GetApiToken().Scopes)
+		assert.True(t, util.IsApiToken(res.GetApiToken().GetToken()))
+		assert.Equal(t, util.ApiTokenHint(res.GetApiToken().GetToken()), res.GetApiToken().TokenPrefix)
+		assert.NotNil(t, res.GetApiToken().ExpiredAt)
+	})
+
+	t.Run("Failure_InvalidScope", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+
+		res, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+			Name:   "ci",
+			Scopes: []string{"users:write"},
+		})
+		assert.ErrorContains(t, err, "rpc error: code = InvalidArgument desc = failed to validate")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_MissingScope", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+
+		res, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+			Name: "ci",
+		})
+		assert.ErrorContains(t, err, "rpc error: code = InvalidArgument desc = failed to validate")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		res, err := s.CreateApiToken(context.Background(), &pb.CreateApiTokenRequest{
+			Name:   "ci",
+			Scopes: []string{util.ScopeTasksRead},
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+	})
+}
+
+func TestListApiTokens(t *testing.T) {
+	s, err := setUpApiToken()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+		for _, name := range []string{"first", "second"} {
+			_, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+				Name:   name,
+				Scopes: []string{util.ScopeTasksWrite},
+			})
+			assert.Nil(t, err)
+		}
+
+		res, err := s.ListApiTokens(ctx, &pb.ListApiTokensRequest{})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, int32(2), res.TotalCount)
+		assert.Len(t, res.GetApiTokens().Data, 2)
+		assert.Equal(t, "second", res.GetApiTokens().Data[0].Name)
+		for _, apiToken := range res.GetApiTokens().Data {
+			assert.Nil(t, apiToken.Token)
+			assert.NotEmpty(t, apiToken.TokenPrefix)
+		}
+	})
+
+	t.Run("Success_OnlyOwnTokens", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+		_, err := s.CreateApiToken(registerUserContext(t, s), &pb.CreateApiTokenRequest{
+			Name:   "other",
+			Scopes: []string{util.ScopeTasksRead},
+		})
+		assert.Nil(t, err)
+
+		res, err := s.ListApiTokens(ctx, &pb.ListApiTokensRequest{})
+		assert.Nil(t, err)
+		assert.Empty(t, res.GetApiTokens().Data)
+	})
+}
+
+func TestRevokeApiToken(t *testing.T) {
+	s, err := setUpApiToken()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+		cRes, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+			Name:   "ci",
+			Scopes: []string{util.ScopeTasksRead},
+		})
+		assert.Nil(t, err)
+		token := cRes.GetApiToken().GetToken()
+		assert.Equal(t, http.StatusOK, callGateway(token, "/v1/task/list"))
+
+		res, err := s.RevokeApiToken(ctx, &pb.RevokeApiTokenRequest{Id: cRes.GetApiToken().Id})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+
+		// The token stops working at once
+		assert.Equal(t, http.StatusUnauthorized, callGateway(token, "/v1/task/list"))
+		assert.EqualError(t, callGrpc(token, "/pb.ToDoList/ListTask"), "invalid token")
+
+		lRes, err := s.ListApiTokens(ctx, &pb.ListApiTokensRequest{})
+		assert.Nil(t, err)
+		assert.True(t, lRes.GetApiTokens().Data[0].IsRevoked)
+	})
+
+	t.Run("Failure_AlreadyRevoked", func(t *testing.T) {
+		ctx := registerUserContext(t, s)
+		cRes, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+			Name:   "ci",
+			Scopes: []string{util.ScopeTasksRead},
+		})
+		assert.Nil(t, err)
+
+		_, err = s.RevokeApiToken(ctx, &pb.RevokeApiTokenRequest{Id: cRes.GetApiToken().Id})
+		assert.Nil(t, err)
+
+		res, err := s.RevokeApiToken(ctx, &pb.RevokeApiTokenRequest{Id: cRes.GetApiToken().Id})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = api token not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_OtherUser", func(t *testing.T) {
+		cRes, err := s.CreateApiToken(registerUserContext(t, s), &pb.CreateApiTokenRequest{
+			Name:   "ci",
+			Scopes: []string{util.ScopeTasksRead},
+		})
+		assert.Nil(t, err)
+
+		res, err := s.RevokeApiToken(registerUserContext(t, s), &pb.RevokeApiTokenRequest{Id: cRes.GetApiToken().Id})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = api token not found")
+		assert.Nil(t, res)
+	})
+}
+
+func TestApiTokenAuthentication(t *testing.T) {
+	s, err := setUpApiToken()
+	assert.NoError(t, err)
+
+	ctx := registerUserContext(t, s)
+	readRes, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+		Name:   "read",
+		Scopes: []string{util.ScopeTasksRead},
+	})
+	assert.Nil(t, err)
+	readToken := readRes.GetApiToken().GetToken()
+
+	writeRes, err := s.CreateApiToken(ctx, &pb.CreateApiTokenRequest{
+		Name:   "write",
+		Scopes: []string{util.ScopeTasksWrite},
+	})
+	assert.Nil(t, err)
+	writeToken := writeRes.GetApiToken().GetToken()
+
+	t.Run("Success_Gateway", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, callGateway(readToken, "/v1/task/list"))
+		assert.Equal(t, http.StatusOK, callGateway(writeToken, "/v1/task/list"))
+		assert.Equal(t, http.StatusOK, callGateway(writeToken, "/v1/task/create"))
+	})
+
+	t.Run("Success_Grpc", func(t *testing.T) {
+		assert.NoError(t, callGrpc(readToken, "/pb.ToDoList/GetTask"))
+		assert.NoError(t, callGrpc(writeToken, "/pb.ToDoList/UpdateTask"))
+	})
+
+	t.Run("Failure_InsufficientScope", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, callGateway(readToken, "/v1/task/create"))
+		assert.EqualError(t, callGrpc(readToken, "/pb.ToDoList/DeleteTask"), "insufficient scope")
+	})
+
+	t.Run("Failure_AccountMethods", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, callGateway(writeToken, "/v1/user/update"))
+		assert.Equal(t, http.StatusForbidden, callGateway(writeToken, "/v1/api_token/create"))
+		assert.EqualError(t, callGrpc(writeToken, "/pb.ToDoList/CreateApiToken"), "insufficient scope")
+	})
+
+	t.Run("Failure_UnknownToken", func(t *testing.T) {
+		token, err := util.GenerateApiToken()
+		assert.NoError(t, err)
+
+		assert.Equal(t, http.StatusUnauthorized, callGateway(token, "/v1/task/list"))
+		assert.EqualError(t, callGrpc(token, "/pb.ToDoList/ListTask"), "invalid token")
+	})
+}
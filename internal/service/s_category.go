@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/middleware"
 	"go-todolist-grpc/internal/model"
 	"go-todolist-grpc/internal/pkg/db"
 	"go-todolist-grpc/internal/pkg/db/condition"
@@ -29,6 +30,12 @@ func (ins ReqCreateCategory) toFieldValues() model.CategoryFieldValues {
 }
 
 func (s *Server) CreateCategory(ctx context.Context, req *pb.CreateCategoryRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -38,12 +45,13 @@ func (s *Server) CreateCategory(ctx context.Context, req *pb.CreateCategoryReque
 	}
 
 	// Check if the category name is already exists
-	getCategory := model.GetCategoryByName(conn, reqCategory.Name)
+	getCategory := model.GetCategoryByName(conn, claims.UserID, reqCategory.Name)
 	if getCategory != nil {
 		return nil, status.Errorf(codes.AlreadyExists, "the category already exists")
 	}
 
 	insFields := reqCategory.toFieldValues()
+	insFields.UserId = model.GiveColInt(claims.UserID)
 	category, categoryErr := model.CreateCategory(conn, &insFields)
 	if categoryErr != nil {
 		return nil, status.Errorf(codes.Internal, "failed to create category: %v", categoryErr)
@@ -51,6 +59,7 @@ func (s *Server) CreateCategory(ctx context.Context, req *pb.CreateCategoryReque
 
 	categoryInfo := &pb.Category{
 		Id:        int32(category.ID.Val),
+		UserId:    int32(category.UserId.Val),
 		Name:      category.Name.Val,
 		CreatedAt: util.GetFullDateStr(category.CreatedAt.Val),
 		UpdatedAt: util.GetFullDateStr(category.UpdatedAt.Val),
@@ -66,6 +75,12 @@ func (s *Server) CreateCategory(ctx context.Context, req *pb.CreateCategoryReque
 }
 
 func (s *Server) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -75,7 +90,7 @@ func (s *Server) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*
 	}
 
 	categoryId := int(reqGet.Id)
-	getCategory := model.GetCategoryByID(conn, categoryId)
+	getCategory := model.GetCategoryByIDAndUserID(conn, categoryId, claims.UserID)
 	if getCategory == nil {
 		return nil, status.Errorf(codes.NotFound, "category ID not found")
 	}
@@ -84,6 +99,7 @@ func (s *Server) GetCategory(ctx context.Context, req *pb.GetCategoryRequest) (*
 		Data: &pb.Response_Category{
 			Category: &pb.Category{
 				Id:        int32(getCategory.ID),
+				UserId:    int32(getCategory.UserId),
 				Name:      getCategory.Name,
 				CreatedAt: util.GetFullDateStr(getCategory.CreatedAt),
 				UpdatedAt: util.GetFullDateStr(getCategory.UpdatedAt),
@@ -119,6 +135,12 @@ func (ins ReqListCategory) toConditions() *model.CategoryConditions {
 }
 
 func (s *Server) ListCategory(ctx context.Context, req *pb.ListCategoryRequest) (*pb.ListResponse, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	reqList := &ReqListCategory{}
@@ -129,6 +151,8 @@ func (s *Server) ListCategory(ctx context.Context, req *pb.ListCategoryRequest)
 	limit := int(reqList.PageSize)
 	offset := int((reqList.Page - 1) * reqList.PageSize)
 	cons := reqList.toConditions()
+	userId := claims.UserID
+	cons.UserId = &condition.Int{EQ: &userId}
 	reqOrderBy := &model.CategoryOrderBy{}
 	if reqList.SortBy != nil {
 		reqOrderBy.Parse(ParseSortBy(*reqList.SortBy))
@@ -144,6 +168,7 @@ func (s *Server) ListCategory(ctx context.Context, req *pb.ListCategoryRequest)
 	for _, category := range listCategory {
 		pbCategories = append(pbCategories, &pb.Category{
 			Id:        int32(category.ID),
+			UserId:    int32(category.UserId),
 			Name:      category.Name,
 			CreatedAt: util.GetFullDateStr(category.CreatedAt),
 			UpdatedAt: util.GetFullDateStr(category.UpdatedAt),
@@ -183,6 +208,12 @@ func (ins ReqUpdateCategory) toFieldValues() (model.CategoryFieldValues, bool) {
 }
 
 func (s *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -192,10 +223,17 @@ func (s *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryReque
 	}
 
 	categoryId := int(reqUpdate.Id)
-	if getCategory := model.GetCategoryByID(conn, categoryId); getCategory == nil {
+	if getCategory := model.GetCategoryByIDAndUserID(conn, categoryId, claims.UserID); getCategory == nil {
 		return nil, status.Errorf(codes.NotFound, "category ID not found")
 	}
 
+	// Check if the new category name is already used by another category
+	if reqUpdate.Name != nil {
+		if getCategory := model.GetCategoryByName(conn, claims.UserID, *reqUpdate.Name); getCategory != nil && getCategory.ID != categoryId {
+			return nil, status.Errorf(codes.AlreadyExists, "the category already exists")
+		}
+	}
+
 	insFields, insCheck := reqUpdate.toFieldValues()
 	if insCheck {
 		tx, txErr := conn.Begin()
@@ -208,7 +246,7 @@ func (s *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryReque
 			return nil, status.Errorf(codes.Internal, "failed to update category: %v", err)
 		}
 
-		getCategory := model.GetCategoryByID(tx, categoryId)
+		getCategory := model.GetCategoryByIDAndUserID(tx, categoryId, claims.UserID)
 		if getCategory == nil {
 			return nil, status.Errorf(codes.NotFound, "category ID not found")
 		}
@@ -223,6 +261,7 @@ func (s *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryReque
 			Data: &pb.Response_Category{
 				Category: &pb.Category{
 					Id:        int32(getCategory.ID),
+					UserId:    int32(getCategory.UserId),
 					Name:      getCategory.Name,
 					CreatedAt: util.GetFullDateStr(getCategory.CreatedAt),
 					UpdatedAt: util.GetFullDateStr(getCategory.UpdatedAt),
@@ -241,6 +280,12 @@ func (s *Server) UpdateCategory(ctx context.Context, req *pb.UpdateCategoryReque
 }
 
 func (s *Server) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -250,7 +295,7 @@ func (s *Server) DeleteCategory(ctx context.Context, req *pb.DeleteCategoryReque
 	}
 
 	categoryId := int(reqDelete.Id)
-	if getCategory := model.GetCategoryByID(conn, categoryId); getCategory == nil {
+	if getCategory := model.GetCategoryByIDAndUserID(conn, categoryId, claims.UserID); getCategory == nil {
 		return nil, status.Errorf(codes.NotFound, "category ID not found")
 	}
 
@@ -6,6 +6,7 @@ import (
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/config"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
 	"go-todolist-grpc/internal/pkg/log"
 	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service"
@@ -36,6 +37,7 @@ func setUpCategory() error {
 	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
 	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
 	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
 	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
 	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
 	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
@@ -59,6 +61,11 @@ func setUpCategory() error {
 	// Init log
 	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
 
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return err
+	}
+
 	// Init sql
 	opt := &db.Option{
 		Host:     config.SourceHost,
@@ -77,19 +84,32 @@ func setUpCategory() error {
 	return nil
 }
 
+func createCategoryOwner(t *testing.T, s *service.Server) context.Context {
+	rReq := &pb.RegisterUserRequest{
+		Email:    util.RandomEmail(),
+		Username: util.RandomString(6),
+		Password: util.RandomString(8),
+	}
+	rRes, err := s.RegisterUser(context.Background(), rReq)
+	assert.Nil(t, err)
+
+	return createAuthenticatedContext(int(rRes.GetUser().Id))
+}
+
 func TestCreateCategory(t *testing.T) {
 	err := setUpCategory()
 	assert.NoError(t, err)
 
 	name := util.RandomString(6)
-	s := service.Server{}
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := createCategoryOwner(t, s)
 
 	t.Run("Sussess", func(t *testing.T) {
 		req := &pb.CreateCategoryRequest{
 			Name: name,
 		}
 
-		res, err := s.CreateCategory(context.Background(), req)
+		res, err := s.CreateCategory(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
@@ -102,7 +122,7 @@ func TestCreateCategory(t *testing.T) {
 			Name: name,
 		}
 
-		res, err := s.CreateCategory(context.Background(), req)
+		res, err := s.CreateCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the category already exists")
 		assert.Nil(t, res)
 
@@ -112,12 +132,39 @@ func TestCreateCategory(t *testing.T) {
 		assert.Contains(t, "the category already exists", st.Message())
 	})
 
+	t.Run("Sussess_SameNameOtherUser", func(t *testing.T) {
+		otherCtx := createCategoryOwner(t, s)
+		req := &pb.CreateCategoryRequest{
+			Name: name,
+		}
+
+		res, err := s.CreateCategory(otherCtx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, name, res.GetCategory().Name)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		req := &pb.CreateCategoryRequest{
+			Name: util.RandomString(6),
+		}
+
+		res, err := s.CreateCategory(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Contains(t, st.Message(), "authentication failed")
+	})
+
 	t.Run("Failure_InvalidName", func(t *testing.T) {
 		req := &pb.CreateCategoryRequest{
 			Name: "",
 		}
 
-		res, err := s.CreateCategory(context.Background(), req)
+		res, err := s.CreateCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqCreateCategory.Name' Error:Field validation for 'Name' failed on the 'required' tag")
 		assert.Nil(t, res)
 
@@ -132,7 +179,7 @@ func TestCreateCategory(t *testing.T) {
 			Name: util.RandomString(129),
 		}
 
-		res, err := s.CreateCategory(context.Background(), req)
+		res, err := s.CreateCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqCreateCategory.Name' Error:Field validation for 'Name' failed on the 'max' tag")
 		assert.Nil(t, res)
 
@@ -147,13 +194,14 @@ func TestGetCategory(t *testing.T) {
 	err := setUpCategory()
 	assert.NoError(t, err)
 
-	s := service.Server{}
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := createCategoryOwner(t, s)
 	name := util.RandomString(6)
 	rReq := &pb.CreateCategoryRequest{
 		Name: name,
 	}
 
-	gRes, rErr := s.CreateCategory(context.Background(), rReq)
+	gRes, rErr := s.CreateCategory(ctx, rReq)
 	assert.Nil(t, rErr)
 
 	t.Run("Sussess", func(t *testing.T) {
@@ -161,7 +209,7 @@ func TestGetCategory(t *testing.T) {
 			Id: gRes.GetCategory().Id,
 		}
 
-		res, err := s.GetCategory(context.Background(), req)
+		res, err := s.GetCategory(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
@@ -174,7 +222,22 @@ func TestGetCategory(t *testing.T) {
 			Id: 999999,
 		}
 
-		res, err := s.GetCategory(context.Background(), req)
+		res, err := s.GetCategory(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "category ID not found", st.Message())
+	})
+	t.Run("Failure_OtherUserCategory", func(t *testing.T) {
+		otherCtx := createCategoryOwner(t, s)
+		req := &pb.GetCategoryRequest{
+			Id: gRes.GetCategory().Id,
+		}
+
+		res, err := s.GetCategory(otherCtx, req)
 		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
 		assert.Nil(t, res)
 
@@ -189,7 +252,12 @@ func TestListCategory(t *testing.T) {
 	err := setUpCategory()
 	assert.NoError(t, err)
 
-	s := service.Server{}
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := createCategoryOwner(t, s)
+	for i := 0; i < 2; i++ {
+		_, cErr := s.CreateCategory(ctx, &pb.CreateCategoryRequest{Name: util.RandomString(6)})
+		assert.Nil(t, cErr)
+	}
 
 	t.Run("Sussess", func(t *testing.T) {
 		sortBy := "-id"
@@ -199,17 +267,32 @@ func TestListCategory(t *testing.T) {
 			SortBy:   &sortBy,
 		}
 
-		res, err := s.ListCategory(context.Background(), req)
+		res, err := s.ListCategory(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
 		assert.Equal(t, "ok", res.Message)
 		assert.NotEmpty(t, res.GetCategories())
+		assert.Equal(t, int32(2), res.TotalCount)
+	})
+
+	t.Run("Sussess_OnlyOwnCategories", func(t *testing.T) {
+		otherCtx := createCategoryOwner(t, s)
+		req := &pb.ListCategoryRequest{
+			Page:     1,
+			PageSize: 999,
+		}
+
+		res, err := s.ListCategory(otherCtx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(0), res.TotalCount)
+		assert.Empty(t, res.GetCategories().GetData())
 	})
 
 	t.Run("Failure_InvalidRequest", func(t *testing.T) {
 		req := &pb.ListCategoryRequest{}
-		res, err := s.ListCategory(context.Background(), req)
+		res, err := s.ListCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqListCategory.Page' Error:Field validation for 'Page' failed on the 'required' tag\nKey: 'ReqListCategory.PageSize' Error:Field validation for 'PageSize' failed on the 'required' tag")
 		assert.Nil(t, res)
 
@@ -227,7 +310,7 @@ func TestListCategory(t *testing.T) {
 			SortBy:   &sortBy,
 		}
 
-		res, err := s.ListCategory(context.Background(), req)
+		res, err := s.ListCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqListCategory.SortBy' Error:Field validation for 'SortBy' failed on the 'max' tag")
 		assert.Nil(t, res)
 
@@ -243,7 +326,7 @@ func TestListCategory(t *testing.T) {
 			PageSize: 1,
 		}
 
-		res, err := s.ListCategory(context.Background(), req)
+		res, err := s.ListCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqListCategory.PageSize' Error:Field validation for 'PageSize' failed on the 'min' tag")
 		assert.Nil(t, res)
 
@@ -258,13 +341,14 @@ func TestUpdateCategory(t *testing.T) {
 	err := setUpCategory()
 	assert.NoError(t, err)
 
-	s := service.Server{}
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := createCategoryOwner(t, s)
 	name := util.RandomString(6)
 	rReq := &pb.CreateCategoryRequest{
 		Name: name,
 	}
 
-	gRes, rErr := s.CreateCategory(context.Background(), rReq)
+	gRes, rErr := s.CreateCategory(ctx, rReq)
 	assert.Nil(t, rErr)
 
 	t.Run("Sussess", func(t *testing.T) {
@@ -274,7 +358,7 @@ func TestUpdateCategory(t *testing.T) {
 			Name: &newName,
 		}
 
-		res, err := s.UpdateCategory(context.Background(), req)
+		res, err := s.UpdateCategory(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
@@ -288,7 +372,24 @@ func TestUpdateCategory(t *testing.T) {
 			Name: &name,
 		}
 
-		res, err := s.UpdateCategory(context.Background(), req)
+		res, err := s.UpdateCategory(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "category ID not found", st.Message())
+	})
+	t.Run("Failure_OtherUserCategory", func(t *testing.T) {
+		otherCtx := createCategoryOwner(t, s)
+		newName := util.RandomString(6)
+		req := &pb.UpdateCategoryRequest{
+			Id:   gRes.GetCategory().Id,
+			Name: &newName,
+		}
+
+		res, err := s.UpdateCategory(otherCtx, req)
 		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
 		assert.Nil(t, res)
 
@@ -303,21 +404,38 @@ func TestDeleteCategory(t *testing.T) {
 	err := setUpCategory()
 	assert.NoError(t, err)
 
-	s := service.Server{}
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := createCategoryOwner(t, s)
 	name := util.RandomString(6)
 	rReq := &pb.CreateCategoryRequest{
 		Name: name,
 	}
 
-	gRes, rErr := s.CreateCategory(context.Background(), rReq)
+	gRes, rErr := s.CreateCategory(ctx, rReq)
 	assert.Nil(t, rErr)
 
+	t.Run("Failure_OtherUserCategory", func(t *testing.T) {
+		otherCtx := createCategoryOwner(t, s)
+		req := &pb.DeleteCategoryRequest{
+			Id: gRes.GetCategory().Id,
+		}
+
+		res, err := s.DeleteCategory(otherCtx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "category ID not found", st.Message())
+	})
+
 	t.Run("Sussess", func(t *testing.T) {
 		req := &pb.DeleteCategoryRequest{
 			Id: gRes.GetCategory().Id,
 		}
 
-		res, err := s.DeleteCategory(context.Background(), req)
+		res, err := s.DeleteCategory(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
@@ -329,7 +447,7 @@ func TestDeleteCategory(t *testing.T) {
 			Id: 999999,
 		}
 
-		res, err := s.DeleteCategory(context.Background(), req)
+		res, err := s.DeleteCategory(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
 		assert.Nil(t, res)
 
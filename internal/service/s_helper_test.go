@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"go-todolist-grpc/internal/service/queue"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+// mockTaskDistributor drops every task, embed it to record only the tasks a test cares about
+type mockTaskDistributor struct{}
+
+func (m *mockTaskDistributor) DistributeTaskSendVerifyEmail(ctx context.Context, payload *queue.PayloadSendVerifyEmail, opts ...asynq.Option) error {
+	return nil
+}
+
+func (m *mockTaskDistributor) DistributeTaskSendResetPassword(ctx context.Context, payload *queue.PayloadSendResetPassword, opts ...asynq.Option) error {
+	return nil
+}
+
+func (m *mockTaskDistributor) DistributeTaskSendTaskReminder(ctx context.Context, payload *queue.PayloadSendTaskReminder, opts ...asynq.Option) error {
+	return nil
+}
+
+func (m *mockTaskDistributor) CancelTaskSendTaskReminder(ctx context.Context, payload *queue.PayloadSendTaskReminder) error {
+	return nil
+}
+
+func createAuthenticatedContext(id int) context.Context {
+	claims := &util.CustomClaims{
+		UserID: id,
+	}
+	claimsJSON, _ := json.Marshal(claims)
+	md := metadata.New(map[string]string{
+		"x-auth-claims": string(claimsJSON),
+	})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+func createAdminContext(id int) context.Context {
+	claims := &util.CustomClaims{
+		UserID: id,
+		Role:   util.RoleAdmin,
+		Permissions: []string{
+			util.PermissionAccount,
+			util.PermissionTasksRead,
+			util.PermissionTasksWrite,
+			util.PermissionUsersRead,
+			util.PermissionUsersWrite,
+		},
+	}
+	claimsJSON, _ := json.Marshal(claims)
+	md := metadata.New(map[string]string{
+		"x-auth-claims": string(claimsJSON),
+	})
+	return metadata.NewIncomingContext(context.Background(), md)
+}
+
+// registerTestUser registers a user with the email and a random password, verifying the email when verified is true.
+// The password is returned along with the user.
+func registerTestUser(t *testing.T, s *service.Server, email string, verified bool) (*pb.User, string) {
+	password := util.RandomString(8)
+	rRes, err := s.RegisterUser(context.Background(), &pb.RegisterUserRequest{
+		Email:    email,
+		Username: util.RandomString(6),
+		Password: password,
+	})
+	assert.Nil(t, err)
+
+	if verified {
+		_, err = s.VerifyUserEmail(createAdminContext(0), &pb.VerifyUserEmailRequest{UserId: rRes.GetUser().Id})
+		assert.Nil(t, err)
+	}
+
+	return rRes.GetUser(), password
+}
+
+// registerUserContext registers a user and returns its context
+func registerUserContext(t *testing.T, s *service.Server) context.Context {
+	user, _ := registerTestUser(t, s, util.RandomEmail(), false)
+
+	return createAuthenticatedContext(int(user.Id))
+}
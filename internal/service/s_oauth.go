@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
+	"go-todolist-grpc/internal/pkg/oauth"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	oauthUsernameMinLen = 3
+	oauthUsernameMaxLen = 32
+)
+
+// LoginWithIdentity signs in the user linked to the identity, linking or creating one on the first login.
+// An email is only trusted when the provider has verified it, and an unverified account is never linked,
+// otherwise whoever registered the address first could take over the account of its owner.
+func (s *Server) LoginWithIdentity(ctx context.Context, identity *oauth.Identity) (*pb.Response, error) {
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	if identity == nil || identity.Provider == "" || identity.Subject == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: missing identity")
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	var getUser *model.User
+	if getIdentity := model.GetIdentityBySubject(tx, identity.Provider, identity.Subject); getIdentity != nil {
+		getUser = model.GetUserByID(tx, getIdentity.UserId)
+		if getUser == nil {
+			return nil, status.Errorf(codes.NotFound, "user ID not found")
+		}
+	} else {
+		if identity.Email == "" || !identity.EmailVerified {
+			return nil, status.Errorf(codes.PermissionDenied, "the email has not been verified by the provider")
+		}
+
+		getUser = model.GetUserByEmail(tx, identity.Email)
+		if getUser != nil && !getUser.IsEmailVerified {
+			return nil, status.Errorf(codes.FailedPrecondition, "the email has been registered but not verified yet")
+		}
+
+		if getUser == nil {
+			var userErr error
+			getUser, userErr = createOAuthUser(tx, cnf, identity)
+			if userErr != nil {
+				return nil, status.Errorf(codes.Internal, "failed to create user: %v", userErr)
+			}
+		}
+
+		now := time.Now().UTC()
+		_, identityErr := model.CreateIdentity(tx, &model.IdentityFieldValues{
+			UserId:    model.GiveColInt(getUser.ID),
+			Provider:  model.GiveColString(identity.Provider),
+			Subject:   model.GiveColString(identity.Subject),
+			Email:     model.GiveColString(identity.Email),
+			CreatedAt: model.GiveColTime(now),
+			UpdatedAt: model.GiveColTime(now),
+		})
+		if identityErr != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create identity: %v", identityErr)
+		}
+	}
+
+	if err := checkUserStatus(getUser); err != nil {
+		return nil, err
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to link identity from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to link identity from db tx: %v", comErr)
+	}
+
+	return signIn(conn, cnf, getUser)
+}
+
+// createOAuthUser creates a user without a usable password, it can be set later by resetting the password
+func createOAuthUser(conn model.DBExecutable, cnf *config.Config, identity *oauth.Identity) (*model.User, error) {
+	hashPassword, hashErr := util.HashPassword(cnf.BcryptCost, util.RandomString(32))
+	if hashErr != nil {
+		return nil, hashErr
+	}
+
+	now := time.Now().UTC()
+	fv := &model.UserFieldValues{
+		Email:           model.GiveColString(util.NormalizeEmail(identity.Email)),
+		Username:        model.GiveColString(oauthUsername(identity)),
+		Password:        model.GiveColString(hashPassword),
+		Status:          model.GiveColBool(true),
+		Role:            model.GiveColString(util.RoleUser),
+		Language:        model.GiveColString(mail.DefaultLocale),
+		IsEmailVerified: model.GiveColBool(true),
+		CreatedAt:       model.GiveColTime(now),
+		UpdatedAt:       model.GiveColTime(now),
+	}
+	user, err := model.CreateUser(conn, fv)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.GetUserByID(conn, user.ID.Val), nil
+}
+
+// oauthUsername uses the display name at the provider, or the local part of the email when it is too short
+func oauthUsername(identity *oauth.Identity) string {
+	name := []rune(strings.TrimSpace(identity.Name))
+	if len(name) < oauthUsernameMinLen {
+		name = []rune(strings.SplitN(identity.Email, "@", 2)[0])
+	}
+	if len(name) < oauthUsernameMinLen {
+		name = append(name, []rune(util.RandomString(oauthUsernameMinLen))...)
+	}
+	if len(name) > oauthUsernameMaxLen {
+		name = name[:oauthUsernameMaxLen]
+	}
+
+	return string(name)
+}
+
+// OAuthHandler serves the login and the callback of the providers of the flow on the gateway,
+// the callback answers with the same body as Login.
+func (s *Server) OAuthHandler(flow *oauth.Flow) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /v1/oauth/{provider}/login", func(w http.ResponseWriter, r *http.Request) {
+		if err := flow.Start(w, r, r.PathValue("provider")); err != nil {
+			writeOAuthError(w, oauthStatus(err))
+		}
+	})
+
+	mux.HandleFunc("GET /v1/oauth/{provider}/callback", func(w http.ResponseWriter, r *http.Request) {
+		identity, err := flow.Finish(w, r, r.PathValue("provider"))
+		if err != nil {
+			writeOAuthError(w, oauthStatus(err))
+			return
+		}
+
+		res, err := s.LoginWithIdentity(r.Context(), identity)
+		if err != nil {
+			writeOAuthError(w, status.Convert(err))
+			return
+		}
+
+		writeOAuthMessage(w, http.StatusOK, res)
+	})
+
+	return mux
+}
+
+func oauthStatus(err error) *status.Status {
+	switch {
+	case errors.Is(err, oauth.ErrUnknownProvider):
+		return status.New(codes.NotFound, err.Error())
+	case errors.Is(err, oauth.ErrInvalidState):
+		return status.New(codes.InvalidArgument, err.Error())
+	default:
+		// The reason may hold details of the provider, it is kept in the log only
+		log.Error.Printf("failed to sign in with oauth: %v", err)
+		return status.New(codes.Unauthenticated, "failed to sign in with the provider")
+	}
+}
+
+// writeOAuthError writes the status the way the gateway writes the errors of the other routes
+func writeOAuthError(w http.ResponseWriter, st *status.Status) {
+	writeOAuthMessage(w, runtime.HTTPStatusFromCode(st.Code()), st.Proto())
+}
+
+func writeOAuthMessage(w http.ResponseWriter, code int, msg proto.Message) {
+	body, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	if err != nil {
+		log.Error.Printf("failed to marshal oauth response: %v", err)
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(code)
+	w.Write(body)
+}
@@ -0,0 +1,276 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/oauth"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockProvider stands for a provider that has already verified the code, the flow itself is tested in the oauth package
+type mockProvider struct {
+	identity *oauth.Identity
+}
+
+func (p *mockProvider) Name() string {
+	return "mock"
+}
+
+func (p *mockProvider) AuthCodeURL(state string, nonce string, verifier string) string {
+	return "https://provider.test/authorize?state=" + url.QueryEscape(state)
+}
+
+func (p *mockProvider) Exchange(ctx context.Context, code string, verifier string, nonce string) (*oauth.Identity, error) {
+	if code != "good-code" {
+		return nil, oauth.ErrInvalidIDToken
+	}
+
+	return p.identity, nil
+}
+
+func setUpOAuth() (*service.Server, error) {
+	var mockConfigContent bytes.Buffer
+	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
+	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+	mockConfigContent.WriteString("TOTP_ENCRYPTION_KEY=" + config.TotpEncryptionKey + "\n")
+	mockConfigContent.WriteString("TOTP_ISSUER=" + config.TotpIssuer + "\n")
+	mockConfigContent.WriteString("TOTP_CHALLENGE_TTL=" + strconv.Itoa(config.TotpChallengeTtl) + "\n")
+	mockConfigContent.WriteString("OAUTH_STATE_KEY=" + config.OauthStateKey + "\n")
+	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
+	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
+	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
+	mockConfigContent.WriteString("DB_PASS=" + config.SourcePassword + "\n")
+	mockConfigContent.WriteString("DB_NAME=" + config.SourceDataBase + "\n")
+	mockConfigContent.WriteString("SSL_MODE=" + config.SourceSSLMode + "\n")
+	mockConfigContent.WriteString("DB_CONN_MAX_LT_SEC=" + strconv.Itoa(config.SourceDBConnMaxLTSec) + "\n")
+	mockConfigContent.WriteString("DB_MAX_CONN=" + strconv.Itoa(config.SourceMaxConn) + "\n")
+	mockConfigContent.WriteString("DB_MAX_IDLE=" + strconv.Itoa(config.SourceMaxIdle) + "\n")
+	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
+	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
+	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
+	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
+	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
+	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
+	mockConfigContent.WriteString("ENABLE_FILE_OUTPUT=" + strconv.FormatBool(config.EnableFileOutput) + "\n")
+
+	// Create app.env file
+	appFolderPath, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	mockConfigFile := filepath.Join(appFolderPath, "app.env")
+	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
+	defer os.Remove(mockConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Init config
+	loadErr := config.Load()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	// Init log
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, err
+	}
+
+	// Init sql
+	opt := &db.Option{
+		Host:     config.SourceHost,
+		Port:     config.SourcePort,
+		Username: config.SourceUser,
+		Password: config.SourcePassword,
+		DBName:   config.SourceDataBase,
+		SSLMode:  config.SourceSSLMode,
+	}
+
+	err = db.Init(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+
+	return s, nil
+}
+
+func randomIdentity() *oauth.Identity {
+	return &oauth.Identity{
+		Provider:      "mock",
+		Subject:       util.RandomString(16),
+		Email:         util.RandomEmail(),
+		EmailVerified: true,
+		Name:          util.RandomString(8),
+	}
+}
+
+func TestLoginWithIdentity(t *testing.T) {
+	s, err := setUpOAuth()
+	assert.NoError(t, err)
+
+	t.Run("Success_CreateUser", func(t *testing.T) {
+		identity := randomIdentity()
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, identity.Email, res.GetUser().Email)
+		assert.Equal(t, identity.Name, res.GetUser().Username)
+		assert.NotEmpty(t, res.GetUser().GetToken())
+		assert.NotEmpty(t, res.GetUser().GetRefreshToken())
+
+		// The next login finds the same user through the identity
+		res2, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.Nil(t, err)
+		assert.Equal(t, res.GetUser().Id, res2.GetUser().Id)
+	})
+
+	t.Run("Success_LinkVerifiedUser", func(t *testing.T) {
+		identity := randomIdentity()
+		user, _ := registerTestUser(t, s, identity.Email, true)
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.Nil(t, err)
+		assert.Equal(t, user.Id, res.GetUser().Id)
+		assert.NotEmpty(t, res.GetUser().GetToken())
+
+		// The identity stays linked when the email changes at the provider
+		identity.Email = util.RandomEmail()
+		res, err = s.LoginWithIdentity(context.Background(), identity)
+		assert.Nil(t, err)
+		assert.Equal(t, user.Id, res.GetUser().Id)
+	})
+
+	t.Run("Success_ShortName", func(t *testing.T) {
+		identity := randomIdentity()
+		identity.Name = ""
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.Nil(t, err)
+		assert.GreaterOrEqual(t, len(res.GetUser().Username), 3)
+	})
+
+	t.Run("Failure_UnverifiedUser", func(t *testing.T) {
+		identity := randomIdentity()
+		registerTestUser(t, s, identity.Email, false)
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = the email has been registered but not verified yet")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_EmailNotVerifiedByProvider", func(t *testing.T) {
+		identity := randomIdentity()
+		identity.EmailVerified = false
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = the email has not been verified by the provider")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_MissingSubject", func(t *testing.T) {
+		identity := randomIdentity()
+		identity.Subject = ""
+
+		res, err := s.LoginWithIdentity(context.Background(), identity)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: missing identity")
+		assert.Nil(t, res)
+	})
+}
+
+func TestOAuthHandler(t *testing.T) {
+	s, err := setUpOAuth()
+	assert.NoError(t, err)
+
+	identity := randomIdentity()
+	flow, err := oauth.NewFlow(config.OauthStateKey, false, &mockProvider{identity: identity})
+	assert.NoError(t, err)
+	handler := s.OAuthHandler(flow)
+
+	// login starts the flow and returns the cookie and the state sent to the provider
+	login := func(t *testing.T) (*http.Cookie, string) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/oauth/mock/login", nil))
+		assert.Equal(t, http.StatusFound, rec.Code)
+
+		location, err := url.Parse(rec.Header().Get("Location"))
+		assert.NoError(t, err)
+		cookies := rec.Result().Cookies()
+		assert.Len(t, cookies, 1)
+
+		return cookies[0], location.Query().Get("state")
+	}
+
+	callback := func(cookie *http.Cookie, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/v1/oauth/mock/callback?"+query, nil)
+		if cookie != nil {
+			req.AddCookie(cookie)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		return rec
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		cookie, state := login(t)
+
+		rec := callback(cookie, "code=good-code&state="+url.QueryEscape(state))
+		assert.Equal(t, http.StatusOK, rec.Code)
+
+		body := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		user, _ := body["user"].(map[string]interface{})
+		assert.Equal(t, identity.Email, user["email"])
+		assert.NotEmpty(t, user["token"])
+		assert.NotEmpty(t, user["refresh_token"])
+	})
+
+	t.Run("Failure_StateMismatch", func(t *testing.T) {
+		cookie, _ := login(t)
+
+		rec := callback(cookie, "code=good-code&state=other")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Failure_MissingCookie", func(t *testing.T) {
+		_, state := login(t)
+
+		rec := callback(nil, "code=good-code&state="+url.QueryEscape(state))
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+	})
+
+	t.Run("Failure_BadCode", func(t *testing.T) {
+		cookie, state := login(t)
+
+		rec := callback(cookie, "code=bad-code&state="+url.QueryEscape(state))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("Failure_UnknownProvider", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/oauth/other/login", nil))
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service/queue"
+	"net/http"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Minimum interval between two reset password emails to the same address
+const requestPasswordResetCooldown = time.Minute
+
+type ReqRequestPasswordReset struct {
+	Email string `json:"email" validate:"required,email,max=64"`
+}
+
+func (s *Server) RequestPasswordReset(ctx context.Context, req *pb.RequestPasswordResetRequest) (*pb.Response, error) {
+	conn := db.GetConn()
+
+	// Validate request
+	reqRequestPasswordReset := &ReqRequestPasswordReset{}
+	if err := bindRequest(req, reqRequestPasswordReset); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	okResponse := &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}
+
+	// Always answer ok so that the response does not reveal whether the email is registered
+	getUser := model.GetUserByEmail(conn, reqRequestPasswordReset.Email)
+	if getUser == nil {
+		return okResponse, nil
+	}
+
+	// Enforce the cooldown per email, silently for the same reason
+	if getPasswordReset := model.GetPasswordResetSince(conn, getUser.Email, time.Now().UTC().Add(-requestPasswordResetCooldown)); getPasswordReset != nil {
+		return okResponse, nil
+	}
+
+	// Define options for the asynq, the unique lock also covers the window before the task creates its row
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(queue.QueueSendMail),
+		asynq.Unique(requestPasswordResetCooldown),
+	}
+
+	// Distribute the task to send a reset password email
+	taskPayload := &queue.PayloadSendResetPassword{
+		UserId: getUser.ID,
+	}
+
+	sendEmailErr := s.taskDistributor.DistributeTaskSendResetPassword(ctx, taskPayload, opts...)
+	if errors.Is(sendEmailErr, asynq.ErrDuplicateTask) {
+		return okResponse, nil
+	}
+	if sendEmailErr != nil {
+		log.Error.Printf("failed to distribute task to send reset password email: %v", sendEmailErr)
+		return nil, status.Errorf(codes.Internal, "failed to distribute task to send reset password email: %v", sendEmailErr)
+	}
+
+	return okResponse, nil
+}
+
+type ReqResetPassword struct {
+	Token    string `json:"token" validate:"required,max=512"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+func (s *Server) ResetPassword(ctx context.Context, req *pb.ResetPasswordRequest) (*pb.Response, error) {
+	cnf := config.Get()
+	conn := db.GetConn()
+	now := time.Now().UTC()
+
+	// Validate request
+	reqResetPassword := &ReqResetPassword{}
+	if err := bindRequest(req, reqResetPassword); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// The token carries the password reset ID and secret code, signed when the email was sent
+	linkClaims, linkErr := parseLinkToken(util.LinkPurposeResetPassword, reqResetPassword.Token)
+	if linkErr != nil {
+		return nil, linkErr
+	}
+
+	// Check if the code is still usable
+	passwordResetId := linkClaims.ID
+	getPasswordReset := model.GetPasswordResetByID(conn, passwordResetId, false, &now)
+	if getPasswordReset == nil {
+		return nil, status.Errorf(codes.NotFound, "password reset ID not found")
+	}
+
+	if !util.CheckSecretCodeHash(cnf.SecretCodeHashKey, linkClaims.SecretCode, getPasswordReset.SecretCodeHash) {
+		return nil, status.Errorf(codes.InvalidArgument, "secret code is incorrect")
+	}
+
+	// Hash password
+	hashPassword, hashPasswordErr := util.HashPassword(cnf.BcryptCost, reqResetPassword.Password)
+	if hashPasswordErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to hash password: %v", hashPasswordErr)
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	used, usedErr := model.UsePasswordReset(tx, passwordResetId)
+	if usedErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update password reset: %v", usedErr)
+	}
+	if !used {
+		return nil, status.Errorf(codes.NotFound, "password reset ID not found")
+	}
+
+	if err := model.UpdateUser(tx, getPasswordReset.UserId, &model.UserFieldValues{
+		Password:  model.GiveColString(hashPassword),
+		UpdatedAt: model.GiveColTime(now),
+	}); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	// Sign out every session that may have been opened with the old password
+	if err := model.RevokeRefreshTokensByUserID(tx, getPasswordReset.UserId); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke token: %v", err)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to reset password from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to reset password from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
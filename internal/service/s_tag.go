@@ -0,0 +1,312 @@
+package service
+
+import (
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/db/condition"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func tagToPb(tag *model.Tag) *pb.Tag {
+	return &pb.Tag{
+		Id:        int32(tag.ID),
+		Name:      tag.Name,
+		CreatedAt: util.GetFullDateStr(tag.CreatedAt),
+		UpdatedAt: util.GetFullDateStr(tag.UpdatedAt),
+	}
+}
+
+func tagsToPb(tags []model.Tag) []*pb.Tag {
+	pbTags := []*pb.Tag{}
+	for _, tag := range tags {
+		pbTags = append(pbTags, tagToPb(&tag))
+	}
+
+	return pbTags
+}
+
+// getUserTags returns the tags of the user by their IDs, a tag of another user is reported as not found
+func getUserTags(conn model.DBExecutable, userId int, tagIds []int32) ([]int, []model.Tag, error) {
+	ids := make([]int, 0, len(tagIds))
+	for _, tagId := range tagIds {
+		ids = append(ids, int(tagId))
+	}
+
+	tags := model.ListTagsByIDs(conn, userId, ids)
+	if len(tags) != len(ids) {
+		return nil, nil, status.Errorf(codes.NotFound, "tag ID not found")
+	}
+
+	return ids, tags, nil
+}
+
+type ReqCreateTag struct {
+	Name string `json:"name" validate:"required,min=1,max=32"`
+}
+
+func (ins ReqCreateTag) toFieldValues() model.TagFieldValues {
+	now := time.Now().UTC()
+	fv := model.TagFieldValues{}
+	fv.Name = model.GiveColString(ins.Name)
+	fv.CreatedAt = model.GiveColTime(now)
+	fv.UpdatedAt = model.GiveColTime(now)
+	return fv
+}
+
+func (s *Server) CreateTag(ctx context.Context, req *pb.CreateTagRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqTag := &ReqCreateTag{}
+	if err := bindRequest(req, reqTag); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Check if the tag name is already exists
+	if getTag := model.GetTagByName(conn, claims.UserID, reqTag.Name); getTag != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "the tag already exists")
+	}
+
+	insFields := reqTag.toFieldValues()
+	insFields.UserId = model.GiveColInt(claims.UserID)
+	tag, tagErr := model.CreateTag(conn, &insFields)
+	if tagErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create tag: %v", tagErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_Tag{
+			Tag: &pb.Tag{
+				Id:        int32(tag.ID.Val),
+				Name:      tag.Name.Val,
+				CreatedAt: util.GetFullDateStr(tag.CreatedAt.Val),
+				UpdatedAt: util.GetFullDateStr(tag.UpdatedAt.Val),
+			},
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+func (s *Server) GetTag(ctx context.Context, req *pb.GetTagRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqGet := &ReqId{}
+	if err := bindRequest(req, reqGet); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	getTag := model.GetTagByIDAndUserID(conn, int(reqGet.Id), claims.UserID)
+	if getTag == nil {
+		return nil, status.Errorf(codes.NotFound, "tag ID not found")
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_Tag{
+			Tag: tagToPb(getTag),
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+type ReqListTag struct {
+	Page     int32   `json:"page" validate:"required,min=1,max=100000"`
+	PageSize int32   `json:"page_size" validate:"required,min=5,max=1000"`
+	SortBy   *string `json:"sort_by" validate:"omitempty,max=10"`
+	Name     *string `json:"name" validate:"omitempty,min=1,max=32"`
+}
+
+func (ins ReqListTag) toConditions() *model.TagConditions {
+	cons := &model.TagConditions{}
+
+	if ins.Name != nil {
+		tagName := *ins.Name
+		cons.Name = &condition.String{StartAt: &tagName}
+	}
+
+	return cons
+}
+
+func (s *Server) ListTag(ctx context.Context, req *pb.ListTagRequest) (*pb.ListResponse, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	reqList := &ReqListTag{}
+	if err := bindRequest(req, reqList); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	limit := int(reqList.PageSize)
+	offset := int((reqList.Page - 1) * reqList.PageSize)
+	cons := reqList.toConditions()
+	userId := claims.UserID
+	cons.UserId = &condition.Int{EQ: &userId}
+	reqOrderBy := &model.TagOrderBy{}
+	if reqList.SortBy != nil {
+		reqOrderBy.Parse(ParseSortBy(*reqList.SortBy))
+	}
+
+	listTag := model.ListTag(conn, cons, reqOrderBy, &limit, &offset)
+	count, err := model.GetTagCount(conn, cons)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get tag count: %v", err)
+	}
+
+	return &pb.ListResponse{
+		Data: &pb.ListResponse_Tags{
+			Tags: &pb.Tags{
+				Data: tagsToPb(listTag),
+			},
+		},
+		TotalCount: count,
+		Page:       reqList.Page,
+		PageSize:   reqList.PageSize,
+		Status:     http.StatusOK,
+		Message:    "ok",
+	}, nil
+}
+
+type ReqUpdateTag struct {
+	Id   int32   `json:"id" validate:"required,min=1"`
+	Name *string `json:"name" validate:"omitempty,min=1,max=32"`
+}
+
+func (ins ReqUpdateTag) toFieldValues() (model.TagFieldValues, bool) {
+	requiredCheck := false
+	fv := model.TagFieldValues{}
+	fv.ID = model.GiveColInt(int(ins.Id))
+
+	if ins.Name != nil {
+		requiredCheck = true
+		fv.Name = model.GiveColString(*ins.Name)
+		fv.UpdatedAt = model.GiveColTime(time.Now().UTC())
+	}
+
+	return fv, requiredCheck
+}
+
+func (s *Server) UpdateTag(ctx context.Context, req *pb.UpdateTagRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqUpdate := &ReqUpdateTag{}
+	if err := bindRequest(req, reqUpdate); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	tagId := int(reqUpdate.Id)
+	if getTag := model.GetTagByIDAndUserID(conn, tagId, claims.UserID); getTag == nil {
+		return nil, status.Errorf(codes.NotFound, "tag ID not found")
+	}
+
+	// Check if the new tag name is already used by another tag
+	if reqUpdate.Name != nil {
+		if getTag := model.GetTagByName(conn, claims.UserID, *reqUpdate.Name); getTag != nil && getTag.ID != tagId {
+			return nil, status.Errorf(codes.AlreadyExists, "the tag already exists")
+		}
+	}
+
+	insFields, insCheck := reqUpdate.toFieldValues()
+	if insCheck {
+		tx, txErr := conn.Begin()
+		if txErr != nil {
+			return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+		}
+		defer tx.Rollback()
+
+		if err := model.UpdateTag(tx, tagId, &insFields); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to update tag: %v", err)
+		}
+
+		getTag := model.GetTagByIDAndUserID(tx, tagId, claims.UserID)
+		if getTag == nil {
+			return nil, status.Errorf(codes.NotFound, "tag ID not found")
+		}
+
+		comErr := tx.Commit()
+		if comErr != nil {
+			log.Error.Printf("failed to update tag from db tx: %v", comErr)
+			return nil, status.Errorf(codes.Internal, "failed to update tag from db tx: %v", comErr)
+		}
+
+		return &pb.Response{
+			Data: &pb.Response_Tag{
+				Tag: tagToPb(getTag),
+			},
+			Status:  http.StatusOK,
+			Message: "ok",
+		}, nil
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+// DeleteTag deletes the tag, the tasks keep their other tags
+func (s *Server) DeleteTag(ctx context.Context, req *pb.DeleteTagRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqDelete := &ReqId{}
+	if err := bindRequest(req, reqDelete); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	tagId := int(reqDelete.Id)
+	if getTag := model.GetTagByIDAndUserID(conn, tagId, claims.UserID); getTag == nil {
+		return nil, status.Errorf(codes.NotFound, "tag ID not found")
+	}
+
+	if err := model.DeleteTag(conn, tagId); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete tag: %v", err)
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
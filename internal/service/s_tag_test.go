@@ -0,0 +1,336 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func setUpTag() error {
+	var mockConfigContent bytes.Buffer
+	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
+	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
+	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
+	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
+	mockConfigContent.WriteString("DB_PASS=" + config.SourcePassword + "\n")
+	mockConfigContent.WriteString("DB_NAME=" + config.SourceDataBase + "\n")
+	mockConfigContent.WriteString("SSL_MODE=" + config.SourceSSLMode + "\n")
+	mockConfigContent.WriteString("DB_CONN_MAX_LT_SEC=" + strconv.Itoa(config.SourceDBConnMaxLTSec) + "\n")
+	mockConfigContent.WriteString("DB_MAX_CONN=" + strconv.Itoa(config.SourceMaxConn) + "\n")
+	mockConfigContent.WriteString("DB_MAX_IDLE=" + strconv.Itoa(config.SourceMaxIdle) + "\n")
+	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
+	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
+	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
+	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
+	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
+	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
+	mockConfigContent.WriteString("ENABLE_FILE_OUTPUT=" + strconv.FormatBool(config.EnableFileOutput) + "\n")
+
+	// Create app.env file
+	appFolderPath, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	mockConfigFile := filepath.Join(appFolderPath, "app.env")
+	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
+	defer os.Remove(mockConfigFile)
+	if err != nil {
+		return err
+	}
+
+	// Init config
+	loadErr := config.Load()
+	if loadErr != nil {
+		return loadErr
+	}
+
+	// Init log
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return err
+	}
+
+	// Init sql
+	opt := &db.Option{
+		Host:     config.SourceHost,
+		Port:     config.SourcePort,
+		Username: config.SourceUser,
+		Password: config.SourcePassword,
+		DBName:   config.SourceDataBase,
+		SSLMode:  config.SourceSSLMode,
+	}
+
+	err = db.Init(opt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func TestCreateTag(t *testing.T) {
+	err := setUpTag()
+	assert.NoError(t, err)
+
+	name := util.RandomString(6)
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := registerUserContext(t, s)
+
+	t.Run("Sussess", func(t *testing.T) {
+		req := &pb.CreateTagRequest{
+			Name: name,
+		}
+
+		res, err := s.CreateTag(ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.NotEmpty(t, res.GetTag().Id)
+		assert.Equal(t, name, res.GetTag().Name)
+	})
+
+	t.Run("Failure_ExistingName", func(t *testing.T) {
+		req := &pb.CreateTagRequest{
+			Name: name,
+		}
+
+		res, err := s.CreateTag(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the tag already exists")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+	})
+
+	t.Run("Sussess_SameNameOtherUser", func(t *testing.T) {
+		otherCtx := registerUserContext(t, s)
+		req := &pb.CreateTagRequest{
+			Name: name,
+		}
+
+		res, err := s.CreateTag(otherCtx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, name, res.GetTag().Name)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		req := &pb.CreateTagRequest{
+			Name: util.RandomString(6),
+		}
+
+		res, err := s.CreateTag(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_LongName", func(t *testing.T) {
+		req := &pb.CreateTagRequest{
+			Name: util.RandomString(33),
+		}
+
+		res, err := s.CreateTag(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqCreateTag.Name' Error:Field validation for 'Name' failed on the 'max' tag")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	})
+}
+
+func TestGetTag(t *testing.T) {
+	err := setUpTag()
+	assert.NoError(t, err)
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := registerUserContext(t, s)
+	cRes, cErr := s.CreateTag(ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+	assert.Nil(t, cErr)
+
+	t.Run("Sussess", func(t *testing.T) {
+		req := &pb.GetTagRequest{
+			Id: cRes.GetTag().Id,
+		}
+
+		res, err := s.GetTag(ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, cRes.GetTag().Name, res.GetTag().Name)
+	})
+
+	t.Run("Failure_OtherUserTag", func(t *testing.T) {
+		otherCtx := registerUserContext(t, s)
+		req := &pb.GetTagRequest{
+			Id: cRes.GetTag().Id,
+		}
+
+		res, err := s.GetTag(otherCtx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+	})
+}
+
+func TestListTag(t *testing.T) {
+	err := setUpTag()
+	assert.NoError(t, err)
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := registerUserContext(t, s)
+	prefix := util.RandomString(6)
+	for _, name := range []string{prefix + "a", prefix + "b", util.RandomString(6)} {
+		_, cErr := s.CreateTag(ctx, &pb.CreateTagRequest{Name: name})
+		assert.Nil(t, cErr)
+	}
+
+	t.Run("Sussess", func(t *testing.T) {
+		sortBy := "name"
+		req := &pb.ListTagRequest{
+			Page:     1,
+			PageSize: 999,
+			SortBy:   &sortBy,
+		}
+
+		res, err := s.ListTag(ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, int32(3), res.TotalCount)
+	})
+
+	t.Run("Sussess_ByName", func(t *testing.T) {
+		sortBy := "name"
+		req := &pb.ListTagRequest{
+			Page:     1,
+			PageSize: 999,
+			SortBy:   &sortBy,
+			Name:     &prefix,
+		}
+
+		res, err := s.ListTag(ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), res.TotalCount)
+		assert.Equal(t, prefix+"a", res.GetTags().GetData()[0].Name)
+	})
+
+	t.Run("Sussess_OnlyOwnTags", func(t *testing.T) {
+		otherCtx := registerUserContext(t, s)
+		req := &pb.ListTagRequest{
+			Page:     1,
+			PageSize: 999,
+		}
+
+		res, err := s.ListTag(otherCtx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(0), res.TotalCount)
+		assert.Empty(t, res.GetTags().GetData())
+	})
+}
+
+func TestUpdateTag(t *testing.T) {
+	err := setUpTag()
+	assert.NoError(t, err)
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := registerUserContext(t, s)
+	cRes, cErr := s.CreateTag(ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+	assert.Nil(t, cErr)
+	otherRes, otherErr := s.CreateTag(ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+	assert.Nil(t, otherErr)
+
+	t.Run("Sussess", func(t *testing.T) {
+		newName := util.RandomString(6)
+		req := &pb.UpdateTagRequest{
+			Id:   cRes.GetTag().Id,
+			Name: &newName,
+		}
+
+		res, err := s.UpdateTag(ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, newName, res.GetTag().Name)
+	})
+
+	t.Run("Failure_ExistingName", func(t *testing.T) {
+		req := &pb.UpdateTagRequest{
+			Id:   cRes.GetTag().Id,
+			Name: &otherRes.GetTag().Name,
+		}
+
+		res, err := s.UpdateTag(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the tag already exists")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_OtherUserTag", func(t *testing.T) {
+		otherCtx := registerUserContext(t, s)
+		newName := util.RandomString(6)
+		req := &pb.UpdateTagRequest{
+			Id:   cRes.GetTag().Id,
+			Name: &newName,
+		}
+
+		res, err := s.UpdateTag(otherCtx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, res)
+	})
+}
+
+func TestDeleteTag(t *testing.T) {
+	err := setUpTag()
+	assert.NoError(t, err)
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+	ctx := registerUserContext(t, s)
+	cRes, cErr := s.CreateTag(ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+	assert.Nil(t, cErr)
+
+	t.Run("Failure_OtherUserTag", func(t *testing.T) {
+		otherCtx := registerUserContext(t, s)
+		req := &pb.DeleteTagRequest{
+			Id: cRes.GetTag().Id,
+		}
+
+		res, err := s.DeleteTag(otherCtx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Sussess", func(t *testing.T) {
+		req := &pb.DeleteTagRequest{
+			Id: cRes.GetTag().Id,
+		}
+
+		res, err := s.DeleteTag(ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+
+		gRes, gErr := s.GetTag(ctx, &pb.GetTagRequest{Id: cRes.GetTag().Id})
+		assert.EqualError(t, gErr, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, gRes)
+	})
+}
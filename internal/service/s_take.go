@@ -2,14 +2,18 @@ package service
 
 import (
 	"context"
+	"database/sql"
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/middleware"
 	"go-todolist-grpc/internal/model"
 	"go-todolist-grpc/internal/pkg/db"
 	"go-todolist-grpc/internal/pkg/db/condition"
 	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/rrule"
 	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service/queue"
 	"net/http"
+	"slices"
 	"time"
 
 	"google.golang.org/grpc/codes"
@@ -23,6 +27,10 @@ type ReqCreateTask struct {
 	Url             *string `json:"url" validate:"omitempty,max=255"`
 	SpecifyDatetime *int64  `json:"specify_datetime" validate:"omitempty,min=1"`
 	Priority        int32   `json:"priority" validate:"required,oneof=1 2 3"`
+	ParentId        *int32  `json:"parent_id" validate:"omitempty,min=1"`
+	RecurrenceRule  *string `json:"recurrence_rule" validate:"omitempty,max=255"`
+	ReminderOffsets []int32 `json:"reminder_offsets" validate:"omitempty,max=5,unique,dive,min=0,max=10080"`
+	TagIds          []int32 `json:"tag_ids" validate:"omitempty,max=20,unique,dive,min=1"`
 }
 
 func (ins ReqCreateTask) toFieldValues() model.TaskFieldValues {
@@ -49,6 +57,19 @@ func (ins ReqCreateTask) toFieldValues() model.TaskFieldValues {
 	fv.IsSpecifyTime = model.GiveColBool(isSpecifyTime)
 
 	fv.Priority = model.GiveColInt(int(ins.Priority))
+
+	var parentId *int
+	if ins.ParentId != nil {
+		parentId = util.Pointer(int(*ins.ParentId))
+	}
+	fv.ParentId = model.GiveColNullInt(parentId)
+
+	// The task is the first occurrence of its rule
+	if ins.RecurrenceRule != nil {
+		fv.RecurrenceRule = model.GiveColNullString(ins.RecurrenceRule)
+		fv.RecurrenceIndex = model.GiveColInt(1)
+	}
+
 	fv.CreatedAt = model.GiveColTime(now)
 	fv.UpdatedAt = model.GiveColTime(now)
 
@@ -68,9 +89,56 @@ func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb
 		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
 	}
 
-	// Check if the task title is already exists
+	// Check if the category belongs to the user
 	conn := db.GetConn()
-	getTask := model.GetTaskByTitle(conn, reqTask.Title)
+	if getCategory := model.GetCategoryByIDAndUserID(conn, int(reqTask.CategoryId), claims.UserID); getCategory == nil {
+		return nil, status.Errorf(codes.NotFound, "category ID not found")
+	}
+
+	// Only a top-level task of the user can have subtasks
+	if reqTask.ParentId != nil {
+		getParent := model.GetTaskByIDAndUserID(conn, int(*reqTask.ParentId), claims.UserID)
+		if getParent == nil {
+			return nil, status.Errorf(codes.NotFound, "parent task ID not found")
+		}
+		if getParent.ParentId != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "a subtask cannot have subtasks")
+		}
+		// The next occurrence does not carry the subtasks over, completing them could not schedule it
+		if getParent.RecurrenceRule != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "a recurring task cannot have subtasks")
+		}
+	}
+
+	// Only a top-level task with a datetime to schedule the occurrences from can recur
+	if reqTask.RecurrenceRule != nil {
+		rule, ruleErr := rrule.Parse(*reqTask.RecurrenceRule)
+		if ruleErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", ruleErr)
+		}
+		if reqTask.SpecifyDatetime == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "a recurring task requires specify_datetime")
+		}
+		if reqTask.ParentId != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "a subtask cannot recur")
+		}
+		reqTask.RecurrenceRule = util.Pointer(rule.String())
+	}
+
+	// A reminder is sent before the datetime of the task
+	if len(reqTask.ReminderOffsets) > 0 && reqTask.SpecifyDatetime == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "reminders require specify_datetime")
+	}
+	slices.Sort(reqTask.ReminderOffsets)
+
+	// Check if the tags belong to the user
+	tagIds, tags, tagErr := getUserTags(conn, claims.UserID, reqTask.TagIds)
+	if tagErr != nil {
+		return nil, tagErr
+	}
+
+	// Check if the task title is already exists in the category
+	getTask := model.GetOpenTaskByTitle(conn, claims.UserID, int(reqTask.CategoryId), reqTask.Title)
 	if getTask != nil {
 		return nil, status.Errorf(codes.AlreadyExists, "the task already exists")
 	}
@@ -78,11 +146,47 @@ func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb
 	insFields := reqTask.toFieldValues()
 	insFields.UserId = model.GiveColInt(claims.UserID)
 
-	task, taskErr := model.CreateTask(conn, &insFields)
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	task, taskErr := model.CreateTask(tx, &insFields)
 	if taskErr != nil {
+		if db.IsUniqueViolation(taskErr) {
+			return nil, status.Errorf(codes.AlreadyExists, "the task already exists")
+		}
 		return nil, status.Errorf(codes.Internal, "failed to create task: %v", taskErr)
 	}
 
+	reminderPayloads, reminderErr := scheduleTaskReminders(tx, task.ID.Val, task.SpecifyDatetime.Val, reqTask.ReminderOffsets)
+	if reminderErr != nil {
+		return nil, reminderErr
+	}
+
+	if err := model.ReplaceTaskTags(tx, task.ID.Val, tagIds); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to set task tags: %v", err)
+	}
+
+	// An open subtask reopens its parent
+	if reqTask.ParentId != nil {
+		if err := model.RollUpTaskCompletion(tx, int(*reqTask.ParentId)); err != nil {
+			if db.IsUniqueViolation(err) {
+				return nil, status.Errorf(codes.AlreadyExists, "the parent task conflicts with an open task of the same title")
+			}
+			return nil, status.Errorf(codes.Internal, "failed to roll up task completion: %v", err)
+		}
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to create task from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to create task from db tx: %v", comErr)
+	}
+
+	s.enqueueTaskReminders(ctx, reminderPayloads)
+
 	taskInfo := &pb.Task{
 		Id:              int32(task.ID.Val),
 		UserId:          int32(task.UserId.Val),
@@ -96,6 +200,11 @@ func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb
 		IsComplete:      task.IsComplete.Val,
 		CreatedAt:       util.GetFullDateStr(task.CreatedAt.Val),
 		UpdatedAt:       util.GetFullDateStr(task.UpdatedAt.Val),
+		ParentId:        reqTask.ParentId,
+		RecurrenceRule:  reqTask.RecurrenceRule,
+		RecurrenceIndex: int32(task.RecurrenceIndex.Val),
+		ReminderOffsets: reqTask.ReminderOffsets,
+		Tags:            tagsToPb(tags),
 	}
 
 	return &pb.Response{
@@ -107,7 +216,43 @@ func (s *Server) CreateTask(ctx context.Context, req *pb.CreateTaskRequest) (*pb
 	}, nil
 }
 
+// taskToPb converts the task along with the progress of its subtasks, the offsets of its reminders and its tags
+func taskToPb(task *model.Task, progress model.SubtaskProgress, reminderOffsets []int32, tags []model.Tag) *pb.Task {
+	var parentId *int32
+	if task.ParentId != nil {
+		parentId = util.Pointer(int32(*task.ParentId))
+	}
+
+	return &pb.Task{
+		Id:                    int32(task.ID),
+		UserId:                int32(task.UserId),
+		CategoryId:            int32(task.CategoryId),
+		Title:                 task.Title,
+		Note:                  task.Note,
+		Url:                   task.Url,
+		SpecifyDatetime:       util.GetFullDateStrFromPtr(&task.SpecifyDatetime),
+		IsSpecifyTime:         task.IsSpecifyTime,
+		Priority:              int32(task.Priority),
+		IsComplete:            task.IsComplete,
+		CreatedAt:             util.GetFullDateStr(task.CreatedAt),
+		UpdatedAt:             util.GetFullDateStr(task.UpdatedAt),
+		ParentId:              parentId,
+		SubtaskCount:          int32(progress.Total),
+		CompletedSubtaskCount: int32(progress.Completed),
+		RecurrenceRule:        task.RecurrenceRule,
+		RecurrenceIndex:       int32(task.RecurrenceIndex),
+		ReminderOffsets:       reminderOffsets,
+		Tags:                  tagsToPb(tags),
+	}
+}
+
 func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -117,27 +262,32 @@ func (s *Server) GetTask(ctx context.Context, req *pb.GetTaskRequest) (*pb.Respo
 	}
 
 	taskId := int(reqGet.Id)
-	getTask := model.GetTaskByID(conn, taskId)
+	getTask := model.GetTaskByIDAndUserID(conn, taskId, claims.UserID)
 	if getTask == nil {
 		return nil, status.Errorf(codes.NotFound, "task ID not found")
 	}
 
+	progress, progressErr := model.GetSubtaskProgress(conn, []int{taskId})
+	if progressErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get subtask progress: %v", progressErr)
+	}
+
+	subtasks := model.ListSubtasks(conn, taskId)
+	taskIds := []int{taskId}
+	for _, subtask := range subtasks {
+		taskIds = append(taskIds, subtask.ID)
+	}
+	reminderOffsets := groupReminderOffsets(model.ListTaskReminders(conn, taskIds))
+	tags := model.ListTagsByTaskIDs(conn, taskIds)
+
+	taskInfo := taskToPb(getTask, progress[taskId], reminderOffsets[taskId], tags[taskId])
+	for _, subtask := range subtasks {
+		taskInfo.Subtasks = append(taskInfo.Subtasks, taskToPb(&subtask, model.SubtaskProgress{}, reminderOffsets[subtask.ID], tags[subtask.ID]))
+	}
+
 	return &pb.Response{
 		Data: &pb.Response_Task{
-			Task: &pb.Task{
-				Id:              int32(getTask.ID),
-				UserId:          int32(getTask.UserId),
-				CategoryId:      int32(getTask.CategoryId),
-				Title:           getTask.Title,
-				Note:            getTask.Note,
-				Url:             getTask.Url,
-				SpecifyDatetime: util.GetFullDateStrFromPtr(&getTask.SpecifyDatetime),
-				IsSpecifyTime:   getTask.IsSpecifyTime,
-				Priority:        int32(getTask.Priority),
-				IsComplete:      getTask.IsComplete,
-				CreatedAt:       util.GetFullDateStr(getTask.CreatedAt),
-				UpdatedAt:       util.GetFullDateStr(getTask.UpdatedAt),
-			},
+			Task: taskInfo,
 		},
 		Status:  http.StatusOK,
 		Message: "ok",
@@ -154,6 +304,10 @@ type ReqListTask struct {
 	IsSpecifyTime *bool   `json:"is_specify_time" validate:"omitempty"`
 	Priority      *int32  `json:"priority" validate:"omitempty,oneof=1 2 3"`
 	IsComplete    *bool   `json:"is_complete" validate:"omitempty"`
+	IsTopLevel    *bool   `json:"is_top_level" validate:"omitempty"`
+	ParentId      *int32  `json:"parent_id" validate:"omitempty,min=1"`
+	TagIds        []int32 `json:"tag_ids" validate:"omitempty,max=20,unique,dive,min=1"`
+	MatchAllTags  *bool   `json:"match_all_tags" validate:"omitempty"`
 }
 
 func (ins ReqListTask) toConditions() *model.TaskConditions {
@@ -183,6 +337,26 @@ func (ins ReqListTask) toConditions() *model.TaskConditions {
 		isComplete := *ins.IsComplete
 		cons.IsComplete = &condition.Bool{EQ: &isComplete}
 	}
+	if ins.IsTopLevel != nil {
+		isTopLevel := *ins.IsTopLevel
+		cons.ParentId = &condition.Int{IsNull: &isTopLevel}
+	}
+	if ins.ParentId != nil {
+		parentId := int(*ins.ParentId)
+		cons.ParentId = &condition.Int{EQ: &parentId}
+	}
+	// The tasks with all of the tags, or any of them by default
+	if len(ins.TagIds) > 0 {
+		tagIds := make([]int, 0, len(ins.TagIds))
+		for _, tagId := range ins.TagIds {
+			tagIds = append(tagIds, int(tagId))
+		}
+		if ins.MatchAllTags != nil && *ins.MatchAllTags {
+			cons.TagIds = &condition.Int{CONTAIN: tagIds}
+		} else {
+			cons.TagIds = &condition.Int{OVERLAP: tagIds}
+		}
+	}
 
 	return cons
 }
@@ -216,22 +390,20 @@ func (s *Server) ListTask(ctx context.Context, req *pb.ListTaskRequest) (*pb.Lis
 		return nil, status.Errorf(codes.Internal, "failed to get task count: %v", err)
 	}
 
+	taskIds := make([]int, 0, len(listTask))
+	for _, task := range listTask {
+		taskIds = append(taskIds, task.ID)
+	}
+	progress, progressErr := model.GetSubtaskProgress(conn, taskIds)
+	if progressErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get subtask progress: %v", progressErr)
+	}
+	reminderOffsets := groupReminderOffsets(model.ListTaskReminders(conn, taskIds))
+	tags := model.ListTagsByTaskIDs(conn, taskIds)
+
 	pbTasks := []*pb.Task{}
 	for _, task := range listTask {
-		pbTasks = append(pbTasks, &pb.Task{
-			Id:              int32(task.ID),
-			UserId:          int32(task.UserId),
-			CategoryId:      int32(task.CategoryId),
-			Title:           task.Title,
-			Note:            task.Note,
-			Url:             task.Url,
-			SpecifyDatetime: util.GetFullDateStrFromPtr(&task.SpecifyDatetime),
-			IsSpecifyTime:   task.IsSpecifyTime,
-			Priority:        int32(task.Priority),
-			IsComplete:      task.IsComplete,
-			CreatedAt:       util.GetFullDateStr(task.CreatedAt),
-			UpdatedAt:       util.GetFullDateStr(task.UpdatedAt),
-		})
+		pbTasks = append(pbTasks, taskToPb(&task, progress[task.ID], reminderOffsets[task.ID], tags[task.ID]))
 	}
 
 	return &pb.ListResponse{
@@ -248,6 +420,100 @@ func (s *Server) ListTask(ctx context.Context, req *pb.ListTaskRequest) (*pb.Lis
 	}, nil
 }
 
+type ReqSearchTasks struct {
+	Query      string  `json:"query" validate:"required,min=1,max=255"`
+	Page       int32   `json:"page" validate:"required,min=1,max=100000"`
+	PageSize   int32   `json:"page_size" validate:"required,min=5,max=1000"`
+	CategoryId *int32  `json:"category_id" validate:"omitempty,min=1"`
+	IsComplete *bool   `json:"is_complete" validate:"omitempty"`
+	TagIds     []int32 `json:"tag_ids" validate:"omitempty,max=20,unique,dive,min=1"`
+}
+
+func (ins ReqSearchTasks) toConditions() *model.TaskConditions {
+	cons := &model.TaskConditions{}
+
+	query := ins.Query
+	cons.SearchVector = &condition.TextSearch{Match: &query}
+	if ins.CategoryId != nil {
+		categoryId := int(*ins.CategoryId)
+		cons.CategoryId = &condition.Int{EQ: &categoryId}
+	}
+	if ins.IsComplete != nil {
+		isComplete := *ins.IsComplete
+		cons.IsComplete = &condition.Bool{EQ: &isComplete}
+	}
+	if len(ins.TagIds) > 0 {
+		tagIds := make([]int, 0, len(ins.TagIds))
+		for _, tagId := range ins.TagIds {
+			tagIds = append(tagIds, int(tagId))
+		}
+		cons.TagIds = &condition.Int{OVERLAP: tagIds}
+	}
+
+	return cons
+}
+
+// SearchTasks searches the title and the note of the tasks of the user, the best match first
+func (s *Server) SearchTasks(ctx context.Context, req *pb.SearchTasksRequest) (*pb.ListResponse, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+	userId := claims.UserID
+
+	reqSearch := &ReqSearchTasks{}
+	if err := bindRequest(req, reqSearch); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	limit := int(reqSearch.PageSize)
+	offset := int((reqSearch.Page - 1) * reqSearch.PageSize)
+	cons := reqSearch.toConditions()
+	cons.UserId = &condition.Int{EQ: &userId}
+
+	conn := db.GetConn()
+	results := model.SearchTask(conn, cons, &limit, &offset)
+	count, err := model.GetTaskCount(conn, cons)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get task count: %v", err)
+	}
+
+	taskIds := make([]int, 0, len(results))
+	for _, result := range results {
+		taskIds = append(taskIds, result.ID)
+	}
+	progress, progressErr := model.GetSubtaskProgress(conn, taskIds)
+	if progressErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to get subtask progress: %v", progressErr)
+	}
+	reminderOffsets := groupReminderOffsets(model.ListTaskReminders(conn, taskIds))
+	tags := model.ListTagsByTaskIDs(conn, taskIds)
+
+	pbResults := []*pb.TaskSearchResult{}
+	for _, result := range results {
+		pbResults = append(pbResults, &pb.TaskSearchResult{
+			Task:           taskToPb(&result.Task, progress[result.ID], reminderOffsets[result.ID], tags[result.ID]),
+			Rank:           result.Rank,
+			TitleHighlight: result.TitleHighlight,
+			NoteSnippet:    result.NoteSnippet,
+		})
+	}
+
+	return &pb.ListResponse{
+		Data: &pb.ListResponse_TaskSearchResults{
+			TaskSearchResults: &pb.TaskSearchResults{
+				Data: pbResults,
+			},
+		},
+		TotalCount: count,
+		Page:       reqSearch.Page,
+		PageSize:   reqSearch.PageSize,
+		Status:     http.StatusOK,
+		Message:    "ok",
+	}, nil
+}
+
 type ReqUpdateTask struct {
 	Id              int32   `json:"id" validate:"required,min=1"`
 	CategoryId      *int32  `json:"category_id" validate:"omitempty,min=1"`
@@ -257,6 +523,11 @@ type ReqUpdateTask struct {
 	SpecifyDatetime *int64  `json:"specify_datetime" validate:"omitempty,min=1"`
 	Priority        *int32  `json:"priority" validate:"omitempty,oneof=1 2 3"`
 	IsComplete      *bool   `json:"is_complete" validate:"omitempty"`
+	RecurrenceRule  *string `json:"recurrence_rule" validate:"omitempty,max=255"`
+	ReminderOffsets []int32 `json:"reminder_offsets" validate:"omitempty,max=5,unique,dive,min=0,max=10080"`
+	ClearReminders  *bool   `json:"clear_reminders" validate:"omitempty"`
+	TagIds          []int32 `json:"tag_ids" validate:"omitempty,max=20,unique,dive,min=1"`
+	ClearTags       *bool   `json:"clear_tags" validate:"omitempty"`
 }
 
 func (ins ReqUpdateTask) toFieldValues() (model.TaskFieldValues, bool) {
@@ -280,15 +551,11 @@ func (ins ReqUpdateTask) toFieldValues() (model.TaskFieldValues, bool) {
 		requiredCheck = true
 		fv.Url = model.GiveColString(*ins.Url)
 	}
-	var t *time.Time
-	isSpecifyTime := false
 	if ins.SpecifyDatetime != nil {
 		requiredCheck = true
-		t = util.Pointer(time.Unix(*ins.SpecifyDatetime/1000, 0))
-		isSpecifyTime = true
+		fv.SpecifyDatetime = model.GiveColNullTime(util.Pointer(time.Unix(*ins.SpecifyDatetime/1000, 0)))
+		fv.IsSpecifyTime = model.GiveColBool(true)
 	}
-	fv.SpecifyDatetime = model.GiveColNullTime(t)
-	fv.IsSpecifyTime = model.GiveColBool(isSpecifyTime)
 	if ins.Priority != nil {
 		requiredCheck = true
 		fv.Priority = model.GiveColInt(int(*ins.Priority))
@@ -297,11 +564,31 @@ func (ins ReqUpdateTask) toFieldValues() (model.TaskFieldValues, bool) {
 		requiredCheck = true
 		fv.IsComplete = model.GiveColBool(*ins.IsComplete)
 	}
+	// An empty rule stops the task from recurring
+	if ins.RecurrenceRule != nil {
+		requiredCheck = true
+		if *ins.RecurrenceRule == "" {
+			fv.RecurrenceRule = model.GiveColNullString(nil)
+			fv.RecurrenceIndex = model.GiveColInt(0)
+		} else {
+			fv.RecurrenceRule = model.GiveColNullString(ins.RecurrenceRule)
+		}
+	}
+	// The reminders and the tags are stored apart from the task
+	if len(ins.ReminderOffsets) > 0 || ins.ClearReminders != nil || len(ins.TagIds) > 0 || ins.ClearTags != nil {
+		requiredCheck = true
+	}
 
 	return fv, requiredCheck
 }
 
 func (s *Server) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -311,12 +598,79 @@ func (s *Server) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb
 	}
 
 	taskId := int(reqUpdate.Id)
-	getTask := model.GetTaskByID(conn, taskId)
+	getTask := model.GetTaskByIDAndUserID(conn, taskId, claims.UserID)
 	if getTask == nil {
 		return nil, status.Errorf(codes.NotFound, "task ID not found")
 	}
 
+	// Check if the new category belongs to the user
+	if reqUpdate.CategoryId != nil {
+		if getCategory := model.GetCategoryByIDAndUserID(conn, int(*reqUpdate.CategoryId), claims.UserID); getCategory == nil {
+			return nil, status.Errorf(codes.NotFound, "category ID not found")
+		}
+	}
+
+	// Only a top-level task with a datetime to schedule the occurrences from can recur
+	if reqUpdate.RecurrenceRule != nil && *reqUpdate.RecurrenceRule != "" {
+		rule, ruleErr := rrule.Parse(*reqUpdate.RecurrenceRule)
+		if ruleErr != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", ruleErr)
+		}
+		if reqUpdate.SpecifyDatetime == nil && !getTask.IsSpecifyTime {
+			return nil, status.Errorf(codes.InvalidArgument, "a recurring task requires specify_datetime")
+		}
+		if getTask.ParentId != nil {
+			return nil, status.Errorf(codes.FailedPrecondition, "a subtask cannot recur")
+		}
+		if len(model.ListSubtasks(conn, taskId)) > 0 {
+			return nil, status.Errorf(codes.FailedPrecondition, "a recurring task cannot have subtasks")
+		}
+		reqUpdate.RecurrenceRule = util.Pointer(rule.String())
+	}
+
+	// The reminders are replaced when given or cleared, and rescheduled along with the datetime
+	clearReminders := reqUpdate.ClearReminders != nil && *reqUpdate.ClearReminders
+	if clearReminders && len(reqUpdate.ReminderOffsets) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "clear_reminders cannot be used with reminder_offsets")
+	}
+	if len(reqUpdate.ReminderOffsets) > 0 && reqUpdate.SpecifyDatetime == nil && !getTask.IsSpecifyTime {
+		return nil, status.Errorf(codes.InvalidArgument, "reminders require specify_datetime")
+	}
+	replaceReminders := clearReminders || len(reqUpdate.ReminderOffsets) > 0 || reqUpdate.SpecifyDatetime != nil
+
+	// The tags are replaced when given or cleared
+	clearTags := reqUpdate.ClearTags != nil && *reqUpdate.ClearTags
+	if clearTags && len(reqUpdate.TagIds) > 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "clear_tags cannot be used with tag_ids")
+	}
+	tagIds, _, tagErr := getUserTags(conn, claims.UserID, reqUpdate.TagIds)
+	if tagErr != nil {
+		return nil, tagErr
+	}
+	replaceTags := clearTags || len(tagIds) > 0
+
+	// Check if the task title is already exists in the target category, reopening a task takes its title back
+	reopen := reqUpdate.IsComplete != nil && !*reqUpdate.IsComplete && getTask.IsComplete
+	if reqUpdate.Title != nil || reqUpdate.CategoryId != nil || reopen {
+		categoryId := getTask.CategoryId
+		if reqUpdate.CategoryId != nil {
+			categoryId = int(*reqUpdate.CategoryId)
+		}
+		title := getTask.Title
+		if reqUpdate.Title != nil {
+			title = *reqUpdate.Title
+		}
+
+		if sameTitleTask := model.GetOpenTaskByTitle(conn, claims.UserID, categoryId, title); sameTitleTask != nil && sameTitleTask.ID != taskId {
+			return nil, status.Errorf(codes.AlreadyExists, "the task already exists")
+		}
+	}
+
 	insFields, insCheck := reqUpdate.toFieldValues()
+	if reqUpdate.RecurrenceRule != nil && *reqUpdate.RecurrenceRule != "" && getTask.RecurrenceIndex == 0 {
+		insFields.RecurrenceIndex = model.GiveColInt(1)
+	}
+	complete := reqUpdate.IsComplete != nil && *reqUpdate.IsComplete && !getTask.IsComplete
 	if insCheck {
 		tx, txErr := conn.Begin()
 		if txErr != nil {
@@ -325,36 +679,101 @@ func (s *Server) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb
 		defer tx.Rollback()
 
 		if err := model.UpdateTask(tx, taskId, &insFields); err != nil {
+			if db.IsUniqueViolation(err) {
+				return nil, status.Errorf(codes.AlreadyExists, "the task already exists")
+			}
 			return nil, status.Errorf(codes.Internal, "failed to update task: %v", err)
 		}
 
-		getTask := model.GetTaskByID(tx, taskId)
+		// Completing a task completes its subtasks, completing or reopening a subtask rolls up to its parent
+		if reqUpdate.IsComplete != nil {
+			var rollUpErr error
+			if getTask.ParentId != nil {
+				rollUpErr = model.RollUpTaskCompletion(tx, *getTask.ParentId)
+			} else if *reqUpdate.IsComplete {
+				rollUpErr = model.CompleteSubtasks(tx, taskId)
+			}
+			// Reopening the parent takes its title back, which an open task may have taken in the meantime
+			if db.IsUniqueViolation(rollUpErr) {
+				return nil, status.Errorf(codes.AlreadyExists, "the parent task conflicts with an open task of the same title")
+			}
+			if rollUpErr != nil {
+				return nil, status.Errorf(codes.Internal, "failed to roll up task completion: %v", rollUpErr)
+			}
+		}
+
+		getTask := model.GetTaskByIDAndUserID(tx, taskId, claims.UserID)
 		if getTask == nil {
 			return nil, status.Errorf(codes.NotFound, "task ID not found")
 		}
 
+		var staleReminders []model.TaskReminder
+		var reminderPayloads []*queue.PayloadSendTaskReminder
+		if replaceReminders {
+			staleReminders = model.ListTaskReminders(tx, []int{taskId})
+			offsets := reqUpdate.ReminderOffsets
+			if len(offsets) == 0 && !clearReminders {
+				offsets = groupReminderOffsets(staleReminders)[taskId]
+			}
+
+			if err := model.DeleteTaskReminders(tx, taskId); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to delete task reminders: %v", err)
+			}
+			payloads, err := scheduleTaskReminders(tx, taskId, getTask.SpecifyDatetime, offsets)
+			if err != nil {
+				return nil, err
+			}
+			reminderPayloads = append(reminderPayloads, payloads...)
+		}
+		reminderOffsets := groupReminderOffsets(model.ListTaskReminders(tx, []int{taskId}))[taskId]
+
+		if replaceTags {
+			if err := model.ReplaceTaskTags(tx, taskId, tagIds); err != nil {
+				return nil, status.Errorf(codes.Internal, "failed to set task tags: %v", err)
+			}
+		}
+		tags := model.ListTagsByTaskIDs(tx, []int{taskId})[taskId]
+
+		// Completing an occurrence of a recurring task schedules the next one along with its reminders and tags
+		if complete && getTask.RecurrenceRule != nil {
+			next, err := createNextOccurrence(tx, getTask)
+			if err != nil {
+				return nil, err
+			}
+			if next != nil {
+				payloads, err := scheduleTaskReminders(tx, next.ID.Val, next.SpecifyDatetime.Val, reminderOffsets)
+				if err != nil {
+					return nil, err
+				}
+				reminderPayloads = append(reminderPayloads, payloads...)
+
+				nextTagIds := make([]int, 0, len(tags))
+				for _, tag := range tags {
+					nextTagIds = append(nextTagIds, tag.ID)
+				}
+				if err := model.ReplaceTaskTags(tx, next.ID.Val, nextTagIds); err != nil {
+					return nil, status.Errorf(codes.Internal, "failed to set task tags: %v", err)
+				}
+			}
+		}
+
+		progress, progressErr := model.GetSubtaskProgress(tx, []int{taskId})
+		if progressErr != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get subtask progress: %v", progressErr)
+		}
+
 		comErr := tx.Commit()
 		if comErr != nil {
 			log.Error.Printf("failed to create task from db tx: %v", comErr)
 			return nil, status.Errorf(codes.Internal, "failed to create task from db tx: %v", comErr)
 		}
 
+		s.cancelTaskReminders(ctx, staleReminders)
+		s.enqueueTaskReminders(ctx, reminderPayloads)
+
 		return &pb.Response{
 			Data: &pb.Response_Task{
-				Task: &pb.Task{
-					Id:              int32(getTask.ID),
-					UserId:          int32(getTask.UserId),
-					CategoryId:      int32(getTask.CategoryId),
-					Title:           getTask.Title,
-					Note:            getTask.Note,
-					Url:             getTask.Url,
-					SpecifyDatetime: util.GetFullDateStrFromPtr(&getTask.SpecifyDatetime),
-					IsSpecifyTime:   getTask.IsSpecifyTime,
-					Priority:        int32(getTask.Priority),
-					IsComplete:      getTask.IsComplete,
-					CreatedAt:       util.GetFullDateStr(getTask.CreatedAt),
-					UpdatedAt:       util.GetFullDateStr(getTask.UpdatedAt),
-				},
+				Task: taskToPb(getTask, progress[taskId], reminderOffsets, tags),
 			},
 			Status:  http.StatusOK,
 			Message: "ok",
@@ -368,7 +787,74 @@ func (s *Server) UpdateTask(ctx context.Context, req *pb.UpdateTaskRequest) (*pb
 	}, nil
 }
 
+// createNextOccurrence creates the occurrence that follows the task by its rule in the time zone of the user,
+// nil is returned once the rule ends or when a later occurrence exists already, as it does when an occurrence
+// is reopened and completed again. The subtasks are not carried over.
+func createNextOccurrence(tx *sql.Tx, task *model.Task) (*model.TaskFieldValues, error) {
+	rule, ruleErr := rrule.Parse(*task.RecurrenceRule)
+	if ruleErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to parse recurrence rule: %v", ruleErr)
+	}
+	if !task.IsSpecifyTime {
+		return nil, nil
+	}
+	if later := model.GetLaterOccurrence(tx, task.UserId, task.CategoryId, task.Title, *task.RecurrenceRule, task.RecurrenceIndex); later != nil {
+		return nil, nil
+	}
+
+	loc := util.GetServiceTimeLoc()
+	if getUser := model.GetUserByID(tx, task.UserId); getUser != nil {
+		userLoc, locErr := util.LoadTimeZone(getUser.TimeZone)
+		if locErr != nil {
+			log.Error.Printf("failed to load time zone of user %d: %v", task.UserId, locErr)
+		} else {
+			loc = userLoc
+		}
+	}
+
+	next, ok := rule.Next(task.SpecifyDatetime, task.RecurrenceIndex, loc)
+	if !ok {
+		return nil, nil
+	}
+
+	if sameTitleTask := model.GetOpenTaskByTitle(tx, task.UserId, task.CategoryId, task.Title); sameTitleTask != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "the next occurrence conflicts with an open task of the same title")
+	}
+
+	now := time.Now().UTC()
+	insFields := &model.TaskFieldValues{
+		UserId:          model.GiveColInt(task.UserId),
+		CategoryId:      model.GiveColInt(task.CategoryId),
+		Title:           model.GiveColString(task.Title),
+		Note:            model.GiveColString(task.Note),
+		Url:             model.GiveColString(task.Url),
+		SpecifyDatetime: model.GiveColNullTime(util.Pointer(next.UTC())),
+		IsSpecifyTime:   model.GiveColBool(true),
+		Priority:        model.GiveColInt(task.Priority),
+		IsComplete:      model.GiveColBool(false),
+		RecurrenceRule:  model.GiveColNullString(task.RecurrenceRule),
+		RecurrenceIndex: model.GiveColInt(task.RecurrenceIndex + 1),
+		CreatedAt:       model.GiveColTime(now),
+		UpdatedAt:       model.GiveColTime(now),
+	}
+	nextTask, err := model.CreateTask(tx, insFields)
+	if err != nil {
+		if db.IsUniqueViolation(err) {
+			return nil, status.Errorf(codes.AlreadyExists, "the next occurrence conflicts with an open task of the same title")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create next occurrence: %v", err)
+	}
+
+	return nextTask, nil
+}
+
 func (s *Server) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	conn := db.GetConn()
 
 	// Validate request
@@ -378,11 +864,18 @@ func (s *Server) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb
 	}
 
 	taskId := int(reqDelete.Id)
-	getTask := model.GetTaskByID(conn, taskId)
+	getTask := model.GetTaskByIDAndUserID(conn, taskId, claims.UserID)
 	if getTask == nil {
 		return nil, status.Errorf(codes.NotFound, "task ID not found")
 	}
 
+	// The reminders of the task and of its subtasks are deleted along with them
+	taskIds := []int{taskId}
+	for _, subtask := range model.ListSubtasks(conn, taskId) {
+		taskIds = append(taskIds, subtask.ID)
+	}
+	staleReminders := model.ListTaskReminders(conn, taskIds)
+
 	tx, txErr := conn.Begin()
 	if txErr != nil {
 		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
@@ -393,12 +886,21 @@ func (s *Server) DeleteTask(ctx context.Context, req *pb.DeleteTaskRequest) (*pb
 		return nil, status.Errorf(codes.Internal, "failed to delete task: %v", err)
 	}
 
+	// The subtasks are deleted along with the task, a deleted subtask no longer counts for its parent
+	if getTask.ParentId != nil {
+		if err := model.RollUpTaskCompletion(tx, *getTask.ParentId); err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to roll up task completion: %v", err)
+		}
+	}
+
 	comErr := tx.Commit()
 	if comErr != nil {
 		log.Error.Printf("failed to create task from db tx: %v", comErr)
 		return nil, status.Errorf(codes.Internal, "failed to create task from db tx: %v", comErr)
 	}
 
+	s.cancelTaskReminders(ctx, staleReminders)
+
 	return &pb.Response{
 		Data:    nil,
 		Status:  http.StatusOK,
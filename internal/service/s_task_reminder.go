@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/service/queue"
+	"slices"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// groupReminderOffsets returns the offsets of the reminders by task ID, in the order of the reminders
+func groupReminderOffsets(reminders []model.TaskReminder) map[int][]int32 {
+	offsets := make(map[int][]int32)
+	for _, reminder := range reminders {
+		offsets[reminder.TaskId] = append(offsets[reminder.TaskId], int32(reminder.OffsetMinutes))
+	}
+
+	return offsets
+}
+
+// scheduleTaskReminders creates the reminders of the task at the offsets before its datetime and returns the ones
+// to enqueue once the transaction is committed, a reminder already due when it is scheduled is kept but not sent.
+func scheduleTaskReminders(tx *sql.Tx, taskId int, specifyDatetime time.Time, offsets []int32) ([]*queue.PayloadSendTaskReminder, error) {
+	now := time.Now().UTC()
+	offsets = slices.Clone(offsets)
+	slices.Sort(offsets)
+
+	payloads := make([]*queue.PayloadSendTaskReminder, 0, len(offsets))
+
+	for _, offset := range offsets {
+		remindAt := specifyDatetime.Add(-time.Duration(offset) * time.Minute).UTC().Truncate(time.Second)
+		reminder, reminderErr := model.CreateTaskReminder(tx, &model.TaskReminderFieldValues{
+			TaskId:        model.GiveColInt(taskId),
+			OffsetMinutes: model.GiveColInt(int(offset)),
+			RemindAt:      model.GiveColTime(remindAt),
+			CreatedAt:     model.GiveColTime(now),
+			UpdatedAt:     model.GiveColTime(now),
+		})
+		if reminderErr != nil {
+			return nil, status.Errorf(codes.Internal, "failed to create task reminder: %v", reminderErr)
+		}
+
+		if !remindAt.After(now) {
+			continue
+		}
+
+		payloads = append(payloads, &queue.PayloadSendTaskReminder{
+			ReminderId: reminder.ID.Val,
+			RemindAt:   remindAt.Unix(),
+		})
+	}
+
+	return payloads, nil
+}
+
+// enqueueTaskReminders enqueues the reminders returned by scheduleTaskReminders after their rows are committed,
+// so that a rolled back transaction leaves nothing in the queue.
+func (s *Server) enqueueTaskReminders(ctx context.Context, payloads []*queue.PayloadSendTaskReminder) {
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+	}
+
+	for _, taskPayload := range payloads {
+		if err := s.taskDistributor.DistributeTaskSendTaskReminder(ctx, taskPayload, opts...); err != nil {
+			log.Error.Printf("failed to distribute task to send task reminder %d: %v", taskPayload.ReminderId, err)
+		}
+	}
+}
+
+// cancelTaskReminders removes the reminders that are not sent yet from the queue once their rows are gone,
+// a reminder left in the queue is skipped by the processor anyway.
+func (s *Server) cancelTaskReminders(ctx context.Context, reminders []model.TaskReminder) {
+	for _, reminder := range reminders {
+		if reminder.SentAt != nil {
+			continue
+		}
+
+		taskPayload := &queue.PayloadSendTaskReminder{
+			ReminderId: reminder.ID,
+			RemindAt:   reminder.RemindAt.Unix(),
+		}
+		if err := s.taskDistributor.CancelTaskSendTaskReminder(ctx, taskPayload); err != nil {
+			log.Error.Printf("failed to cancel task reminder %d: %v", reminder.ID, err)
+		}
+	}
+}
@@ -3,10 +3,10 @@ package service_test
 import (
 	"bytes"
 	"context"
-	"encoding/json"
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/config"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
 	"go-todolist-grpc/internal/pkg/log"
 	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service"
@@ -16,21 +16,32 @@ import (
 	"path/filepath"
 	"strconv"
 	"testing"
+	"time"
 
 	"github.com/hibiken/asynq"
 	"github.com/stretchr/testify/assert"
 	"google.golang.org/grpc/codes"
-	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-type mockTaskDistributorByTask struct{}
+// mockTaskDistributorByTask records the reminders scheduled and cancelled
+type mockTaskDistributorByTask struct {
+	mockTaskDistributor
+	reminders []*queue.PayloadSendTaskReminder
+	cancelled []*queue.PayloadSendTaskReminder
+}
+
+func (m *mockTaskDistributorByTask) DistributeTaskSendTaskReminder(ctx context.Context, payload *queue.PayloadSendTaskReminder, opts ...asynq.Option) error {
+	m.reminders = append(m.reminders, payload)
+	return nil
+}
 
-func (m *mockTaskDistributorByTask) DistributeTaskSendVerifyEmail(ctx context.Context, payload *queue.PayloadSendVerifyEmail, opts ...asynq.Option) error {
+func (m *mockTaskDistributorByTask) CancelTaskSendTaskReminder(ctx context.Context, payload *queue.PayloadSendTaskReminder) error {
+	m.cancelled = append(m.cancelled, payload)
 	return nil
 }
 
-func setUpTask() (*service.Server, error) {
+func setUpTask() (*service.Server, *mockTaskDistributorByTask, error) {
 	var mockConfigContent bytes.Buffer
 	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
 	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
@@ -46,6 +57,7 @@ func setUpTask() (*service.Server, error) {
 	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
 	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
 	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
 	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
 	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
 	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
@@ -57,18 +69,23 @@ func setUpTask() (*service.Server, error) {
 	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
 	defer os.Remove(mockConfigFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// Init config
 	loadErr := config.Load()
 	if loadErr != nil {
-		return nil, loadErr
+		return nil, nil, loadErr
 	}
 
 	// Init log
 	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
 
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, nil, err
+	}
+
 	// Init sql
 	opt := &db.Option{
 		Host:     config.SourceHost,
@@ -81,35 +98,25 @@ func setUpTask() (*service.Server, error) {
 
 	err = db.Init(opt)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	mockDistributor := &mockTaskDistributorByTask{}
-	s := service.NewServer(mockDistributor)
+	s := service.NewServer(mockDistributor, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
 
-	return s, nil
-}
-
-func createAuthenticatedContext(id int) context.Context {
-	claims := &util.CustomClaims{
-		UserID: id,
-	}
-	claimsJSON, _ := json.Marshal(claims)
-	md := metadata.New(map[string]string{
-		"x-auth-claims": string(claimsJSON),
-	})
-	return metadata.NewIncomingContext(context.Background(), md)
+	return s, mockDistributor, nil
 }
 
 type setUpTaskInfo struct {
-	s          *service.Server
-	ctx        context.Context
-	userId     int32
-	categoryId int32
+	s           *service.Server
+	distributor *mockTaskDistributorByTask
+	ctx         context.Context
+	userId      int32
+	categoryId  int32
 }
 
 func createUserAndCategory(t *testing.T) *setUpTaskInfo {
-	s, err := setUpTask()
+	s, distributor, err := setUpTask()
 	assert.NoError(t, err)
 
 	// Register a user
@@ -123,10 +130,10 @@ func createUserAndCategory(t *testing.T) *setUpTaskInfo {
 
 	isEmailVerified := true
 	uReq := &pb.UpdateUserRequest{
-		UserId:          rRes.GetUser().Id,
+		UserId:          &rRes.GetUser().Id,
 		IsEmailVerified: &isEmailVerified,
 	}
-	_, uErr := s.UpdateUser(context.Background(), uReq)
+	_, uErr := s.UpdateUser(createAdminContext(0), uReq)
 	assert.Nil(t, uErr)
 
 	// Create authenticated context
@@ -136,14 +143,15 @@ func createUserAndCategory(t *testing.T) *setUpTaskInfo {
 	cReq := &pb.CreateCategoryRequest{
 		Name: util.RandomString(6),
 	}
-	cRes, err := s.CreateCategory(context.Background(), cReq)
+	cRes, err := s.CreateCategory(ctx, cReq)
 	assert.Nil(t, err)
 
 	return &setUpTaskInfo{
-		s:          s,
-		ctx:        ctx,
-		userId:     rRes.GetUser().Id,
-		categoryId: cRes.GetCategory().Id,
+		s:           s,
+		distributor: distributor,
+		ctx:         ctx,
+		userId:      rRes.GetUser().Id,
+		categoryId:  cRes.GetCategory().Id,
 	}
 }
 
@@ -193,6 +201,57 @@ func TestCreateTask(t *testing.T) {
 		assert.Contains(t, st.Message(), "failed to validate")
 	})
 
+	t.Run("Failure_ExistingTitle", func(t *testing.T) {
+		cTRes := createTask(t, setUp)
+		req := &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      cTRes.GetTask().Title,
+			Priority:   util.RandomInt(int32(1), int32(3)),
+		}
+
+		res, err := setUp.s.CreateTask(setUp.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the task already exists")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+		assert.Equal(t, "the task already exists", st.Message())
+	})
+
+	t.Run("Sussess_SameTitleOtherUser", func(t *testing.T) {
+		cTRes := createTask(t, setUp)
+		other := createUserAndCategory(t)
+		req := &pb.CreateTaskRequest{
+			CategoryId: other.categoryId,
+			Title:      cTRes.GetTask().Title,
+			Priority:   util.RandomInt(int32(1), int32(3)),
+		}
+
+		res, err := other.s.CreateTask(other.ctx, req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, cTRes.GetTask().Title, res.GetTask().Title)
+	})
+
+	t.Run("Failure_OtherUserCategory", func(t *testing.T) {
+		other := createUserAndCategory(t)
+		req := &pb.CreateTaskRequest{
+			CategoryId: other.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   util.RandomInt(int32(1), int32(3)),
+		}
+
+		res, err := setUp.s.CreateTask(setUp.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = category ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "category ID not found", st.Message())
+	})
+
 	t.Run("Failure_Unauthenticated", func(t *testing.T) {
 		unauthCtx := context.Background()
 		req := &pb.CreateTaskRequest{
@@ -243,6 +302,36 @@ func TestGetTask(t *testing.T) {
 		assert.Equal(t, codes.NotFound, st.Code())
 		assert.Equal(t, "task ID not found", st.Message())
 	})
+	t.Run("Failure_OtherUserTask", func(t *testing.T) {
+		other := createUserAndCategory(t)
+		req := &pb.GetTaskRequest{
+			Id: cTRes.GetTask().Id,
+		}
+
+		res, err := other.s.GetTask(other.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = task ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "task ID not found", st.Message())
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		req := &pb.GetTaskRequest{
+			Id: cTRes.GetTask().Id,
+		}
+
+		res, err := setUp.s.GetTask(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Contains(t, st.Message(), "authentication failed")
+	})
 }
 
 func TestListTask(t *testing.T) {
@@ -333,6 +422,24 @@ func TestUpdateTask(t *testing.T) {
 		assert.Equal(t, newTitle, res.GetTask().Title)
 	})
 
+	t.Run("Failure_ExistingTitle", func(t *testing.T) {
+		otherTask := createTask(t, setUp)
+		existingTitle := otherTask.GetTask().Title
+		req := &pb.UpdateTaskRequest{
+			Id:    cTRes.GetTask().Id,
+			Title: &existingTitle,
+		}
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the task already exists")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.AlreadyExists, st.Code())
+		assert.Equal(t, "the task already exists", st.Message())
+	})
+
 	t.Run("Failure_Non-ExistentID", func(t *testing.T) {
 		req := &pb.UpdateTaskRequest{
 			Id:    999999,
@@ -348,12 +455,55 @@ func TestUpdateTask(t *testing.T) {
 		assert.Equal(t, codes.NotFound, st.Code())
 		assert.Equal(t, "task ID not found", st.Message())
 	})
+	t.Run("Failure_OtherUserTask", func(t *testing.T) {
+		other := createUserAndCategory(t)
+		otherTitle := util.RandomString(10)
+		req := &pb.UpdateTaskRequest{
+			Id:    cTRes.GetTask().Id,
+			Title: &otherTitle,
+		}
+
+		res, err := other.s.UpdateTask(other.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = task ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "task ID not found", st.Message())
+
+		// The owner still sees the unchanged title
+		gRes, gErr := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: cTRes.GetTask().Id})
+		assert.Nil(t, gErr)
+		assert.Equal(t, newTitle, gRes.GetTask().Title)
+	})
 }
 
 func TestDeleteTask(t *testing.T) {
 	setUp := createUserAndCategory(t)
 	cTRes := createTask(t, setUp)
 
+	t.Run("Failure_OtherUserTask", func(t *testing.T) {
+		other := createUserAndCategory(t)
+		req := &pb.DeleteTaskRequest{
+			Id: cTRes.GetTask().Id,
+		}
+
+		res, err := other.s.DeleteTask(other.ctx, req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = task ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+		assert.Equal(t, "task ID not found", st.Message())
+
+		// The task must survive the foreign delete attempt
+		gRes, gErr := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: cTRes.GetTask().Id})
+		assert.Nil(t, gErr)
+		assert.Equal(t, cTRes.GetTask().Id, gRes.GetTask().Id)
+	})
+
 	t.Run("Sussess", func(t *testing.T) {
 		req := &pb.DeleteTaskRequest{
 			Id: cTRes.GetTask().Id,
@@ -381,3 +531,763 @@ func TestDeleteTask(t *testing.T) {
 		assert.Equal(t, "task ID not found", st.Message())
 	})
 }
+
+func createSubtask(t *testing.T, setUp *setUpTaskInfo, parentId int32) *pb.Task {
+	req := &pb.CreateTaskRequest{
+		CategoryId: setUp.categoryId,
+		Title:      util.RandomString(10),
+		Priority:   util.RandomInt(int32(1), int32(3)),
+		ParentId:   &parentId,
+	}
+	res, err := setUp.s.CreateTask(setUp.ctx, req)
+	assert.Nil(t, err)
+
+	return res.GetTask()
+}
+
+func TestSubtask(t *testing.T) {
+	setUp := createUserAndCategory(t)
+
+	t.Run("Sussess_GetTask", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		first := createSubtask(t, setUp, parent.Id)
+		second := createSubtask(t, setUp, parent.Id)
+		assert.Equal(t, parent.Id, first.GetParentId())
+
+		res, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), res.GetTask().SubtaskCount)
+		assert.Equal(t, int32(0), res.GetTask().CompletedSubtaskCount)
+		assert.Len(t, res.GetTask().Subtasks, 2)
+		assert.Equal(t, first.Id, res.GetTask().Subtasks[0].Id)
+		assert.Equal(t, second.Id, res.GetTask().Subtasks[1].Id)
+	})
+
+	t.Run("Sussess_ListTopLevel", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		subtask := createSubtask(t, setUp, parent.Id)
+		isTopLevel := true
+
+		res, err := setUp.s.ListTask(setUp.ctx, &pb.ListTaskRequest{
+			Page:       1,
+			PageSize:   1000,
+			CategoryId: &setUp.categoryId,
+			IsTopLevel: &isTopLevel,
+		})
+		assert.Nil(t, err)
+		ids := []int32{}
+		for _, task := range res.GetTasks().Data {
+			assert.Nil(t, task.ParentId)
+			ids = append(ids, task.Id)
+			if task.Id == parent.Id {
+				assert.Equal(t, int32(1), task.SubtaskCount)
+			}
+		}
+		assert.Contains(t, ids, parent.Id)
+		assert.NotContains(t, ids, subtask.Id)
+
+		res, err = setUp.s.ListTask(setUp.ctx, &pb.ListTaskRequest{
+			Page:     1,
+			PageSize: 5,
+			ParentId: &parent.Id,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), res.TotalCount)
+		assert.Equal(t, subtask.Id, res.GetTasks().Data[0].Id)
+	})
+
+	t.Run("Sussess_RollUp", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		first := createSubtask(t, setUp, parent.Id)
+		second := createSubtask(t, setUp, parent.Id)
+		isComplete := true
+
+		// The parent stays open until every subtask is complete
+		_, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: first.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		gRes, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), gRes.GetTask().CompletedSubtaskCount)
+		assert.False(t, gRes.GetTask().IsComplete)
+
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: second.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		gRes, err = setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(2), gRes.GetTask().CompletedSubtaskCount)
+		assert.True(t, gRes.GetTask().IsComplete)
+
+		// A new open subtask reopens the parent
+		third := createSubtask(t, setUp, parent.Id)
+		gRes, err = setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+		assert.False(t, gRes.GetTask().IsComplete)
+
+		// Deleting it completes the parent again
+		_, err = setUp.s.DeleteTask(setUp.ctx, &pb.DeleteTaskRequest{Id: third.Id})
+		assert.Nil(t, err)
+		gRes, err = setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+		assert.True(t, gRes.GetTask().IsComplete)
+	})
+
+	t.Run("Sussess_CompleteParent", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		createSubtask(t, setUp, parent.Id)
+		createSubtask(t, setUp, parent.Id)
+		isComplete := true
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: parent.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.True(t, res.GetTask().IsComplete)
+		assert.Equal(t, int32(2), res.GetTask().SubtaskCount)
+		assert.Equal(t, int32(2), res.GetTask().CompletedSubtaskCount)
+	})
+
+	t.Run("Sussess_DeleteParent", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		subtask := createSubtask(t, setUp, parent.Id)
+
+		_, err := setUp.s.DeleteTask(setUp.ctx, &pb.DeleteTaskRequest{Id: parent.Id})
+		assert.Nil(t, err)
+
+		res, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: subtask.Id})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = task ID not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_NestedSubtask", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		subtask := createSubtask(t, setUp, parent.Id)
+
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   1,
+			ParentId:   &subtask.Id,
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = a subtask cannot have subtasks")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_OtherUserParent", func(t *testing.T) {
+		otherSetUp := createUserAndCategory(t)
+		parent := createTask(t, otherSetUp).GetTask()
+
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   1,
+			ParentId:   &parent.Id,
+		})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = parent task ID not found")
+		assert.Nil(t, res)
+	})
+}
+
+func createRecurringTask(t *testing.T, setUp *setUpTaskInfo, rule string, specifyDatetime time.Time) *pb.Task {
+	datetime := specifyDatetime.UnixMilli()
+	req := &pb.CreateTaskRequest{
+		CategoryId:      setUp.categoryId,
+		Title:           util.RandomString(10),
+		Priority:        util.RandomInt(int32(1), int32(3)),
+		SpecifyDatetime: &datetime,
+		RecurrenceRule:  &rule,
+	}
+	res, err := setUp.s.CreateTask(setUp.ctx, req)
+	assert.Nil(t, err)
+
+	return res.GetTask()
+}
+
+// listOpenTasksByTitle returns the open tasks of the category with the title
+func listOpenTasksByTitle(t *testing.T, setUp *setUpTaskInfo, title string) []*pb.Task {
+	isComplete := false
+	res, err := setUp.s.ListTask(setUp.ctx, &pb.ListTaskRequest{
+		Page:       1,
+		PageSize:   5,
+		CategoryId: &setUp.categoryId,
+		Title:      &title,
+		IsComplete: &isComplete,
+	})
+	assert.Nil(t, err)
+
+	return res.GetTasks().Data
+}
+
+func TestRecurringTask(t *testing.T) {
+	setUp := createUserAndCategory(t)
+	isComplete := true
+
+	// Daylight saving time starts on 2024-03-10 in New York
+	timeZone := "America/New_York"
+	_, err := setUp.s.UpdateUser(setUp.ctx, &pb.UpdateUserRequest{TimeZone: &timeZone})
+	assert.Nil(t, err)
+	newYork, _ := time.LoadLocation(timeZone)
+
+	t.Run("Sussess_Create", func(t *testing.T) {
+		task := createRecurringTask(t, setUp, "rrule:freq=weekly;byday=mo,we", time.Date(2024, 3, 4, 9, 0, 0, 0, newYork))
+		assert.Equal(t, "FREQ=WEEKLY;BYDAY=MO,WE", task.GetRecurrenceRule())
+		assert.Equal(t, int32(1), task.RecurrenceIndex)
+	})
+
+	t.Run("Sussess_CompleteCreatesNext", func(t *testing.T) {
+		task := createRecurringTask(t, setUp, "FREQ=DAILY", time.Date(2024, 3, 9, 9, 0, 0, 0, newYork))
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.True(t, res.GetTask().IsComplete)
+
+		// The next occurrence keeps 09:00 in the time zone of the user across the change to daylight saving time
+		next := listOpenTasksByTitle(t, setUp, task.Title)
+		assert.Len(t, next, 1)
+		assert.NotEqual(t, task.Id, next[0].Id)
+		assert.Equal(t, util.GetFullDateStr(time.Date(2024, 3, 10, 9, 0, 0, 0, newYork)), next[0].GetSpecifyDatetime())
+		assert.Equal(t, int32(2), next[0].RecurrenceIndex)
+		assert.Equal(t, task.GetRecurrenceRule(), next[0].GetRecurrenceRule())
+		assert.Equal(t, task.Priority, next[0].Priority)
+
+		// Updating the completed occurrence again does not create another one
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.Len(t, listOpenTasksByTitle(t, setUp, task.Title), 1)
+	})
+
+	t.Run("Sussess_ReopenAndCompleteAgain", func(t *testing.T) {
+		task := createRecurringTask(t, setUp, "FREQ=DAILY", time.Date(2024, 3, 9, 9, 0, 0, 0, newYork))
+
+		_, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		next := listOpenTasksByTitle(t, setUp, task.Title)
+		assert.Len(t, next, 1)
+
+		// Complete the second occurrence and delete the third so that the first one can be reopened
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: next[0].Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		third := listOpenTasksByTitle(t, setUp, task.Title)
+		assert.Len(t, third, 1)
+		_, err = setUp.s.DeleteTask(setUp.ctx, &pb.DeleteTaskRequest{Id: third[0].Id})
+		assert.Nil(t, err)
+
+		reopen := false
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &reopen})
+		assert.Nil(t, err)
+
+		// Completing it again does not create the second occurrence once more
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.True(t, res.GetTask().IsComplete)
+		assert.Empty(t, listOpenTasksByTitle(t, setUp, task.Title))
+	})
+
+	t.Run("Sussess_CountEnds", func(t *testing.T) {
+		task := createRecurringTask(t, setUp, "FREQ=MONTHLY;COUNT=2", time.Date(2024, 1, 31, 9, 0, 0, 0, newYork))
+
+		_, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		next := listOpenTasksByTitle(t, setUp, task.Title)
+		assert.Len(t, next, 1)
+		assert.Equal(t, util.GetFullDateStr(time.Date(2024, 3, 31, 9, 0, 0, 0, newYork)), next[0].GetSpecifyDatetime())
+
+		// The second occurrence is the last one
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: next[0].Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.Empty(t, listOpenTasksByTitle(t, setUp, task.Title))
+	})
+
+	t.Run("Sussess_StopRecurring", func(t *testing.T) {
+		task := createRecurringTask(t, setUp, "FREQ=DAILY", time.Date(2024, 3, 9, 9, 0, 0, 0, newYork))
+		empty := ""
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, RecurrenceRule: &empty})
+		assert.Nil(t, err)
+		assert.Nil(t, res.GetTask().RecurrenceRule)
+		assert.Equal(t, int32(0), res.GetTask().RecurrenceIndex)
+		assert.Equal(t, task.GetSpecifyDatetime(), res.GetTask().GetSpecifyDatetime())
+
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+		assert.Empty(t, listOpenTasksByTitle(t, setUp, task.Title))
+	})
+
+	t.Run("Failure_InvalidRule", func(t *testing.T) {
+		datetime := time.Now().UnixMilli()
+		rule := "FREQ=YEARLY"
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			SpecifyDatetime: &datetime,
+			RecurrenceRule:  &rule,
+		})
+		assert.EqualError(t, err, `rpc error: code = InvalidArgument desc = failed to validate: invalid recurrence rule: unsupported FREQ "YEARLY"`)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_WithoutDatetime", func(t *testing.T) {
+		rule := "FREQ=DAILY"
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:     setUp.categoryId,
+			Title:          util.RandomString(10),
+			Priority:       1,
+			RecurrenceRule: &rule,
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = a recurring task requires specify_datetime")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Subtask", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		subtask := createSubtask(t, setUp, parent.Id)
+		datetime := time.Now().UnixMilli()
+		rule := "FREQ=DAILY"
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: subtask.Id, SpecifyDatetime: &datetime, RecurrenceRule: &rule})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = a subtask cannot recur")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_ParentWithSubtasks", func(t *testing.T) {
+		parent := createTask(t, setUp).GetTask()
+		createSubtask(t, setUp, parent.Id)
+		datetime := time.Now().UnixMilli()
+		rule := "FREQ=DAILY"
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: parent.Id, SpecifyDatetime: &datetime, RecurrenceRule: &rule})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = a recurring task cannot have subtasks")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_SubtaskOfRecurringTask", func(t *testing.T) {
+		parent := createRecurringTask(t, setUp, "FREQ=DAILY", time.Now())
+		parentId := parent.Id
+
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   1,
+			ParentId:   &parentId,
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = a recurring task cannot have subtasks")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_InvalidTimeZone", func(t *testing.T) {
+		timeZone := "Mars/Olympus"
+		res, err := setUp.s.UpdateUser(setUp.ctx, &pb.UpdateUserRequest{TimeZone: &timeZone})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: unknown time zone Mars/Olympus")
+		assert.Nil(t, res)
+	})
+}
+
+func TestTaskReminder(t *testing.T) {
+	setUp := createUserAndCategory(t)
+	due := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+
+	createTaskWithReminders := func(t *testing.T, offsets []int32) *pb.Task {
+		datetime := due.UnixMilli()
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			SpecifyDatetime: &datetime,
+			ReminderOffsets: offsets,
+		})
+		assert.Nil(t, err)
+
+		return res.GetTask()
+	}
+
+	t.Run("Sussess_Create", func(t *testing.T) {
+		scheduled := len(setUp.distributor.reminders)
+		task := createTaskWithReminders(t, []int32{60, 15})
+		assert.Equal(t, []int32{15, 60}, task.ReminderOffsets)
+
+		reminders := setUp.distributor.reminders[scheduled:]
+		assert.Len(t, reminders, 2)
+		assert.Equal(t, due.Add(-15*time.Minute).Unix(), reminders[0].RemindAt)
+		assert.Equal(t, due.Add(-60*time.Minute).Unix(), reminders[1].RemindAt)
+
+		res, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: task.Id})
+		assert.Nil(t, err)
+		assert.Equal(t, []int32{15, 60}, res.GetTask().ReminderOffsets)
+	})
+
+	t.Run("Sussess_Reschedule", func(t *testing.T) {
+		task := createTaskWithReminders(t, []int32{15})
+		scheduled := len(setUp.distributor.reminders)
+		cancelled := len(setUp.distributor.cancelled)
+
+		// Moving the task moves its reminders
+		datetime := due.Add(time.Hour).UnixMilli()
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, SpecifyDatetime: &datetime})
+		assert.Nil(t, err)
+		assert.Equal(t, []int32{15}, res.GetTask().ReminderOffsets)
+		assert.Len(t, setUp.distributor.cancelled[cancelled:], 1)
+		assert.Equal(t, due.Add(-15*time.Minute).Unix(), setUp.distributor.cancelled[cancelled].RemindAt)
+		assert.Len(t, setUp.distributor.reminders[scheduled:], 1)
+		assert.Equal(t, due.Add(45*time.Minute).Unix(), setUp.distributor.reminders[scheduled].RemindAt)
+
+		// Updating anything else leaves them alone
+		title := util.RandomString(10)
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, Title: &title})
+		assert.Nil(t, err)
+		assert.Len(t, setUp.distributor.cancelled[cancelled:], 1)
+
+		// Replacing the offsets
+		res, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, ReminderOffsets: []int32{30, 5}})
+		assert.Nil(t, err)
+		assert.Equal(t, []int32{5, 30}, res.GetTask().ReminderOffsets)
+		assert.Len(t, setUp.distributor.cancelled[cancelled:], 2)
+		assert.Len(t, setUp.distributor.reminders[scheduled:], 3)
+	})
+
+	t.Run("Sussess_Clear", func(t *testing.T) {
+		task := createTaskWithReminders(t, []int32{15, 30})
+		cancelled := len(setUp.distributor.cancelled)
+		clearReminders := true
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, ClearReminders: &clearReminders})
+		assert.Nil(t, err)
+		assert.Empty(t, res.GetTask().ReminderOffsets)
+		assert.Len(t, setUp.distributor.cancelled[cancelled:], 2)
+	})
+
+	t.Run("Sussess_Delete", func(t *testing.T) {
+		task := createTaskWithReminders(t, []int32{15})
+		cancelled := len(setUp.distributor.cancelled)
+
+		_, err := setUp.s.DeleteTask(setUp.ctx, &pb.DeleteTaskRequest{Id: task.Id})
+		assert.Nil(t, err)
+		assert.Len(t, setUp.distributor.cancelled[cancelled:], 1)
+	})
+
+	t.Run("Sussess_DueReminderNotScheduled", func(t *testing.T) {
+		scheduled := len(setUp.distributor.reminders)
+		datetime := time.Now().Add(10 * time.Minute).UnixMilli()
+
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			SpecifyDatetime: &datetime,
+			ReminderOffsets: []int32{5, 15},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, []int32{5, 15}, res.GetTask().ReminderOffsets)
+		assert.Len(t, setUp.distributor.reminders[scheduled:], 1)
+	})
+
+	t.Run("Sussess_NextOccurrence", func(t *testing.T) {
+		datetime := due.UnixMilli()
+		rule := "FREQ=DAILY"
+		cRes, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			SpecifyDatetime: &datetime,
+			RecurrenceRule:  &rule,
+			ReminderOffsets: []int32{15},
+		})
+		assert.Nil(t, err)
+		scheduled := len(setUp.distributor.reminders)
+		isComplete := true
+
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: cRes.GetTask().Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+
+		next := listOpenTasksByTitle(t, setUp, cRes.GetTask().Title)
+		assert.Len(t, next, 1)
+		assert.Equal(t, []int32{15}, next[0].ReminderOffsets)
+		assert.Len(t, setUp.distributor.reminders[scheduled:], 1)
+	})
+
+	t.Run("Failure_WithoutDatetime", func(t *testing.T) {
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			ReminderOffsets: []int32{15},
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = reminders require specify_datetime")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_InvalidOffsets", func(t *testing.T) {
+		task := createTaskWithReminders(t, nil)
+		for _, offsets := range [][]int32{{-1}, {10081}, {15, 15}, {1, 2, 3, 4, 5, 6}} {
+			res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, ReminderOffsets: offsets})
+			assert.Equal(t, codes.InvalidArgument, status.Code(err))
+			assert.Nil(t, res)
+		}
+	})
+
+	t.Run("Failure_ClearWithOffsets", func(t *testing.T) {
+		task := createTaskWithReminders(t, nil)
+		clearReminders := true
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: task.Id, ReminderOffsets: []int32{15}, ClearReminders: &clearReminders})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = clear_reminders cannot be used with reminder_offsets")
+		assert.Nil(t, res)
+	})
+}
+
+func TestTaskTag(t *testing.T) {
+	setUp := createUserAndCategory(t)
+
+	createTag := func(t *testing.T) int32 {
+		res, err := setUp.s.CreateTag(setUp.ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+		assert.Nil(t, err)
+
+		return res.GetTag().Id
+	}
+	createTaggedTask := func(t *testing.T, tagIds []int32) *pb.Task {
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   1,
+			TagIds:     tagIds,
+		})
+		assert.Nil(t, err)
+
+		return res.GetTask()
+	}
+	listTaskIds := func(t *testing.T, tagIds []int32, matchAll bool) []int32 {
+		res, err := setUp.s.ListTask(setUp.ctx, &pb.ListTaskRequest{
+			Page:         1,
+			PageSize:     999,
+			TagIds:       tagIds,
+			MatchAllTags: &matchAll,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(len(res.GetTasks().GetData())), res.TotalCount)
+
+		ids := make([]int32, 0)
+		for _, task := range res.GetTasks().GetData() {
+			ids = append(ids, task.Id)
+		}
+
+		return ids
+	}
+
+	work, home, urgent := createTag(t), createTag(t), createTag(t)
+	workTask := createTaggedTask(t, []int32{work})
+	bothTask := createTaggedTask(t, []int32{work, urgent})
+	homeTask := createTaggedTask(t, []int32{home})
+	untagged := createTaggedTask(t, nil)
+
+	t.Run("Sussess_Create", func(t *testing.T) {
+		assert.Len(t, bothTask.Tags, 2)
+
+		res, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: bothTask.Id})
+		assert.Nil(t, err)
+		assert.Len(t, res.GetTask().Tags, 2)
+		assert.Empty(t, untagged.Tags)
+	})
+
+	t.Run("Sussess_ListAnyTag", func(t *testing.T) {
+		ids := listTaskIds(t, []int32{work, home}, false)
+		assert.ElementsMatch(t, []int32{workTask.Id, bothTask.Id, homeTask.Id}, ids)
+	})
+
+	t.Run("Sussess_ListAllTags", func(t *testing.T) {
+		ids := listTaskIds(t, []int32{work, urgent}, true)
+		assert.ElementsMatch(t, []int32{bothTask.Id}, ids)
+	})
+
+	t.Run("Sussess_Update", func(t *testing.T) {
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: untagged.Id, TagIds: []int32{home}})
+		assert.Nil(t, err)
+		assert.Len(t, res.GetTask().Tags, 1)
+		assert.Equal(t, home, res.GetTask().Tags[0].Id)
+		assert.ElementsMatch(t, []int32{homeTask.Id, untagged.Id}, listTaskIds(t, []int32{home}, false))
+
+		clearTags := true
+		res, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: untagged.Id, ClearTags: &clearTags})
+		assert.Nil(t, err)
+		assert.Empty(t, res.GetTask().Tags)
+		assert.ElementsMatch(t, []int32{homeTask.Id}, listTaskIds(t, []int32{home}, false))
+	})
+
+	t.Run("Sussess_DeleteTag", func(t *testing.T) {
+		_, err := setUp.s.DeleteTag(setUp.ctx, &pb.DeleteTagRequest{Id: urgent})
+		assert.Nil(t, err)
+
+		res, err := setUp.s.GetTask(setUp.ctx, &pb.GetTaskRequest{Id: bothTask.Id})
+		assert.Nil(t, err)
+		assert.Len(t, res.GetTask().Tags, 1)
+		assert.Equal(t, work, res.GetTask().Tags[0].Id)
+	})
+
+	t.Run("Sussess_NextOccurrence", func(t *testing.T) {
+		datetime := time.Now().Add(24 * time.Hour).UnixMilli()
+		rule := "FREQ=DAILY"
+		cRes, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId:      setUp.categoryId,
+			Title:           util.RandomString(10),
+			Priority:        1,
+			SpecifyDatetime: &datetime,
+			RecurrenceRule:  &rule,
+			TagIds:          []int32{work},
+		})
+		assert.Nil(t, err)
+		isComplete := true
+
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: cRes.GetTask().Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+
+		next := listOpenTasksByTitle(t, setUp, cRes.GetTask().Title)
+		assert.Len(t, next, 1)
+		assert.Len(t, next[0].Tags, 1)
+		assert.Equal(t, work, next[0].Tags[0].Id)
+	})
+
+	t.Run("Failure_OtherUserTag", func(t *testing.T) {
+		other := createUserAndCategory(t)
+		oRes, err := other.s.CreateTag(other.ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+		assert.Nil(t, err)
+
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      util.RandomString(10),
+			Priority:   1,
+			TagIds:     []int32{work, oRes.GetTag().Id},
+		})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, res)
+
+		uRes, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: workTask.Id, TagIds: []int32{oRes.GetTag().Id}})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = tag ID not found")
+		assert.Nil(t, uRes)
+	})
+
+	t.Run("Failure_ClearWithTags", func(t *testing.T) {
+		clearTags := true
+
+		res, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: workTask.Id, TagIds: []int32{work}, ClearTags: &clearTags})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = clear_tags cannot be used with tag_ids")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_DuplicateTags", func(t *testing.T) {
+		res, err := setUp.s.ListTask(setUp.ctx, &pb.ListTaskRequest{Page: 1, PageSize: 999, TagIds: []int32{work, work}})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		assert.Nil(t, res)
+	})
+}
+
+func TestSearchTasks(t *testing.T) {
+	setUp := createUserAndCategory(t)
+	word := util.RandomString(8)
+	other := util.RandomString(8)
+
+	createNoted := func(t *testing.T, title string, note string) *pb.Task {
+		res, err := setUp.s.CreateTask(setUp.ctx, &pb.CreateTaskRequest{
+			CategoryId: setUp.categoryId,
+			Title:      title,
+			Note:       &note,
+			Priority:   1,
+		})
+		assert.Nil(t, err)
+
+		return res.GetTask()
+	}
+	search := func(t *testing.T, req *pb.SearchTasksRequest) []*pb.TaskSearchResult {
+		req.Page = 1
+		req.PageSize = 999
+		res, err := setUp.s.SearchTasks(setUp.ctx, req)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, int32(len(res.GetTaskSearchResults().GetData())), res.TotalCount)
+
+		return res.GetTaskSearchResults().GetData()
+	}
+
+	inTitle := createNoted(t, "Buy "+word, "from the store")
+	inNote := createNoted(t, "Errands "+util.RandomString(4), "remember the "+word+" before noon")
+	both := createNoted(t, word+" and "+other, "")
+	createNoted(t, "Unrelated "+util.RandomString(4), "nothing to see")
+
+	t.Run("Sussess_Ranked", func(t *testing.T) {
+		results := search(t, &pb.SearchTasksRequest{Query: word})
+		assert.Len(t, results, 3)
+
+		// A match in the title outranks a match in the note
+		ids := make([]int32, 0)
+		for _, result := range results {
+			ids = append(ids, result.Task.Id)
+		}
+		assert.ElementsMatch(t, []int32{inTitle.Id, inNote.Id, both.Id}, ids)
+		assert.Equal(t, inNote.Id, ids[2])
+		assert.GreaterOrEqual(t, results[0].Rank, results[2].Rank)
+	})
+
+	t.Run("Sussess_Highlight", func(t *testing.T) {
+		results := search(t, &pb.SearchTasksRequest{Query: word})
+		for _, result := range results {
+			switch result.Task.Id {
+			case inTitle.Id:
+				assert.Equal(t, "Buy <b>"+word+"</b>", result.TitleHighlight)
+			case inNote.Id:
+				assert.Contains(t, result.NoteSnippet, "<b>"+word+"</b>")
+				assert.NotContains(t, result.TitleHighlight, "<b>")
+			}
+		}
+	})
+
+	t.Run("Sussess_HighlightEscaped", func(t *testing.T) {
+		markup := util.RandomString(8)
+		createNoted(t, markup+` <img src=x onerror="alert(1)">`, "<script>"+markup+"</script>")
+
+		results := search(t, &pb.SearchTasksRequest{Query: markup})
+		assert.Len(t, results, 1)
+		assert.Equal(t, "<b>"+markup+"</b> &lt;img src=x onerror=&quot;alert(1)&quot;&gt;", results[0].TitleHighlight)
+		assert.Contains(t, results[0].NoteSnippet, "&lt;script&gt;<b>"+markup+"</b>&lt;/script&gt;")
+		assert.NotContains(t, results[0].NoteSnippet, "<script>")
+	})
+
+	t.Run("Sussess_WebSearchSyntax", func(t *testing.T) {
+		results := search(t, &pb.SearchTasksRequest{Query: word + " " + other})
+		assert.Len(t, results, 1)
+		assert.Equal(t, both.Id, results[0].Task.Id)
+
+		results = search(t, &pb.SearchTasksRequest{Query: word + " -" + other})
+		assert.Len(t, results, 2)
+
+		results = search(t, &pb.SearchTasksRequest{Query: other + " or noon"})
+		assert.Len(t, results, 2)
+	})
+
+	t.Run("Sussess_Filters", func(t *testing.T) {
+		isComplete := true
+		_, err := setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: inTitle.Id, IsComplete: &isComplete})
+		assert.Nil(t, err)
+
+		isComplete = false
+		results := search(t, &pb.SearchTasksRequest{Query: word, IsComplete: &isComplete})
+		assert.Len(t, results, 2)
+
+		tRes, err := setUp.s.CreateTag(setUp.ctx, &pb.CreateTagRequest{Name: util.RandomString(6)})
+		assert.Nil(t, err)
+		_, err = setUp.s.UpdateTask(setUp.ctx, &pb.UpdateTaskRequest{Id: both.Id, TagIds: []int32{tRes.GetTag().Id}})
+		assert.Nil(t, err)
+
+		results = search(t, &pb.SearchTasksRequest{Query: word, TagIds: []int32{tRes.GetTag().Id}})
+		assert.Len(t, results, 1)
+		assert.Equal(t, both.Id, results[0].Task.Id)
+	})
+
+	t.Run("Sussess_OnlyOwnTasks", func(t *testing.T) {
+		otherSetUp := createUserAndCategory(t)
+		res, err := otherSetUp.s.SearchTasks(otherSetUp.ctx, &pb.SearchTasksRequest{Query: word, Page: 1, PageSize: 999})
+		assert.Nil(t, err)
+		assert.Equal(t, int32(0), res.TotalCount)
+		assert.Empty(t, res.GetTaskSearchResults().GetData())
+	})
+
+	t.Run("Failure_EmptyQuery", func(t *testing.T) {
+		res, err := setUp.s.SearchTasks(setUp.ctx, &pb.SearchTasksRequest{Page: 1, PageSize: 999})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqSearchTasks.Query' Error:Field validation for 'Query' failed on the 'required' tag")
+		assert.Nil(t, res)
+	})
+}
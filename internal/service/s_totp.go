@@ -0,0 +1,405 @@
+package service
+
+import (
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/model"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"net/http"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	totpRequiredMessage  = "totp code required"
+	totpIncorrectMessage = "totp code is incorrect"
+
+	defaultTotpChallengeTtl = 5
+)
+
+func totpChallengeTtl(cnf *config.Config) int {
+	if cnf.TotpChallengeTtl <= 0 {
+		return defaultTotpChallengeTtl
+	}
+
+	return cnf.TotpChallengeTtl
+}
+
+// isTotpCode tells the codes of the authenticator app apart from the recovery codes
+func isTotpCode(code string) bool {
+	if len(code) != util.TotpDigits {
+		return false
+	}
+	for _, c := range code {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// redeemTotpCode checks a code of the authenticator app or a recovery code and consumes it,
+// a TOTP code cannot be used twice and a recovery code only once.
+func redeemTotpCode(conn model.DBExecutable, cnf *config.Config, user *model.User, code string) (bool, error) {
+	if !isTotpCode(code) {
+		codeHash := util.HashSecretCode(cnf.SecretCodeHashKey, util.NormalizeRecoveryCode(code))
+		return model.UseRecoveryCode(conn, user.ID, codeHash)
+	}
+
+	if user.TotpSecret == nil {
+		return false, nil
+	}
+
+	secret, err := util.DecryptString(cnf.TotpEncryptionKey, *user.TotpSecret)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := util.ValidateTotp(secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+
+	return model.UseTotpStep(conn, user.ID, step)
+}
+
+type ReqLoginTotp struct {
+	ChallengeToken string `json:"challenge_token" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+func (s *Server) LoginTOTP(ctx context.Context, req *pb.LoginTOTPRequest) (*pb.Response, error) {
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqLogin := &ReqLoginTotp{}
+	if err := bindRequest(req, reqLogin); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	claims, err := util.ParseToken(util.GetKeySet(), reqLogin.ChallengeToken)
+	if err != nil || claims.TokenType != util.TokenTypeTotpChallenge {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid challenge token")
+	}
+
+	getUser := model.GetUserByID(conn, claims.UserID)
+	if getUser == nil || !getUser.IsTotpEnabled {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid challenge token")
+	}
+
+	if err := checkUserStatus(getUser); err != nil {
+		return nil, err
+	}
+
+	// The codes share the failed attempts of the password
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, getUser.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return nil, status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return nil, status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	redeemed, redeemErr := redeemTotpCode(conn, cnf, getUser, reqLogin.Code)
+	if redeemErr != nil {
+		log.Error.Printf("failed to check totp code: %v", redeemErr)
+		return nil, status.Errorf(codes.Internal, "failed to check totp code: %v", redeemErr)
+	}
+	if !redeemed {
+		return nil, s.loginFailed(ctx, getUser.Email, ip, getUser, status.Error(codes.Unauthenticated, totpIncorrectMessage))
+	}
+
+	if err := s.loginGuard.Succeed(ctx, getUser.Email); err != nil {
+		log.Error.Printf("failed to reset login attempts: %v", err)
+	}
+
+	// Grnerate token
+	token, refreshToken, tokenErr := issueTokens(conn, cnf, getUser)
+	if tokenErr != nil {
+		log.Error.Printf("failed to generate token: %v", tokenErr)
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", tokenErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_User{
+			User: &pb.User{
+				Id:           int32(getUser.ID),
+				Username:     getUser.Username,
+				Email:        getUser.Email,
+				CreatedAt:    util.GetFullDateStr(getUser.CreatedAt),
+				UpdatedAt:    util.GetFullDateStr(getUser.UpdatedAt),
+				Language:     getUser.Language,
+				TimeZone:     getUser.TimeZone,
+				Token:        &token,
+				RefreshToken: &refreshToken,
+			},
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+func (s *Server) EnrollTOTP(ctx context.Context, req *pb.EnrollTOTPRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Check if the user ID not found
+	getUser := model.GetUserByID(conn, claims.UserID)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	if getUser.IsTotpEnabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+
+	// A new enrollment replaces the secret of an unconfirmed one
+	secret, secretErr := util.GenerateTotpSecret()
+	if secretErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate totp secret: %v", secretErr)
+	}
+
+	encryptedSecret, encryptErr := util.EncryptString(cnf.TotpEncryptionKey, secret)
+	if encryptErr != nil {
+		log.Error.Printf("failed to encrypt totp secret: %v", encryptErr)
+		return nil, status.Errorf(codes.Internal, "failed to encrypt totp secret: %v", encryptErr)
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	fv := &model.UserFieldValues{
+		TotpSecret: model.GiveColNullString(&encryptedSecret),
+		UpdatedAt:  model.GiveColTime(time.Now().UTC()),
+	}
+	if err := model.UpdateUser(tx, getUser.ID, fv); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to enroll totp from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to enroll totp from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_Totp{
+			Totp: &pb.TOTP{
+				Secret: secret,
+				Uri:    util.TotpKeyURI(cnf.TotpIssuer, getUser.Email, secret),
+			},
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+type ReqConfirmTotp struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+func (s *Server) ConfirmTOTP(ctx context.Context, req *pb.ConfirmTOTPRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqConfirm := &ReqConfirmTotp{}
+	if err := bindRequest(req, reqConfirm); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Check if the user ID not found
+	getUser := model.GetUserByID(conn, claims.UserID)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	if getUser.IsTotpEnabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is already enabled")
+	}
+	if getUser.TotpSecret == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication has not been enrolled")
+	}
+
+	// Wrong codes count against the same lockout as the password
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, getUser.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return nil, status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return nil, status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	// The first code proves the authenticator app holds the secret
+	redeemed, redeemErr := redeemTotpCode(tx, cnf, getUser, reqConfirm.Code)
+	if redeemErr != nil {
+		log.Error.Printf("failed to check totp code: %v", redeemErr)
+		return nil, status.Errorf(codes.Internal, "failed to check totp code: %v", redeemErr)
+	}
+	if !redeemed {
+		return nil, s.loginFailed(ctx, getUser.Email, ip, getUser, status.Error(codes.InvalidArgument, totpIncorrectMessage))
+	}
+
+	if err := s.loginGuard.Succeed(ctx, getUser.Email); err != nil {
+		log.Error.Printf("failed to reset login attempts: %v", err)
+	}
+
+	fv := &model.UserFieldValues{
+		IsTotpEnabled: model.GiveColBool(true),
+		UpdatedAt:     model.GiveColTime(time.Now().UTC()),
+	}
+	if err := model.UpdateUser(tx, getUser.ID, fv); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	// Only the hashes are stored, the codes are shown this once
+	recoveryCodes := util.GenerateRecoveryCodes(util.RecoveryCodeCount)
+	codeHashes := make([]string, 0, len(recoveryCodes))
+	for _, code := range recoveryCodes {
+		codeHashes = append(codeHashes, util.HashSecretCode(cnf.SecretCodeHashKey, util.NormalizeRecoveryCode(code)))
+	}
+	if err := model.ReplaceRecoveryCodes(tx, getUser.ID, codeHashes); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create recovery codes: %v", err)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to confirm totp from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to confirm totp from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_Totp{
+			Totp: &pb.TOTP{
+				RecoveryCodes: recoveryCodes,
+			},
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+type ReqDisableTotp struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+func (s *Server) DisableTOTP(ctx context.Context, req *pb.DisableTOTPRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqDisable := &ReqDisableTotp{}
+	if err := bindRequest(req, reqDisable); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Check if the user ID not found
+	getUser := model.GetUserByID(conn, claims.UserID)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	if !getUser.IsTotpEnabled {
+		return nil, status.Errorf(codes.FailedPrecondition, "two-factor authentication is not enabled")
+	}
+
+	// Turning 2FA off requires both factors, a stolen access token alone is not enough.
+	// Either one being wrong counts against the lockout, which is only reset once both are right.
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, getUser.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return nil, status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return nil, status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	if !checkUserPassword(getUser, reqDisable.Password) {
+		return nil, s.loginFailed(ctx, getUser.Email, ip, getUser, status.Error(codes.InvalidArgument, "password is incorrect"))
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	redeemed, redeemErr := redeemTotpCode(tx, cnf, getUser, reqDisable.Code)
+	if redeemErr != nil {
+		log.Error.Printf("failed to check totp code: %v", redeemErr)
+		return nil, status.Errorf(codes.Internal, "failed to check totp code: %v", redeemErr)
+	}
+	if !redeemed {
+		return nil, s.loginFailed(ctx, getUser.Email, ip, getUser, status.Error(codes.InvalidArgument, totpIncorrectMessage))
+	}
+
+	if err := s.loginGuard.Succeed(ctx, getUser.Email); err != nil {
+		log.Error.Printf("failed to reset login attempts: %v", err)
+	}
+
+	fv := &model.UserFieldValues{
+		TotpSecret:    model.GiveColNullString(nil),
+		IsTotpEnabled: model.GiveColBool(false),
+		UpdatedAt:     model.GiveColTime(time.Now().UTC()),
+	}
+	if err := model.UpdateUser(tx, getUser.ID, fv); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	if err := model.DeleteRecoveryCodesByUserID(tx, getUser.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete recovery codes: %v", err)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to disable totp from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to disable totp from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
@@ -0,0 +1,483 @@
+package service_test
+
+import (
+	"bytes"
+	"context"
+	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
+	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setUpTotp() (*service.Server, error) {
+	var mockConfigContent bytes.Buffer
+	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
+	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+	mockConfigContent.WriteString("TOTP_ENCRYPTION_KEY=" + config.TotpEncryptionKey + "\n")
+	mockConfigContent.WriteString("TOTP_ISSUER=" + config.TotpIssuer + "\n")
+	mockConfigContent.WriteString("TOTP_CHALLENGE_TTL=" + strconv.Itoa(config.TotpChallengeTtl) + "\n")
+	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
+	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
+	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
+	mockConfigContent.WriteString("DB_PASS=" + config.SourcePassword + "\n")
+	mockConfigContent.WriteString("DB_NAME=" + config.SourceDataBase + "\n")
+	mockConfigContent.WriteString("SSL_MODE=" + config.SourceSSLMode + "\n")
+	mockConfigContent.WriteString("DB_CONN_MAX_LT_SEC=" + strconv.Itoa(config.SourceDBConnMaxLTSec) + "\n")
+	mockConfigContent.WriteString("DB_MAX_CONN=" + strconv.Itoa(config.SourceMaxConn) + "\n")
+	mockConfigContent.WriteString("DB_MAX_IDLE=" + strconv.Itoa(config.SourceMaxIdle) + "\n")
+	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
+	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
+	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
+	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
+	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
+	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
+	mockConfigContent.WriteString("ENABLE_FILE_OUTPUT=" + strconv.FormatBool(config.EnableFileOutput) + "\n")
+
+	// Create app.env file
+	appFolderPath, _ := filepath.Abs(filepath.Dir(os.Args[0]))
+	mockConfigFile := filepath.Join(appFolderPath, "app.env")
+	err := os.WriteFile(mockConfigFile, mockConfigContent.Bytes(), 0644)
+	defer os.Remove(mockConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	// Init config
+	loadErr := config.Load()
+	if loadErr != nil {
+		return nil, loadErr
+	}
+
+	// Init log
+	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
+
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, err
+	}
+
+	// Init sql
+	opt := &db.Option{
+		Host:     config.SourceHost,
+		Port:     config.SourcePort,
+		Username: config.SourceUser,
+		Password: config.SourcePassword,
+		DBName:   config.SourceDataBase,
+		SSLMode:  config.SourceSSLMode,
+	}
+
+	err = db.Init(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	s := service.NewServer(&mockTaskDistributor{}, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
+
+	return s, nil
+}
+
+// currentTotpCode returns the code of the period offset from now, every redemption needs a newer period than the last one
+func currentTotpCode(t *testing.T, secret string, offset int) string {
+	code, err := util.TotpCode(secret, util.TotpStep(time.Now())+offset)
+	assert.NoError(t, err)
+
+	return code
+}
+
+type totpUser struct {
+	ctx           context.Context
+	email         string
+	password      string
+	secret        string
+	recoveryCodes []string
+}
+
+// createTotpUser registers a verified user, and enables 2FA when enable is true
+func createTotpUser(t *testing.T, s *service.Server, enable bool) *totpUser {
+	registered, password := registerTestUser(t, s, util.RandomEmail(), true)
+	user := &totpUser{
+		ctx:      createAuthenticatedContext(int(registered.Id)),
+		email:    registered.Email,
+		password: password,
+	}
+	if !enable {
+		return user
+	}
+
+	eRes, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+	assert.Nil(t, err)
+	user.secret = eRes.GetTotp().Secret
+
+	cRes, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+		Code: currentTotpCode(t, user.secret, -1),
+	})
+	assert.Nil(t, err)
+	user.recoveryCodes = cRes.GetTotp().RecoveryCodes
+
+	return user
+}
+
+func TestEnrollTOTP(t *testing.T) {
+	s, err := setUpTotp()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		res, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Len(t, res.GetTotp().Secret, 32)
+		assert.Equal(t, util.TotpKeyURI(config.TotpIssuer, user.email, res.GetTotp().Secret), res.GetTotp().Uri)
+		assert.Empty(t, res.GetTotp().RecoveryCodes)
+
+		// Login stays single step until the enrollment is confirmed
+		lRes, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    user.email,
+			Password: user.password,
+		})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, lRes.GetUser().GetToken())
+		assert.Nil(t, lRes.GetUser().ChallengeToken)
+	})
+
+	t.Run("Failure_AlreadyEnabled", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = two-factor authentication is already enabled")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		res, err := s.EnrollTOTP(context.Background(), &pb.EnrollTOTPRequest{})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+	})
+}
+
+func TestConfirmTOTP(t *testing.T) {
+	s, err := setUpTotp()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		eRes, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+		assert.Nil(t, err)
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: currentTotpCode(t, eRes.GetTotp().Secret, 0),
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Len(t, res.GetTotp().RecoveryCodes, util.RecoveryCodeCount)
+		assert.Empty(t, res.GetTotp().Secret)
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		eRes, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+		assert.Nil(t, err)
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: currentTotpCode(t, eRes.GetTotp().Secret, -3),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = totp code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Lockout", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		eRes, err := s.EnrollTOTP(user.ctx, &pb.EnrollTOTPRequest{})
+		assert.Nil(t, err)
+
+		for i := 1; i < lockout.DefaultPolicy().MaxEmailAttempts; i++ {
+			_, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{Code: "000000"})
+			assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = totp code is incorrect")
+		}
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{Code: "000000"})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+
+		res, err = s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: currentTotpCode(t, eRes.GetTotp().Secret, 0),
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_NotEnrolled", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: "123456",
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = two-factor authentication has not been enrolled")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_AlreadyEnabled", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: currentTotpCode(t, user.secret, 0),
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = two-factor authentication is already enabled")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_InvalidCode", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		res, err := s.ConfirmTOTP(user.ctx, &pb.ConfirmTOTPRequest{
+			Code: "12ab56",
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqConfirmTotp.Code' Error:Field validation for 'Code' failed on the 'numeric' tag")
+		assert.Nil(t, res)
+	})
+}
+
+func TestLoginTOTP(t *testing.T) {
+	s, err := setUpTotp()
+	assert.NoError(t, err)
+
+	login := func(t *testing.T, user *totpUser) string {
+		res, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    user.email,
+			Password: user.password,
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, "totp code required", res.Message)
+		assert.Nil(t, res.GetUser().Token)
+		assert.Nil(t, res.GetUser().RefreshToken)
+		assert.NotEmpty(t, res.GetUser().GetChallengeToken())
+
+		return res.GetUser().GetChallengeToken()
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           currentTotpCode(t, user.secret, 0),
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.NotEmpty(t, res.GetUser().GetToken())
+		assert.NotEmpty(t, res.GetUser().GetRefreshToken())
+	})
+
+	t.Run("Success_RecoveryCode", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           user.recoveryCodes[0],
+		})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, res.GetUser().GetToken())
+
+		// A recovery code is single use
+		res, err = s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           user.recoveryCodes[0],
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = totp code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_ReplayedCode", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+		code := currentTotpCode(t, user.secret, 0)
+
+		_, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           code,
+		})
+		assert.Nil(t, err)
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           code,
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = totp code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           currentTotpCode(t, user.secret, 3),
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = totp code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Lockout", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+		challengeToken := login(t, user)
+
+		for i := 1; i < lockout.DefaultPolicy().MaxEmailAttempts; i++ {
+			_, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+				ChallengeToken: challengeToken,
+				Code:           "000000",
+			})
+			assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = totp code is incorrect")
+		}
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: challengeToken,
+			Code:           "000000",
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+
+		res, err = s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: challengeToken,
+			Code:           currentTotpCode(t, user.secret, 0),
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_AccessTokenAsChallenge", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: login(t, user),
+			Code:           currentTotpCode(t, user.secret, 0),
+		})
+		assert.Nil(t, err)
+
+		res, err = s.LoginTOTP(context.Background(), &pb.LoginTOTPRequest{
+			ChallengeToken: res.GetUser().GetToken(),
+			Code:           currentTotpCode(t, user.secret, 1),
+		})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = invalid challenge token")
+		assert.Nil(t, res)
+	})
+}
+
+func TestDisableTOTP(t *testing.T) {
+	s, err := setUpTotp()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     currentTotpCode(t, user.secret, 0),
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+
+		// Login is single step again
+		lRes, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    user.email,
+			Password: user.password,
+		})
+		assert.Nil(t, err)
+		assert.NotEmpty(t, lRes.GetUser().GetToken())
+		assert.Nil(t, lRes.GetUser().ChallengeToken)
+	})
+
+	t.Run("Success_RecoveryCode", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     user.recoveryCodes[len(user.recoveryCodes)-1],
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+	})
+
+	t.Run("Failure_IncorrectPassword", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: "invalid-password",
+			Code:     currentTotpCode(t, user.secret, 0),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = password is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     "abcde-fghij",
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = totp code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_Lockout", func(t *testing.T) {
+		user := createTotpUser(t, s, true)
+
+		// The right password does not reset the attempts of the wrong codes
+		for i := 1; i < lockout.DefaultPolicy().MaxEmailAttempts; i++ {
+			_, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+				Password: user.password,
+				Code:     "000000",
+			})
+			assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = totp code is incorrect")
+		}
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     "000000",
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+
+		res, err = s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     currentTotpCode(t, user.secret, 0),
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_NotEnabled", func(t *testing.T) {
+		user := createTotpUser(t, s, false)
+
+		res, err := s.DisableTOTP(user.ctx, &pb.DisableTOTPRequest{
+			Password: user.password,
+			Code:     "123456",
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = two-factor authentication is not enabled")
+		assert.Nil(t, res)
+	})
+}
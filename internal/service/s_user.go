@@ -2,14 +2,19 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
 	"go-todolist-grpc/internal/model"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
 	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/mail"
 	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service/queue"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/hibiken/asynq"
@@ -18,18 +23,29 @@ import (
 )
 
 type ReqRegister struct {
-	Email    string `json:"email" validate:"required,email,max=64"`
-	Username string `json:"username" validate:"required,min=3,max=32"`
-	Password string `json:"password" validate:"required,min=8"`
+	Email    string  `json:"email" validate:"required,email,max=64"`
+	Username string  `json:"username" validate:"required,min=3,max=32"`
+	Password string  `json:"password" validate:"required,min=8"`
+	Language *string `json:"language" validate:"omitempty,min=2,max=16"`
+	TimeZone *string `json:"time_zone" validate:"omitempty,max=64"`
 }
 
 func (ins ReqRegister) toFieldValues() model.UserFieldValues {
 	now := time.Now().UTC()
 	fv := model.UserFieldValues{}
-	fv.Email = model.GiveColString(ins.Email)
+	fv.Email = model.GiveColString(util.NormalizeEmail(ins.Email))
 	fv.Username = model.GiveColString(ins.Username)
 	fv.Password = model.GiveColString(ins.Password)
 	fv.Status = model.GiveColBool(true)
+	fv.Role = model.GiveColString(util.RoleUser)
+	fv.Language = model.GiveColString(mail.DefaultLocale)
+	if ins.Language != nil {
+		fv.Language = model.GiveColString(*ins.Language)
+	}
+	fv.TimeZone = model.GiveColString(util.TZLocStr)
+	if ins.TimeZone != nil {
+		fv.TimeZone = model.GiveColString(*ins.TimeZone)
+	}
 	fv.CreatedAt = model.GiveColTime(now)
 	fv.UpdatedAt = model.GiveColTime(now)
 	return fv
@@ -44,6 +60,11 @@ func (s *Server) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest)
 	if err := bindRequest(req, reqRegister); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
 	}
+	if reqRegister.TimeZone != nil {
+		if _, err := util.LoadTimeZone(*reqRegister.TimeZone); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err)
+		}
+	}
 
 	// Check if the email is already registered
 	getUser := model.GetUserByEmail(conn, reqRegister.Email)
@@ -104,6 +125,8 @@ func (s *Server) RegisterUser(ctx context.Context, req *pb.RegisterUserRequest)
 		Email:     user.Email.Val,
 		CreatedAt: util.GetFullDateStr(user.CreatedAt.Val),
 		UpdatedAt: util.GetFullDateStr(user.UpdatedAt.Val),
+		Language:  user.Language.Val,
+		TimeZone:  user.TimeZone.Val,
 	}
 
 	return &pb.Response{
@@ -130,23 +153,71 @@ func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Response,
 		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
 	}
 
-	// Check if this email is unregistered
+	// Refuse while the email or the IP is locked out
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, reqLogin.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return nil, status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return nil, status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	// An unregistered email and a wrong password get the same answer
 	getUser := model.GetUserByEmail(conn, reqLogin.Email)
-	if getUser == nil {
-		return nil, status.Errorf(codes.NotFound, "this email is unregistered")
+	if !checkUserPassword(getUser, reqLogin.Password) {
+		return nil, s.loginFailed(ctx, reqLogin.Email, ip, getUser, status.Error(codes.Unauthenticated, loginFailedMessage))
+	}
+
+	if err := checkUserStatus(getUser); err != nil {
+		return nil, err
 	}
 
 	if !getUser.IsEmailVerified {
 		return nil, status.Errorf(codes.PermissionDenied, "this email has not been verified yet")
 	}
 
-	// Check user password
-	if checkPassword := util.CheckPasswordHash(reqLogin.Password, getUser.Password); !checkPassword {
-		return nil, status.Errorf(codes.InvalidArgument, "password is incorrect")
+	// With 2FA the attempts are kept until LoginTOTP succeeds
+	if !getUser.IsTotpEnabled {
+		if err := s.loginGuard.Succeed(ctx, reqLogin.Email); err != nil {
+			log.Error.Printf("failed to reset login attempts: %v", err)
+		}
+	}
+
+	return signIn(conn, cnf, getUser)
+}
+
+// signIn issues the tokens of a user who passed the first factor, or the challenge token when 2FA is enabled
+func signIn(conn model.DBExecutable, cnf *config.Config, getUser *model.User) (*pb.Response, error) {
+	// With 2FA the first factor only earns a challenge token to be exchanged by LoginTOTP
+	if getUser.IsTotpEnabled {
+		challengeToken, _, challengeErr := util.GenerateToken(totpChallengeTtl(cnf), util.GetKeySet(), util.TokenTypeTotpChallenge, getUser.ID, getUser.Role)
+		if challengeErr != nil {
+			log.Error.Printf("failed to generate challenge token: %v", challengeErr)
+			return nil, status.Errorf(codes.Internal, "failed to generate challenge token: %v", challengeErr)
+		}
+
+		return &pb.Response{
+			Data: &pb.Response_User{
+				User: &pb.User{
+					Id:             int32(getUser.ID),
+					Username:       getUser.Username,
+					Email:          getUser.Email,
+					CreatedAt:      util.GetFullDateStr(getUser.CreatedAt),
+					UpdatedAt:      util.GetFullDateStr(getUser.UpdatedAt),
+					Language:       getUser.Language,
+					TimeZone:       getUser.TimeZone,
+					ChallengeToken: &challengeToken,
+				},
+			},
+			Status:  http.StatusOK,
+			Message: totpRequiredMessage,
+		}, nil
 	}
 
 	// Grnerate token
-	token, tokenErr := util.GenerateToken(cnf.JwtTtl, cnf.JwtSecretKey, int(getUser.ID))
+	token, refreshToken, tokenErr := issueTokens(conn, cnf, getUser)
 	if tokenErr != nil {
 		log.Error.Printf("failed to generate token: %v", tokenErr)
 		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", tokenErr)
@@ -155,12 +226,15 @@ func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Response,
 	return &pb.Response{
 		Data: &pb.Response_User{
 			User: &pb.User{
-				Id:        int32(getUser.ID),
-				Username:  getUser.Username,
-				Email:     getUser.Email,
-				CreatedAt: util.GetFullDateStr(getUser.CreatedAt),
-				UpdatedAt: util.GetFullDateStr(getUser.UpdatedAt),
-				Token:     &token,
+				Id:           int32(getUser.ID),
+				Username:     getUser.Username,
+				Email:        getUser.Email,
+				CreatedAt:    util.GetFullDateStr(getUser.CreatedAt),
+				UpdatedAt:    util.GetFullDateStr(getUser.UpdatedAt),
+				Language:     getUser.Language,
+				TimeZone:     getUser.TimeZone,
+				Token:        &token,
+				RefreshToken: &refreshToken,
 			},
 		},
 		Status:  http.StatusOK,
@@ -168,17 +242,272 @@ func (s *Server) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Response,
 	}, nil
 }
 
+const (
+	loginFailedMessage        = "email or password is incorrect"
+	loginLockedMessage        = "too many failed login attempts, please try again later"
+	accountDisabledMessage    = "this account has been disabled"
+	accountDeactivatedMessage = "this account has been deactivated, reactivate it to sign in"
+)
+
+// checkUserStatus refuses a disabled account, telling apart the account the user deactivated and can reactivate
+func checkUserStatus(user *model.User) error {
+	if user.Status {
+		return nil
+	}
+
+	if user.DeactivatedAt != nil {
+		return status.Error(codes.FailedPrecondition, accountDeactivatedMessage)
+	}
+
+	return status.Error(codes.PermissionDenied, accountDisabledMessage)
+}
+
+// dummyPasswordHashes holds the hash of a random password by bcrypt cost
+var dummyPasswordHashes sync.Map
+
+// checkUserPassword compares the password with the hash of the user. An unknown user is compared with a dummy hash
+// of the configured cost, so that an unregistered email takes as long to answer as a registered one.
+func checkUserPassword(user *model.User, password string) bool {
+	if user != nil {
+		return util.CheckPasswordHash(password, user.Password)
+	}
+
+	cost := config.Get().BcryptCost
+	hash, ok := dummyPasswordHashes.Load(cost)
+	if !ok {
+		dummyHash, err := util.HashPassword(cost, util.RandomString(16))
+		if err != nil {
+			log.Error.Printf("failed to hash dummy password: %v", err)
+		}
+		hash, _ = dummyPasswordHashes.LoadOrStore(cost, dummyHash)
+	}
+	util.CheckPasswordHash(password, hash.(string))
+
+	return false
+}
+
+// verifyUserPassword checks the password a signed-in user confirms an action with, the attempts are counted
+// with those of Login so that a stolen access token cannot be used to guess the password
+func (s *Server) verifyUserPassword(ctx context.Context, user *model.User, password string, message string) error {
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, user.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	if !checkUserPassword(user, password) {
+		return s.loginFailed(ctx, user.Email, ip, user, status.Error(codes.InvalidArgument, message))
+	}
+
+	if err := s.loginGuard.Succeed(ctx, user.Email); err != nil {
+		log.Error.Printf("failed to reset login attempts: %v", err)
+	}
+
+	return nil
+}
+
+// loginFailed counts the failed attempt and writes an audit log for every lockout it triggers,
+// failErr is returned as long as the attempt did not lock anything
+func (s *Server) loginFailed(ctx context.Context, email string, ip string, user *model.User, failErr error) error {
+	lockouts, err := s.loginGuard.Fail(ctx, email, ip)
+	if err != nil {
+		log.Error.Printf("failed to record login attempt: %v", err)
+		return status.Errorf(codes.Internal, "failed to record login attempt: %v", err)
+	}
+	if len(lockouts) == 0 {
+		return failErr
+	}
+
+	for _, locked := range lockouts {
+		log.Info.Printf("login lockout - %s: %s, ip: %s, attempts: %d, level: %d, duration: %s", locked.Subject, locked.Value, ip, locked.Attempts, locked.Level, locked.Duration)
+
+		fv := &model.AuditLogFieldValues{
+			UserId:    model.GiveColNullInt(nil),
+			Action:    model.GiveColString(model.AuditActionLoginLockout),
+			Subject:   model.GiveColString(locked.Subject + ":" + locked.Value),
+			Ip:        model.GiveColString(ip),
+			Detail:    model.GiveColString(fmt.Sprintf("attempts=%d level=%d duration=%s", locked.Attempts, locked.Level, locked.Duration)),
+			CreatedAt: model.GiveColTime(time.Now().UTC()),
+		}
+		if user != nil && locked.Subject == lockout.SubjectEmail {
+			fv.UserId = model.GiveColNullInt(&user.ID)
+		}
+		if _, err := model.CreateAuditLog(db.GetConn(), fv); err != nil {
+			log.Error.Printf("failed to create audit log: %v", err)
+		}
+	}
+
+	return status.Error(codes.ResourceExhausted, loginLockedMessage)
+}
+
+// issueTokens generates an access token and a refresh token for the user and persists the session
+// that links them, so that revoking the refresh token also revokes its access token.
+func issueTokens(conn model.DBExecutable, cnf *config.Config, user *model.User) (string, string, error) {
+	permissions, err := model.ListPermissionNamesByRole(conn, user.Role)
+	if err != nil {
+		return "", "", err
+	}
+
+	token, claims, err := util.GenerateToken(cnf.JwtTtl, util.GetKeySet(), util.TokenTypeAccess, user.ID, user.Role, permissions...)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, refreshClaims, err := util.GenerateToken(cnf.JwtRefreshTtl, util.GetKeySet(), util.TokenTypeRefresh, user.ID, user.Role)
+	if err != nil {
+		return "", "", err
+	}
+
+	now := time.Now().UTC()
+	fv := &model.RefreshTokenFieldValues{
+		UserId:    model.GiveColInt(user.ID),
+		Jti:       model.GiveColString(refreshClaims.ID),
+		AccessJti: model.GiveColString(claims.ID),
+		IsRevoked: model.GiveColBool(false),
+		ExpiredAt: model.GiveColTime(refreshClaims.ExpiresAt.Time.UTC()),
+		CreatedAt: model.GiveColTime(now),
+		UpdatedAt: model.GiveColTime(now),
+	}
+	if _, err := model.CreateRefreshToken(conn, fv); err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+type ReqRefreshToken struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+func (s *Server) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.Response, error) {
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqRefresh := &ReqRefreshToken{}
+	if err := bindRequest(req, reqRefresh); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Validate refresh token
+	claims, err := util.ParseToken(util.GetKeySet(), reqRefresh.RefreshToken)
+	if err != nil || claims.TokenType != util.TokenTypeRefresh {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	getRefreshToken := model.GetRefreshTokenByJti(conn, claims.ID)
+	if getRefreshToken == nil || getRefreshToken.UserId != claims.UserID {
+		return nil, status.Errorf(codes.Unauthenticated, "invalid refresh token")
+	}
+
+	// A revoked refresh token being presented again means it was leaked, so end every session of the user
+	if getRefreshToken.IsRevoked {
+		log.Warning.Printf("revoked refresh token reused by user %d", getRefreshToken.UserId)
+		if err := model.RevokeRefreshTokensByUserID(conn, getRefreshToken.UserId); err != nil {
+			log.Error.Printf("failed to revoke refresh tokens: %v", err)
+		}
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token has been revoked")
+	}
+
+	if getRefreshToken.ExpiredAt.Before(time.Now()) {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token has expired")
+	}
+
+	// Check if the user ID not found
+	getUser := model.GetUserByID(conn, getRefreshToken.UserId)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	if err := checkUserStatus(getUser); err != nil {
+		return nil, err
+	}
+
+	// Rotate refresh token
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	revoked, revokeErr := model.RevokeRefreshTokenByID(tx, getRefreshToken.ID)
+	if revokeErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke refresh token: %v", revokeErr)
+	}
+	if !revoked {
+		return nil, status.Errorf(codes.Unauthenticated, "refresh token has been revoked")
+	}
+
+	token, refreshToken, tokenErr := issueTokens(tx, cnf, getUser)
+	if tokenErr != nil {
+		log.Error.Printf("failed to generate token: %v", tokenErr)
+		return nil, status.Errorf(codes.Internal, "failed to generate token: %v", tokenErr)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to refresh token from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to refresh token from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data: &pb.Response_User{
+			User: &pb.User{
+				Id:           int32(getUser.ID),
+				Username:     getUser.Username,
+				Email:        getUser.Email,
+				CreatedAt:    util.GetFullDateStr(getUser.CreatedAt),
+				UpdatedAt:    util.GetFullDateStr(getUser.UpdatedAt),
+				Language:     getUser.Language,
+				TimeZone:     getUser.TimeZone,
+				Token:        &token,
+				RefreshToken: &refreshToken,
+			},
+		},
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+func (s *Server) Logout(ctx context.Context, req *pb.LogoutRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Revoke the session of the current access token
+	if err := model.RevokeRefreshTokenByAccessJti(conn, claims.UserID, claims.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke token: %v", err)
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
 type ReqUpdateUser struct {
-	UserId          int32   `json:"user_id" validate:"required"`
+	UserId          *int32  `json:"user_id" validate:"omitempty,min=1"`
 	Username        *string `json:"username" validate:"omitempty,min=3,max=32"`
 	Password        *string `json:"password" validate:"omitempty,min=8"`
 	IsEmailVerified *bool   `json:"is_email_verified" validate:"omitempty"`
+	CurrentPassword *string `json:"current_password" validate:"omitempty,min=8"`
+	Language        *string `json:"language" validate:"omitempty,min=2,max=16"`
+	TimeZone        *string `json:"time_zone" validate:"omitempty,max=64"`
 }
 
-func (ins ReqUpdateUser) toFieldValues() (model.UserFieldValues, bool) {
+func (ins ReqUpdateUser) toFieldValues(userId int) (model.UserFieldValues, bool) {
 	requiredCheck := false
 	fv := model.UserFieldValues{}
-	fv.ID = model.GiveColInt(int(ins.UserId))
+	fv.ID = model.GiveColInt(userId)
 
 	if ins.Username != nil {
 		requiredCheck = true
@@ -195,10 +524,26 @@ func (ins ReqUpdateUser) toFieldValues() (model.UserFieldValues, bool) {
 		fv.IsEmailVerified = model.GiveColBool(*ins.IsEmailVerified)
 	}
 
+	if ins.Language != nil {
+		requiredCheck = true
+		fv.Language = model.GiveColString(*ins.Language)
+	}
+
+	if ins.TimeZone != nil {
+		requiredCheck = true
+		fv.TimeZone = model.GiveColString(*ins.TimeZone)
+	}
+
 	return fv, requiredCheck
 }
 
 func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
 	cnf := config.Get()
 	conn := db.GetConn()
 
@@ -207,13 +552,40 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 	if err := bindRequest(req, reqUpdate); err != nil {
 		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
 	}
+	if reqUpdate.TimeZone != nil {
+		if _, err := util.LoadTimeZone(*reqUpdate.TimeZone); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err)
+		}
+	}
+
+	// Only admins may update another user or change the verification flag
+	userId := claims.UserID
+	if reqUpdate.UserId != nil && int(*reqUpdate.UserId) != claims.UserID {
+		if !claims.HasPermission(util.PermissionUsersWrite) {
+			return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+		}
+		userId = int(*reqUpdate.UserId)
+	}
+	if reqUpdate.IsEmailVerified != nil && !claims.HasPermission(util.PermissionUsersWrite) {
+		return nil, status.Errorf(codes.PermissionDenied, "permission denied")
+	}
 
 	// Check if the user ID not found
-	userId := int(reqUpdate.UserId)
-	if getUser := model.GetUserByID(conn, userId); getUser == nil {
+	getUser := model.GetUserByID(conn, userId)
+	if getUser == nil {
 		return nil, status.Errorf(codes.NotFound, "user ID not found")
 	}
 
+	// Changing your own password requires the current one
+	if reqUpdate.Password != nil && userId == claims.UserID {
+		if reqUpdate.CurrentPassword == nil {
+			return nil, status.Errorf(codes.InvalidArgument, "current password is required")
+		}
+		if err := s.verifyUserPassword(ctx, getUser, *reqUpdate.CurrentPassword, "current password is incorrect"); err != nil {
+			return nil, err
+		}
+	}
+
 	// Hash password
 	if reqUpdate.Password != nil {
 		hashPassword, hashPasswordErr := util.HashPassword(cnf.BcryptCost, *reqUpdate.Password)
@@ -224,7 +596,7 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 	}
 
 	// Update user
-	insFields, insCheck := reqUpdate.toFieldValues()
+	insFields, insCheck := reqUpdate.toFieldValues(userId)
 	if insCheck {
 		tx, txErr := conn.Begin()
 		if txErr != nil {
@@ -236,6 +608,19 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 			return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
 		}
 
+		// A new password signs out every other session, the caller stays signed in when it is their own
+		if reqUpdate.Password != nil {
+			var revokeErr error
+			if userId == claims.UserID {
+				revokeErr = model.RevokeOtherRefreshTokensByUserID(tx, userId, claims.ID)
+			} else {
+				revokeErr = model.RevokeRefreshTokensByUserID(tx, userId)
+			}
+			if revokeErr != nil {
+				return nil, status.Errorf(codes.Internal, "failed to revoke refresh tokens: %v", revokeErr)
+			}
+		}
+
 		getUser := model.GetUserByID(tx, userId)
 		if getUser == nil {
 			return nil, status.Errorf(codes.NotFound, "user ID not found")
@@ -255,6 +640,8 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 					Email:     getUser.Email,
 					CreatedAt: util.GetFullDateStr(getUser.CreatedAt),
 					UpdatedAt: util.GetFullDateStr(getUser.UpdatedAt),
+					Language:  getUser.Language,
+					TimeZone:  getUser.TimeZone,
 				},
 			},
 			Status:  http.StatusOK,
@@ -268,3 +655,125 @@ func (s *Server) UpdateUser(ctx context.Context, req *pb.UpdateUserRequest) (*pb
 		Message: "ok",
 	}, nil
 }
+
+type ReqDeactivateAccount struct {
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// DeactivateAccount closes the account of the user and ends all of its sessions,
+// the user can open it again with ReactivateAccount.
+func (s *Server) DeactivateAccount(ctx context.Context, req *pb.DeactivateAccountRequest) (*pb.Response, error) {
+	claims, err := middleware.GetClaimsFromContext(ctx)
+	if err != nil {
+		log.Error.Printf("Failed to get user ID: %v", err)
+		return nil, status.Errorf(codes.Unauthenticated, "authentication failed: %v", err)
+	}
+
+	conn := db.GetConn()
+
+	// Validate request
+	reqDeactivate := &ReqDeactivateAccount{}
+	if err := bindRequest(req, reqDeactivate); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// Check if the user ID not found
+	getUser := model.GetUserByID(conn, claims.UserID)
+	if getUser == nil {
+		return nil, status.Errorf(codes.NotFound, "user ID not found")
+	}
+
+	if err := s.verifyUserPassword(ctx, getUser, reqDeactivate.Password, "password is incorrect"); err != nil {
+		return nil, err
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	fv := &model.UserFieldValues{
+		Status:        model.GiveColBool(false),
+		DeactivatedAt: model.GiveColNullTime(&now),
+		UpdatedAt:     model.GiveColTime(now),
+	}
+	if err := model.UpdateUser(tx, getUser.ID, fv); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update user: %v", err)
+	}
+
+	if err := model.RevokeRefreshTokensByUserID(tx, getUser.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to revoke sessions: %v", err)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to deactivate account from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to deactivate account from db tx: %v", comErr)
+	}
+
+	return &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}, nil
+}
+
+type ReqReactivateAccount struct {
+	Email    string `json:"email" validate:"required,email,max=64"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// ReactivateAccount opens an account the user deactivated and signs the user in the way Login does,
+// an account disabled by an admin can only be enabled by an admin.
+func (s *Server) ReactivateAccount(ctx context.Context, req *pb.ReactivateAccountRequest) (*pb.Response, error) {
+	cnf := config.Get()
+	conn := db.GetConn()
+
+	// Validate request
+	reqReactivate := &ReqReactivateAccount{}
+	if err := bindRequest(req, reqReactivate); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	// The credentials are guessed the same way as at Login, so they share the failed attempts
+	ip := middleware.GetClientIPFromContext(ctx)
+	lockedFor, lockErr := s.loginGuard.Check(ctx, reqReactivate.Email, ip)
+	if lockErr != nil {
+		log.Error.Printf("failed to check login attempts: %v", lockErr)
+		return nil, status.Errorf(codes.Internal, "failed to check login attempts: %v", lockErr)
+	}
+	if lockedFor > 0 {
+		return nil, status.Error(codes.ResourceExhausted, loginLockedMessage)
+	}
+
+	getUser := model.GetUserByEmail(conn, reqReactivate.Email)
+	if !checkUserPassword(getUser, reqReactivate.Password) {
+		return nil, s.loginFailed(ctx, reqReactivate.Email, ip, getUser, status.Error(codes.Unauthenticated, loginFailedMessage))
+	}
+
+	if getUser.Status {
+		return nil, status.Errorf(codes.FailedPrecondition, "this account has not been deactivated")
+	}
+
+	reactivated, reactivateErr := model.ReactivateUser(conn, getUser.ID)
+	if reactivateErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to reactivate account: %v", reactivateErr)
+	}
+	if !reactivated {
+		return nil, status.Error(codes.PermissionDenied, accountDisabledMessage)
+	}
+
+	// With 2FA the attempts are kept until LoginTOTP succeeds
+	if !getUser.IsTotpEnabled {
+		if err := s.loginGuard.Succeed(ctx, reqReactivate.Email); err != nil {
+			log.Error.Printf("failed to reset login attempts: %v", err)
+		}
+	}
+
+	getUser.Status = true
+	getUser.DeactivatedAt = nil
+
+	return signIn(conn, cnf, getUser)
+}
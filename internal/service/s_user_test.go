@@ -3,36 +3,44 @@ package service_test
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"go-todolist-grpc/api/pb"
 	"go-todolist-grpc/internal/config"
+	"go-todolist-grpc/internal/middleware"
+	"go-todolist-grpc/internal/model"
 	"go-todolist-grpc/internal/pkg/db"
+	"go-todolist-grpc/internal/pkg/lockout"
 	"go-todolist-grpc/internal/pkg/log"
 	"go-todolist-grpc/internal/pkg/util"
 	"go-todolist-grpc/internal/service"
-	"go-todolist-grpc/internal/service/queue"
+	"math"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
-	"github.com/hibiken/asynq"
 	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 )
 
-type mockTaskDistributorByUser struct{}
-
-func (m *mockTaskDistributorByUser) DistributeTaskSendVerifyEmail(ctx context.Context, payload *queue.PayloadSendVerifyEmail, opts ...asynq.Option) error {
-	return nil
-}
-
 func setUpUser() (*service.Server, error) {
 	var mockConfigContent bytes.Buffer
 	mockConfigContent.WriteString("HTTP_SERVER_PORT=" + config.HttpPort + "\n")
 	mockConfigContent.WriteString("GRPC_SERVER_PORT=" + config.GrpcPort + "\n")
+	mockConfigContent.WriteString("PUBLIC_BASE_URL=" + config.PublicBaseUrl + "\n")
+	mockConfigContent.WriteString("LINK_SIGNING_KEY=" + config.LinkSigningKey + "\n")
+	mockConfigContent.WriteString("SECRET_CODE_HASH_KEY=" + config.SecretCodeHashKey + "\n")
+	mockConfigContent.WriteString("TOTP_ENCRYPTION_KEY=" + config.TotpEncryptionKey + "\n")
+	mockConfigContent.WriteString("TOTP_ISSUER=" + config.TotpIssuer + "\n")
+	mockConfigContent.WriteString("TOTP_CHALLENGE_TTL=" + strconv.Itoa(config.TotpChallengeTtl) + "\n")
 	mockConfigContent.WriteString("DB_HOST=" + config.SourceHost + "\n")
 	mockConfigContent.WriteString("DB_PORT=" + config.SourcePort + "\n")
 	mockConfigContent.WriteString("DB_USER=" + config.SourceUser + "\n")
@@ -45,6 +53,7 @@ func setUpUser() (*service.Server, error) {
 	mockConfigContent.WriteString("BCRYPT_COST=" + strconv.Itoa(config.BcryptCost) + "\n")
 	mockConfigContent.WriteString("JWT_SECRET_KEY=" + config.JwtSecretKey + "\n")
 	mockConfigContent.WriteString("JWT_TTL=" + strconv.Itoa(config.JwtTtl) + "\n")
+	mockConfigContent.WriteString("JWT_REFRESH_TTL=" + strconv.Itoa(config.JwtRefreshTtl) + "\n")
 	mockConfigContent.WriteString("LOG_LEVEL=" + strconv.Itoa(config.LogLevel) + "\n")
 	mockConfigContent.WriteString("LOG_FOLDER_PATH=" + config.LogFolderPath + "\n")
 	mockConfigContent.WriteString("ENABLE_CONSOLE_OUTPUT=" + strconv.FormatBool(config.EnableConsoleOutput) + "\n")
@@ -68,6 +77,11 @@ func setUpUser() (*service.Server, error) {
 	// Init log
 	log.Init(config.LogLevel, config.LogFolderPath, strconv.Itoa(os.Getpid()), config.EnableConsoleOutput, config.EnableFileOutput)
 
+	// Init JWT keys
+	if err := util.InitKeySet(&util.KeySetOption{SecretKey: config.JwtSecretKey}); err != nil {
+		return nil, err
+	}
+
 	// Init sql
 	opt := &db.Option{
 		Host:     config.SourceHost,
@@ -83,8 +97,8 @@ func setUpUser() (*service.Server, error) {
 		return nil, err
 	}
 
-	mockDistributor := &mockTaskDistributorByUser{}
-	s := service.NewServer(mockDistributor)
+	mockDistributor := &mockTaskDistributor{}
+	s := service.NewServer(mockDistributor, lockout.NewGuard(lockout.NewMemoryStore(), lockout.DefaultPolicy()))
 
 	return s, nil
 }
@@ -107,6 +121,26 @@ func TestRegisterUser(t *testing.T) {
 		assert.Equal(t, int32(http.StatusOK), res.Status)
 		assert.Equal(t, "ok", res.Message)
 		assert.NotEmpty(t, res.GetUser().Id)
+		assert.Equal(t, "en", res.GetUser().Language)
+	})
+
+	t.Run("Success_Language", func(t *testing.T) {
+		language := "zh-TW"
+		req := &pb.RegisterUserRequest{
+			Email:    util.RandomEmail(),
+			Username: util.RandomString(6),
+			Password: util.RandomString(8),
+			Language: &language,
+		}
+
+		res, err := s.RegisterUser(context.Background(), req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, "zh-TW", res.GetUser().Language)
+
+		getUser := model.GetUserByID(db.GetConn(), int(res.GetUser().Id))
+		assert.NotNil(t, getUser)
+		assert.Equal(t, "zh-TW", getUser.Language)
 	})
 
 	t.Run("Failure_ExistingEmail", func(t *testing.T) {
@@ -126,6 +160,18 @@ func TestRegisterUser(t *testing.T) {
 		assert.Equal(t, "the email already exists", st.Message())
 	})
 
+	t.Run("Failure_ExistingEmailInOtherCase", func(t *testing.T) {
+		req := &pb.RegisterUserRequest{
+			Email:    strings.ToUpper(email),
+			Username: util.RandomString(6),
+			Password: util.RandomString(8),
+		}
+
+		res, err := s.RegisterUser(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = AlreadyExists desc = the email already exists")
+		assert.Nil(t, res)
+	})
+
 	t.Run("Failure_InvalidEmail", func(t *testing.T) {
 		req := &pb.RegisterUserRequest{
 			Email:    "invalid-email",
@@ -195,10 +241,10 @@ func TestLogin(t *testing.T) {
 	assert.Nil(t, rErr)
 
 	uReq := &pb.UpdateUserRequest{
-		UserId:          rRes.GetUser().Id,
+		UserId:          &rRes.GetUser().Id,
 		IsEmailVerified: &isEmailVerified,
 	}
-	_, uErr := s.UpdateUser(context.Background(), uReq)
+	_, uErr := s.UpdateUser(createAdminContext(0), uReq)
 	assert.Nil(t, uErr)
 
 	t.Run("Success", func(t *testing.T) {
@@ -214,6 +260,17 @@ func TestLogin(t *testing.T) {
 		assert.Equal(t, int32(http.StatusOK), res.Status)
 		assert.Equal(t, "ok", res.Message)
 		assert.NotEmpty(t, res.GetUser().Token)
+		assert.NotEmpty(t, res.GetUser().RefreshToken)
+	})
+
+	t.Run("Success_EmailInOtherCase", func(t *testing.T) {
+		res, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    strings.ToUpper(email),
+			Password: password,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, rRes.GetUser().Id, res.GetUser().Id)
 	})
 
 	t.Run("Failure_IncorrectPassword", func(t *testing.T) {
@@ -223,13 +280,13 @@ func TestLogin(t *testing.T) {
 		}
 
 		res, err := s.Login(context.Background(), req)
-		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = password is incorrect")
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
 		assert.Nil(t, res)
 
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
-		assert.Equal(t, codes.InvalidArgument, st.Code())
-		assert.Equal(t, "password is incorrect", st.Message())
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Equal(t, "email or password is incorrect", st.Message())
 	})
 
 	t.Run("Failure_UnregisteredEmail", func(t *testing.T) {
@@ -239,13 +296,373 @@ func TestLogin(t *testing.T) {
 		}
 
 		res, err := s.Login(context.Background(), req)
-		assert.EqualError(t, err, "rpc error: code = NotFound desc = this email is unregistered")
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
 		assert.Nil(t, res)
 
 		st, ok := status.FromError(err)
 		assert.True(t, ok)
-		assert.Equal(t, codes.NotFound, st.Code())
-		assert.Equal(t, "this email is unregistered", st.Message())
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Equal(t, "email or password is incorrect", st.Message())
+	})
+
+	t.Run("Failure_EmailLockout", func(t *testing.T) {
+		s, err := setUpUser()
+		assert.NoError(t, err)
+
+		req := &pb.LoginRequest{
+			Email:    email,
+			Password: "invalid-password",
+		}
+		for i := 1; i < lockout.DefaultPolicy().MaxEmailAttempts; i++ {
+			_, err := s.Login(context.Background(), req)
+			assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
+		}
+
+		res, err := s.Login(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+
+		auditLog := model.GetLatestAuditLog(db.GetConn(), model.AuditActionLoginLockout, lockout.SubjectEmail+":"+email)
+		assert.NotNil(t, auditLog)
+		assert.Equal(t, rRes.GetUser().Id, int32(*auditLog.UserId))
+		assert.Equal(t, "attempts=5 level=1 duration=1m0s", auditLog.Detail)
+
+		// The correct password is refused as well while the email is locked
+		res, err = s.Login(context.Background(), &pb.LoginRequest{
+			Email:    email,
+			Password: password,
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_IPLockout", func(t *testing.T) {
+		s, err := setUpUser()
+		assert.NoError(t, err)
+
+		ip := fmt.Sprintf("203.0.113.%d", util.RandomInt(1, 254))
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", ip+", 10.0.0.1"))
+		for i := 1; i < lockout.DefaultPolicy().MaxIPAttempts; i++ {
+			_, err := s.Login(ctx, &pb.LoginRequest{
+				Email:    util.RandomEmail(),
+				Password: util.RandomString(8),
+			})
+			assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
+		}
+
+		res, err := s.Login(ctx, &pb.LoginRequest{
+			Email:    util.RandomEmail(),
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = ResourceExhausted desc = too many failed login attempts, please try again later")
+		assert.Nil(t, res)
+
+		auditLog := model.GetLatestAuditLog(db.GetConn(), model.AuditActionLoginLockout, lockout.SubjectIP+":"+ip)
+		assert.NotNil(t, auditLog)
+		assert.Nil(t, auditLog.UserId)
+		assert.Equal(t, ip, auditLog.Ip)
+
+		// Another address is not affected
+		res, err = s.Login(metadata.NewIncomingContext(context.Background(), metadata.Pairs("x-forwarded-for", "198.51.100.1")), &pb.LoginRequest{
+			Email:    email,
+			Password: password,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+	})
+
+	t.Run("Success_ResetsFailedAttempts", func(t *testing.T) {
+		s, err := setUpUser()
+		assert.NoError(t, err)
+
+		wrongReq := &pb.LoginRequest{
+			Email:    email,
+			Password: "invalid-password",
+		}
+		for i := 1; i < lockout.DefaultPolicy().MaxEmailAttempts; i++ {
+			_, err := s.Login(context.Background(), wrongReq)
+			assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
+		}
+
+		res, err := s.Login(context.Background(), &pb.LoginRequest{
+			Email:    email,
+			Password: password,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+
+		// The counter starts over after the successful login
+		_, err = s.Login(context.Background(), wrongReq)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
+	})
+}
+
+// loginTestUser registers a verified user and logs in with it
+func loginTestUser(t *testing.T, s *service.Server) *pb.User {
+	email := util.RandomEmail()
+	password := util.RandomString(8)
+	isEmailVerified := true
+	rRes, rErr := s.RegisterUser(context.Background(), &pb.RegisterUserRequest{
+		Email:    email,
+		Username: util.RandomString(6),
+		Password: password,
+	})
+	assert.Nil(t, rErr)
+
+	_, uErr := s.UpdateUser(createAdminContext(0), &pb.UpdateUserRequest{
+		UserId:          &rRes.GetUser().Id,
+		IsEmailVerified: &isEmailVerified,
+	})
+	assert.Nil(t, uErr)
+
+	lRes, lErr := s.Login(context.Background(), &pb.LoginRequest{
+		Email:    email,
+		Password: password,
+	})
+	assert.Nil(t, lErr)
+
+	return lRes.GetUser()
+}
+
+// createTokenContext builds the context the middleware would hand over for the access token
+func createTokenContext(t *testing.T, token string) (context.Context, *util.CustomClaims) {
+	claims, err := util.ParseToken(util.GetKeySet(), token)
+	assert.NoError(t, err)
+
+	claimsJSON, _ := json.Marshal(claims)
+	md := metadata.New(map[string]string{
+		"x-auth-claims": string(claimsJSON),
+	})
+	return metadata.NewIncomingContext(context.Background(), md), claims
+}
+
+func TestRefreshToken(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		res, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, user.Id, res.GetUser().Id)
+		assert.NotEmpty(t, res.GetUser().Token)
+		assert.NotEqual(t, user.GetRefreshToken(), res.GetUser().GetRefreshToken())
+
+		// The access token of the rotated session is revoked
+		_, claims := createTokenContext(t, user.GetToken())
+		isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), claims.ID)
+		assert.NoError(t, revokedErr)
+		assert.True(t, isRevoked)
+	})
+
+	t.Run("Failure_ReusedToken", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		rRes, rErr := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.Nil(t, rErr)
+
+		res, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = refresh token has been revoked")
+		assert.Nil(t, res)
+
+		// Reuse revokes every session of the user, including the rotated one
+		res, err = s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: rRes.GetUser().GetRefreshToken()})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = refresh token has been revoked")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Equal(t, "refresh token has been revoked", st.Message())
+	})
+
+	t.Run("Failure_AccessToken", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		res, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetToken()})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = invalid refresh token")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Equal(t, "invalid refresh token", st.Message())
+	})
+
+	t.Run("Failure_EmptyToken", func(t *testing.T) {
+		res, err := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{})
+		assert.Error(t, err)
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	})
+}
+
+func TestLogout(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		ctx, claims := createTokenContext(t, user.GetToken())
+
+		res, err := s.Logout(ctx, &pb.LogoutRequest{})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), claims.ID)
+		assert.NoError(t, revokedErr)
+		assert.True(t, isRevoked)
+
+		// The refresh token of the session can no longer be used
+		rRes, rErr := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.EqualError(t, rErr, "rpc error: code = Unauthenticated desc = refresh token has been revoked")
+		assert.Nil(t, rRes)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		res, err := s.Logout(context.Background(), &pb.LogoutRequest{})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+	})
+}
+
+func TestDeactivateAccount(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user, password := registerTestUser(t, s, util.RandomEmail(), true)
+		lRes, lErr := s.Login(context.Background(), &pb.LoginRequest{Email: user.Email, Password: password})
+		assert.Nil(t, lErr)
+		ctx, claims := createTokenContext(t, lRes.GetUser().GetToken())
+
+		res, err := s.DeactivateAccount(ctx, &pb.DeactivateAccountRequest{Password: password})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+
+		// Every session ends and signing in is refused until the account is reactivated
+		isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), claims.ID)
+		assert.NoError(t, revokedErr)
+		assert.True(t, isRevoked)
+		assert.False(t, model.IsUserActive(db.GetConn(), int(user.Id)))
+
+		res, err = s.Login(context.Background(), &pb.LoginRequest{Email: user.Email, Password: password})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = this account has been deactivated, reactivate it to sign in")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_IncorrectPassword", func(t *testing.T) {
+		user, _ := registerTestUser(t, s, util.RandomEmail(), true)
+
+		res, err := s.DeactivateAccount(createAuthenticatedContext(int(user.Id)), &pb.DeactivateAccountRequest{Password: "invalid-password"})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = password is incorrect")
+		assert.Nil(t, res)
+		assert.True(t, model.IsUserActive(db.GetConn(), int(user.Id)))
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		res, err := s.DeactivateAccount(context.Background(), &pb.DeactivateAccountRequest{Password: util.RandomString(8)})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_TokenOfDisabledAccount", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		// Disable the account without ending its sessions, the middleware still refuses the token
+		tx, err := db.GetConn().Begin()
+		assert.NoError(t, err)
+		assert.NoError(t, model.UpdateUser(tx, int(user.Id), &model.UserFieldValues{Status: model.GiveColBool(false)}))
+		assert.NoError(t, tx.Commit())
+
+		interceptor := middleware.VerifyTokenByGrpc(config.Get())
+		ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "Bearer "+user.GetToken()))
+		res, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/pb.ToDoList/ListTask"}, func(ctx context.Context, req interface{}) (interface{}, error) {
+			return "ok", nil
+		})
+		assert.EqualError(t, err, "account has been disabled")
+		assert.Nil(t, res)
+
+		rRes, rErr := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.EqualError(t, rErr, "rpc error: code = PermissionDenied desc = this account has been disabled")
+		assert.Nil(t, rRes)
+	})
+}
+
+func TestReactivateAccount(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	deactivate := func(t *testing.T, user *pb.User, password string) {
+		_, err := s.DeactivateAccount(createAuthenticatedContext(int(user.Id)), &pb.DeactivateAccountRequest{Password: password})
+		assert.Nil(t, err)
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user, password := registerTestUser(t, s, util.RandomEmail(), true)
+		deactivate(t, user, password)
+
+		res, err := s.ReactivateAccount(context.Background(), &pb.ReactivateAccountRequest{Email: user.Email, Password: password})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, user.Id, res.GetUser().Id)
+		assert.NotEmpty(t, res.GetUser().GetToken())
+		assert.True(t, model.IsUserActive(db.GetConn(), int(user.Id)))
+
+		lRes, lErr := s.Login(context.Background(), &pb.LoginRequest{Email: user.Email, Password: password})
+		assert.Nil(t, lErr)
+		assert.NotEmpty(t, lRes.GetUser().GetToken())
+	})
+
+	t.Run("Failure_IncorrectPassword", func(t *testing.T) {
+		user, password := registerTestUser(t, s, util.RandomEmail(), true)
+		deactivate(t, user, password)
+
+		res, err := s.ReactivateAccount(context.Background(), &pb.ReactivateAccountRequest{Email: user.Email, Password: "invalid-password"})
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = email or password is incorrect")
+		assert.Nil(t, res)
+		assert.False(t, model.IsUserActive(db.GetConn(), int(user.Id)))
+	})
+
+	t.Run("Failure_NotDeactivated", func(t *testing.T) {
+		user, password := registerTestUser(t, s, util.RandomEmail(), true)
+
+		res, err := s.ReactivateAccount(context.Background(), &pb.ReactivateAccountRequest{Email: user.Email, Password: password})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = this account has not been deactivated")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_DisabledByAdmin", func(t *testing.T) {
+		user, password := registerTestUser(t, s, util.RandomEmail(), true)
+		deactivate(t, user, password)
+
+		// An admin disabling the account takes away the right to reactivate it
+		_, dErr := s.DisableUser(createAdminContext(0), &pb.DisableUserRequest{UserId: user.Id})
+		assert.Nil(t, dErr)
+
+		res, err := s.ReactivateAccount(context.Background(), &pb.ReactivateAccountRequest{Email: user.Email, Password: password})
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = this account has been disabled")
+		assert.Nil(t, res)
+
+		res, err = s.Login(context.Background(), &pb.LoginRequest{Email: user.Email, Password: password})
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = this account has been disabled")
+		assert.Nil(t, res)
 	})
 }
 
@@ -254,43 +671,197 @@ func TestUpdateUser(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Insert a test user
+	password := util.RandomString(8)
 	rReq := &pb.RegisterUserRequest{
 		Email:    util.RandomEmail(),
 		Username: util.RandomString(6),
-		Password: util.RandomString(8),
+		Password: password,
 	}
 
 	rRes, err := s.RegisterUser(context.Background(), rReq)
 	assert.Nil(t, err)
+	ctx := createAuthenticatedContext(int(rRes.GetUser().Id))
 
 	t.Run("Success", func(t *testing.T) {
 		newUsername := util.RandomString(6)
 		newPassword := util.RandomString(8)
 		req := &pb.UpdateUserRequest{
-			UserId:   rRes.GetUser().Id,
-			Username: &newUsername,
-			Password: &newPassword,
+			Username:        &newUsername,
+			Password:        &newPassword,
+			CurrentPassword: &password,
 		}
 
-		res, err := s.UpdateUser(context.Background(), req)
+		res, err := s.UpdateUser(ctx, req)
 		assert.Nil(t, err)
 		assert.NotNil(t, res)
 		assert.Equal(t, int32(http.StatusOK), res.Status)
 		assert.Equal(t, "ok", res.Message)
+		assert.Equal(t, rRes.GetUser().Id, res.GetUser().Id)
 		assert.Equal(t, newUsername, res.GetUser().Username)
 		assert.NotEmpty(t, res.GetUser().Email)
+		password = newPassword
 	})
 
-	t.Run("Failure_InvalidUserID", func(t *testing.T) {
-		newUsername := util.RandomString(6)
+	t.Run("Success_PasswordRevokesOtherSessions", func(t *testing.T) {
+		email := util.RandomEmail()
+		oldPassword := util.RandomString(8)
+		isEmailVerified := true
+		oRes, err := s.RegisterUser(context.Background(), &pb.RegisterUserRequest{
+			Email:    email,
+			Username: util.RandomString(6),
+			Password: oldPassword,
+		})
+		assert.Nil(t, err)
+		_, err = s.UpdateUser(createAdminContext(0), &pb.UpdateUserRequest{
+			UserId:          &oRes.GetUser().Id,
+			IsEmailVerified: &isEmailVerified,
+		})
+		assert.Nil(t, err)
+
+		current, err := s.Login(context.Background(), &pb.LoginRequest{Email: email, Password: oldPassword})
+		assert.Nil(t, err)
+		other, err := s.Login(context.Background(), &pb.LoginRequest{Email: email, Password: oldPassword})
+		assert.Nil(t, err)
+
+		currentCtx, currentClaims := createTokenContext(t, current.GetUser().GetToken())
 		newPassword := util.RandomString(8)
+		res, err := s.UpdateUser(currentCtx, &pb.UpdateUserRequest{
+			Password:        &newPassword,
+			CurrentPassword: &oldPassword,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+
+		// The caller stays signed in, every other session is signed out
+		_, otherClaims := createTokenContext(t, other.GetUser().GetToken())
+		isRevoked, revokedErr := model.IsAccessTokenRevoked(db.GetConn(), currentClaims.ID)
+		assert.NoError(t, revokedErr)
+		assert.False(t, isRevoked)
+		isRevoked, revokedErr = model.IsAccessTokenRevoked(db.GetConn(), otherClaims.ID)
+		assert.NoError(t, revokedErr)
+		assert.True(t, isRevoked)
+
+		res, err = s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: other.GetUser().GetRefreshToken()})
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("Success_AdminOverride", func(t *testing.T) {
+		newUsername := util.RandomString(6)
+		isEmailVerified := true
+		req := &pb.UpdateUserRequest{
+			UserId:          &rRes.GetUser().Id,
+			Username:        &newUsername,
+			IsEmailVerified: &isEmailVerified,
+		}
+
+		res, err := s.UpdateUser(createAdminContext(0), req)
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, rRes.GetUser().Id, res.GetUser().Id)
+		assert.Equal(t, newUsername, res.GetUser().Username)
+	})
+
+	t.Run("Failure_Unauthenticated", func(t *testing.T) {
+		newUsername := util.RandomString(6)
 		req := &pb.UpdateUserRequest{
-			UserId:   999999,
 			Username: &newUsername,
-			Password: &newPassword,
 		}
 
 		res, err := s.UpdateUser(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = Unauthenticated desc = authentication failed: no metadata found in context")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.Unauthenticated, st.Code())
+		assert.Contains(t, st.Message(), "authentication failed")
+	})
+
+	t.Run("Failure_OtherUser", func(t *testing.T) {
+		oReq := &pb.RegisterUserRequest{
+			Email:    util.RandomEmail(),
+			Username: util.RandomString(6),
+			Password: util.RandomString(8),
+		}
+		oRes, err := s.RegisterUser(context.Background(), oReq)
+		assert.Nil(t, err)
+
+		newUsername := util.RandomString(6)
+		req := &pb.UpdateUserRequest{
+			UserId:   &oRes.GetUser().Id,
+			Username: &newUsername,
+		}
+
+		res, err := s.UpdateUser(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Equal(t, "permission denied", st.Message())
+	})
+
+	t.Run("Failure_SelfVerifyEmail", func(t *testing.T) {
+		isEmailVerified := true
+		req := &pb.UpdateUserRequest{
+			IsEmailVerified: &isEmailVerified,
+		}
+
+		res, err := s.UpdateUser(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = PermissionDenied desc = permission denied")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		assert.Equal(t, "permission denied", st.Message())
+	})
+
+	t.Run("Failure_MissingCurrentPassword", func(t *testing.T) {
+		newPassword := util.RandomString(8)
+		req := &pb.UpdateUserRequest{
+			Password: &newPassword,
+		}
+
+		res, err := s.UpdateUser(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = current password is required")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "current password is required", st.Message())
+	})
+
+	t.Run("Failure_IncorrectCurrentPassword", func(t *testing.T) {
+		newPassword := util.RandomString(8)
+		wrongPassword := "invalid-password"
+		req := &pb.UpdateUserRequest{
+			Password:        &newPassword,
+			CurrentPassword: &wrongPassword,
+		}
+
+		res, err := s.UpdateUser(ctx, req)
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = current password is incorrect")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "current password is incorrect", st.Message())
+	})
+
+	t.Run("Failure_InvalidUserID", func(t *testing.T) {
+		newUsername := util.RandomString(6)
+		invalidUserId := int32(999999)
+		req := &pb.UpdateUserRequest{
+			UserId:   &invalidUserId,
+			Username: &newUsername,
+		}
+
+		res, err := s.UpdateUser(createAdminContext(0), req)
 		assert.EqualError(t, err, "rpc error: code = NotFound desc = user ID not found")
 		assert.Nil(t, res)
 
@@ -302,14 +873,11 @@ func TestUpdateUser(t *testing.T) {
 
 	t.Run("Failure_EmptyUsername", func(t *testing.T) {
 		newUsername := ""
-		newPassword := util.RandomString(8)
 		req := &pb.UpdateUserRequest{
-			UserId:   rRes.GetUser().Id,
 			Username: &newUsername,
-			Password: &newPassword,
 		}
 
-		res, err := s.UpdateUser(context.Background(), req)
+		res, err := s.UpdateUser(ctx, req)
 		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = failed to validate: Key: 'ReqUpdateUser.Username' Error:Field validation for 'Username' failed on the 'min' tag")
 		assert.Nil(t, res)
 
@@ -319,3 +887,417 @@ func TestUpdateUser(t *testing.T) {
 		assert.Contains(t, st.Message(), "failed to validate")
 	})
 }
+
+func TestRequestPasswordReset(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		res, err := s.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: user.Email})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+	})
+
+	t.Run("Success_Cooldown", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+
+		// A second request within the cooldown sends nothing but still answers ok
+		res, err := s.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: user.Email})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+	})
+
+	t.Run("Success_ExpiresEarlierCodes", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, _ := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+
+		err := model.ExpirePasswordResetsByUserID(db.GetConn(), int(user.Id))
+		assert.NoError(t, err)
+
+		now := time.Now().UTC()
+		assert.Nil(t, model.GetPasswordResetByID(db.GetConn(), passwordReset.ID.Val, false, &now))
+	})
+
+	t.Run("Success_UnregisteredEmail", func(t *testing.T) {
+		res, err := s.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: util.RandomEmail()})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+	})
+
+	t.Run("Failure_InvalidEmail", func(t *testing.T) {
+		res, err := s.RequestPasswordReset(context.Background(), &pb.RequestPasswordResetRequest{Email: "invalid-email"})
+		assert.Error(t, err)
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	})
+}
+
+// createPasswordReset inserts the code the reset password mail would carry and returns it with the record
+func createPasswordReset(t *testing.T, user *pb.User, expiredAt time.Time) (*model.PasswordResetFieldValues, string) {
+	now := time.Now().UTC()
+	secretCode := util.RandomString(32)
+	passwordReset, err := model.CreatePasswordReset(db.GetConn(), &model.PasswordResetFieldValues{
+		UserId:         model.GiveColInt(int(user.Id)),
+		Email:          model.GiveColString(user.Email),
+		SecretCodeHash: model.GiveColString(util.HashSecretCode(config.SecretCodeHashKey, secretCode)),
+		IsUsed:         model.GiveColBool(false),
+		ExpiredAt:      model.GiveColTime(expiredAt),
+		CreatedAt:      model.GiveColTime(now),
+		UpdatedAt:      model.GiveColTime(now),
+	})
+	assert.NoError(t, err)
+
+	return passwordReset, secretCode
+}
+
+// signLinkToken builds the token query parameter of the link that would have been mailed
+func signLinkToken(t *testing.T, purpose string, id int, secretCode string, expiredAt time.Time) string {
+	token, err := util.SignLink(config.LinkSigningKey, &util.LinkClaims{
+		Purpose:    purpose,
+		ID:         id,
+		SecretCode: secretCode,
+		ExpiredAt:  expiredAt.Unix(),
+	})
+	assert.NoError(t, err)
+
+	return token
+}
+
+func TestResetPassword(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	t.Run("Success", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+		password := util.RandomString(8)
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, secretCode, passwordReset.ExpiredAt.Val),
+			Password: password,
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+
+		// The new password works and the old sessions are signed out
+		lRes, lErr := s.Login(context.Background(), &pb.LoginRequest{Email: user.Email, Password: password})
+		assert.Nil(t, lErr)
+		assert.NotEmpty(t, lRes.GetUser().Token)
+
+		rRes, rErr := s.RefreshToken(context.Background(), &pb.RefreshTokenRequest{RefreshToken: user.GetRefreshToken()})
+		assert.Error(t, rErr)
+		assert.Nil(t, rRes)
+	})
+
+	t.Run("Failure_UsedCode", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+		req := &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, secretCode, passwordReset.ExpiredAt.Val),
+			Password: util.RandomString(8),
+		}
+
+		_, err := s.ResetPassword(context.Background(), req)
+		assert.Nil(t, err)
+
+		res, err := s.ResetPassword(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = password reset ID not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_ExpiredCode", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(-time.Minute))
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, secretCode, time.Now().UTC().Add(time.Minute)),
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = password reset ID not found")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.NotFound, st.Code())
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, _ := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, util.RandomString(32), passwordReset.ExpiredAt.Val),
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = secret code is incorrect")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+		assert.Equal(t, "secret code is incorrect", st.Message())
+	})
+
+	t.Run("Failure_TamperedLink", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+		token := signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, secretCode, passwordReset.ExpiredAt.Val)
+
+		// Pointing the signed token at another ID breaks the signature
+		payload, signature, _ := strings.Cut(token, ".")
+		forged := signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val+1, secretCode, passwordReset.ExpiredAt.Val)
+		forgedPayload, _, _ := strings.Cut(forged, ".")
+		assert.NotEqual(t, payload, forgedPayload)
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    forgedPayload + "." + signature,
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the link is invalid")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_ExpiredLink", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeResetPassword, passwordReset.ID.Val, secretCode, time.Now().UTC().Add(-time.Minute)),
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the link has expired")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_WrongPurpose", func(t *testing.T) {
+		user := loginTestUser(t, s)
+		passwordReset, secretCode := createPasswordReset(t, user, time.Now().UTC().Add(15*time.Minute))
+
+		res, err := s.ResetPassword(context.Background(), &pb.ResetPasswordRequest{
+			Token:    signLinkToken(t, util.LinkPurposeVerifyEmail, passwordReset.ID.Val, secretCode, passwordReset.ExpiredAt.Val),
+			Password: util.RandomString(8),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the link is invalid")
+		assert.Nil(t, res)
+	})
+}
+
+func TestVerifyEmail(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	createVerifyEmail := func(t *testing.T, expiredAt time.Time) (*model.VerifyEmailFieldValues, string) {
+		rRes, rErr := s.RegisterUser(context.Background(), &pb.RegisterUserRequest{
+			Email:    util.RandomEmail(),
+			Username: util.RandomString(6),
+			Password: util.RandomString(8),
+		})
+		assert.Nil(t, rErr)
+
+		now := time.Now().UTC()
+		secretCode := util.RandomString(32)
+		verifyEmail, err := model.CreateVerifyEmail(db.GetConn(), &model.VerifyEmailFieldValues{
+			UserId:         model.GiveColInt(int(rRes.GetUser().Id)),
+			Username:       model.GiveColString(rRes.GetUser().Username),
+			Email:          model.GiveColString(rRes.GetUser().Email),
+			SecretCodeHash: model.GiveColString(util.HashSecretCode(config.SecretCodeHashKey, secretCode)),
+			IsUsed:         model.GiveColBool(false),
+			ExpiredAt:      model.GiveColTime(expiredAt),
+			CreatedAt:      model.GiveColTime(now),
+			UpdatedAt:      model.GiveColTime(now),
+		})
+		assert.NoError(t, err)
+
+		return verifyEmail, secretCode
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		verifyEmail, secretCode := createVerifyEmail(t, time.Now().UTC().Add(time.Hour))
+
+		res, err := s.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, secretCode, verifyEmail.ExpiredAt.Val),
+		})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.True(t, res.GetVerifyEmail().IsUsed)
+
+		getUser := model.GetUserByID(db.GetConn(), verifyEmail.UserId.Val)
+		assert.NotNil(t, getUser)
+		assert.True(t, getUser.IsEmailVerified)
+	})
+
+	t.Run("Failure_IncorrectCode", func(t *testing.T) {
+		verifyEmail, _ := createVerifyEmail(t, time.Now().UTC().Add(time.Hour))
+
+		res, err := s.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, util.RandomString(32), verifyEmail.ExpiredAt.Val),
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = secret code is incorrect")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_UsedCode", func(t *testing.T) {
+		verifyEmail, secretCode := createVerifyEmail(t, time.Now().UTC().Add(time.Hour))
+		req := &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, secretCode, verifyEmail.ExpiredAt.Val),
+		}
+
+		_, err := s.VerifyEmail(context.Background(), req)
+		assert.Nil(t, err)
+
+		res, err := s.VerifyEmail(context.Background(), req)
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = this verification link has already been used")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.FailedPrecondition, st.Code())
+	})
+
+	t.Run("Failure_ExpiredCode", func(t *testing.T) {
+		verifyEmail, secretCode := createVerifyEmail(t, time.Now().UTC().Add(-time.Minute))
+
+		// The link itself is still valid, only the stored code has expired
+		res, err := s.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, secretCode, time.Now().UTC().Add(time.Hour)),
+		})
+		assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = this verification link has expired")
+		assert.Nil(t, res)
+
+		getUser := model.GetUserByID(db.GetConn(), verifyEmail.UserId.Val)
+		assert.NotNil(t, getUser)
+		assert.False(t, getUser.IsEmailVerified)
+	})
+
+	t.Run("Failure_NotFound", func(t *testing.T) {
+		res, err := s.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, math.MaxInt32, util.RandomString(32), time.Now().UTC().Add(time.Hour)),
+		})
+		assert.EqualError(t, err, "rpc error: code = NotFound desc = verify email ID not found")
+		assert.Nil(t, res)
+	})
+
+	t.Run("Failure_ConcurrentRedemption", func(t *testing.T) {
+		verifyEmail, secretCode := createVerifyEmail(t, time.Now().UTC().Add(time.Hour))
+		req := &pb.VerifyEmailRequest{
+			Token: signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, secretCode, verifyEmail.ExpiredAt.Val),
+		}
+
+		const attempts = 5
+		errs := make(chan error, attempts)
+		wg := sync.WaitGroup{}
+		for i := 0; i < attempts; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := s.VerifyEmail(context.Background(), req)
+				errs <- err
+			}()
+		}
+		wg.Wait()
+		close(errs)
+
+		succeeded := 0
+		for err := range errs {
+			if err == nil {
+				succeeded++
+				continue
+			}
+			assert.EqualError(t, err, "rpc error: code = FailedPrecondition desc = this verification link has already been used")
+		}
+		assert.Equal(t, 1, succeeded)
+	})
+
+	t.Run("Failure_InvalidLink", func(t *testing.T) {
+		verifyEmail, secretCode := createVerifyEmail(t, time.Now().UTC().Add(time.Hour))
+		token := signLinkToken(t, util.LinkPurposeVerifyEmail, verifyEmail.ID.Val, secretCode, verifyEmail.ExpiredAt.Val)
+
+		res, err := s.VerifyEmail(context.Background(), &pb.VerifyEmailRequest{
+			Token: token + "x",
+		})
+		assert.EqualError(t, err, "rpc error: code = InvalidArgument desc = the link is invalid")
+		assert.Nil(t, res)
+
+		st, ok := status.FromError(err)
+		assert.True(t, ok)
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+	})
+}
+
+func TestResendVerificationEmail(t *testing.T) {
+	s, err := setUpUser()
+	assert.NoError(t, err)
+
+	createVerifyEmail := func(t *testing.T, user *pb.User, createdAt time.Time) *model.VerifyEmailFieldValues {
+		verifyEmail, err := model.CreateVerifyEmail(db.GetConn(), &model.VerifyEmailFieldValues{
+			UserId:         model.GiveColInt(int(user.Id)),
+			Username:       model.GiveColString(user.Username),
+			Email:          model.GiveColString(user.Email),
+			SecretCodeHash: model.GiveColString(util.HashSecretCode(config.SecretCodeHashKey, util.RandomString(32))),
+			IsUsed:         model.GiveColBool(false),
+			ExpiredAt:      model.GiveColTime(createdAt.Add(time.Hour)),
+			CreatedAt:      model.GiveColTime(createdAt),
+			UpdatedAt:      model.GiveColTime(createdAt),
+		})
+		assert.NoError(t, err)
+
+		return verifyEmail
+	}
+
+	t.Run("Success", func(t *testing.T) {
+		user, _ := registerTestUser(t, s, util.RandomEmail(), false)
+		verifyEmail := createVerifyEmail(t, user, time.Now().UTC().Add(-2*time.Minute))
+
+		res, err := s.ResendVerificationEmail(context.Background(), &pb.ResendVerificationEmailRequest{Email: user.Email})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+
+		// The previous link no longer works
+		now := time.Now().UTC()
+		assert.Nil(t, model.GetVerifyEmailByID(db.GetConn(), verifyEmail.ID.Val, false, &now))
+	})
+
+	t.Run("Success_Cooldown", func(t *testing.T) {
+		user, _ := registerTestUser(t, s, util.RandomEmail(), false)
+		verifyEmail := createVerifyEmail(t, user, time.Now().UTC())
+
+		// A second request within the cooldown sends nothing but still answers ok
+		res, err := s.ResendVerificationEmail(context.Background(), &pb.ResendVerificationEmailRequest{Email: user.Email})
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+		assert.Equal(t, int32(http.StatusOK), res.Status)
+		assert.Equal(t, "ok", res.Message)
+
+		// The previous link still works
+		now := time.Now().UTC()
+		assert.NotNil(t, model.GetVerifyEmailByID(db.GetConn(), verifyEmail.ID.Val, false, &now))
+	})
+
+	t.Run("Success_AlreadyVerified", func(t *testing.T) {
+		user := loginTestUser(t, s)
+
+		res, err := s.ResendVerificationEmail(context.Background(), &pb.ResendVerificationEmailRequest{Email: user.Email})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res.Message)
+	})
+
+	t.Run("Success_UnregisteredEmail", func(t *testing.T) {
+		res, err := s.ResendVerificationEmail(context.Background(), &pb.ResendVerificationEmailRequest{Email: util.RandomEmail()})
+		assert.NoError(t, err)
+		assert.Equal(t, "ok", res.Message)
+	})
+}
@@ -2,31 +2,31 @@ package service
 
 import (
 	"context"
+	"errors"
 	"go-todolist-grpc/api/pb"
+	"go-todolist-grpc/internal/config"
 	"go-todolist-grpc/internal/model"
 	"go-todolist-grpc/internal/pkg/db"
 	"go-todolist-grpc/internal/pkg/log"
+	"go-todolist-grpc/internal/pkg/util"
+	"go-todolist-grpc/internal/service/queue"
+	"net/http"
 	"time"
 
+	"github.com/hibiken/asynq"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
-type ReqVerifyEmail struct {
-	Id         int32  `json:"id" validate:"required,min=1"`
-	SecretCode string `json:"secret_code" validate:"required,max=32"`
-}
+// Minimum interval between two verification emails sent to the same address
+const resendVerifyEmailCooldown = time.Minute
 
-func (ins ReqVerifyEmail) toFieldValues() model.VerifyEmailFieldValues {
-	fv := model.VerifyEmailFieldValues{}
-	fv.ID = model.GiveColInt(int(ins.Id))
-	fv.SecretCode = model.GiveColString(ins.SecretCode)
-	fv.IsUsed = model.GiveColBool(true)
-
-	return fv
+type ReqVerifyEmail struct {
+	Token string `json:"token" validate:"required,max=512"`
 }
 
 func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*pb.Response, error) {
+	cnf := config.Get()
 	conn := db.GetConn()
 	now := time.Now().UTC()
 
@@ -36,21 +36,44 @@ func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*
 		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
 	}
 
-	verifyEmailId := int(reqVerifyEmail.Id)
-	getVerifyEmail := model.GetVerifyEmailByID(conn, verifyEmailId, false, &now)
-	if getVerifyEmail == nil {
-		return nil, status.Errorf(codes.NotFound, "verify email ID not found")
+	// The token carries the verify email ID and secret code, signed when the email was sent
+	linkClaims, linkErr := parseLinkToken(util.LinkPurposeVerifyEmail, reqVerifyEmail.Token)
+	if linkErr != nil {
+		return nil, linkErr
 	}
 
-	insFields := reqVerifyEmail.toFieldValues()
+	verifyEmailId := linkClaims.ID
+	secretCodeHash := util.HashSecretCode(cnf.SecretCodeHashKey, linkClaims.SecretCode)
+
 	tx, txErr := conn.Begin()
 	if txErr != nil {
 		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
 	}
 	defer tx.Rollback()
 
-	if err := model.UpdateVerifyEmail(tx, verifyEmailId, &insFields); err != nil {
-		return nil, status.Errorf(codes.Internal, "failed to update verify email: %v", err)
+	// Lock the row so that concurrent requests with the same link are redeemed one at a time
+	getVerifyEmail := model.LockVerifyEmailByID(tx, verifyEmailId)
+	if getVerifyEmail == nil {
+		return nil, status.Errorf(codes.NotFound, "verify email ID not found")
+	}
+
+	// Check the code first so that the state of the link is only revealed to its owner
+	if !util.CheckSecretCodeHash(cnf.SecretCodeHashKey, linkClaims.SecretCode, getVerifyEmail.SecretCodeHash) {
+		return nil, status.Errorf(codes.InvalidArgument, "secret code is incorrect")
+	}
+	if getVerifyEmail.IsUsed {
+		return nil, status.Errorf(codes.FailedPrecondition, "this verification link has already been used")
+	}
+	if getVerifyEmail.ExpiredAt.Before(now) {
+		return nil, status.Errorf(codes.FailedPrecondition, "this verification link has expired")
+	}
+
+	used, usedErr := model.UseVerifyEmail(tx, verifyEmailId, secretCodeHash, now)
+	if usedErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update verify email: %v", usedErr)
+	}
+	if !used {
+		return nil, status.Errorf(codes.FailedPrecondition, "this verification link has already been used")
 	}
 
 	if err := model.UpdateUser(tx, getVerifyEmail.UserId, &model.UserFieldValues{
@@ -73,3 +96,75 @@ func (s *Server) VerifyEmail(ctx context.Context, req *pb.VerifyEmailRequest) (*
 		},
 	}, nil
 }
+
+type ReqResendVerificationEmail struct {
+	Email string `json:"email" validate:"required,email,max=64"`
+}
+
+func (s *Server) ResendVerificationEmail(ctx context.Context, req *pb.ResendVerificationEmailRequest) (*pb.Response, error) {
+	conn := db.GetConn()
+	now := time.Now().UTC()
+
+	// Validate request
+	reqResend := &ReqResendVerificationEmail{}
+	if err := bindRequest(req, reqResend); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "failed to validate: %v", err.Error())
+	}
+
+	okResponse := &pb.Response{
+		Data:    nil,
+		Status:  http.StatusOK,
+		Message: "ok",
+	}
+
+	// Always answer ok so that the response does not reveal whether the email is registered or verified
+	getUser := model.GetUserByEmail(conn, reqResend.Email)
+	if getUser == nil || getUser.IsEmailVerified {
+		return okResponse, nil
+	}
+
+	// Enforce the cooldown per email, silently for the same reason
+	if getVerifyEmail := model.GetVerifyEmailSince(conn, getUser.Email, now.Add(-resendVerifyEmailCooldown)); getVerifyEmail != nil {
+		return okResponse, nil
+	}
+
+	tx, txErr := conn.Begin()
+	if txErr != nil {
+		return nil, status.Errorf(codes.Internal, "failed to open db transaction: %v", txErr)
+	}
+	defer tx.Rollback()
+
+	// Only the link of the new email stays valid
+	if err := model.ExpireVerifyEmailsByUserID(tx, getUser.ID); err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update verify email: %v", err)
+	}
+
+	// Define options for the asynq, the unique lock also covers the window before the task creates its row
+	opts := []asynq.Option{
+		asynq.MaxRetry(3),
+		asynq.Queue(queue.QueueSendMail),
+		asynq.Unique(resendVerifyEmailCooldown),
+	}
+
+	// Distribute the task to send a verification email
+	taskPayload := &queue.PayloadSendVerifyEmail{
+		UserId: getUser.ID,
+	}
+
+	sendEmailErr := s.taskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+	if errors.Is(sendEmailErr, asynq.ErrDuplicateTask) {
+		return okResponse, nil
+	}
+	if sendEmailErr != nil {
+		log.Error.Printf("failed to distribute task to send verify email: %v", sendEmailErr)
+		return nil, status.Errorf(codes.Internal, "failed to distribute task to send verify email: %v", sendEmailErr)
+	}
+
+	comErr := tx.Commit()
+	if comErr != nil {
+		log.Error.Printf("failed to resend verify email from db tx: %v", comErr)
+		return nil, status.Errorf(codes.Internal, "failed to resend verify email from db tx: %v", comErr)
+	}
+
+	return okResponse, nil
+}